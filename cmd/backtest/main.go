@@ -0,0 +1,217 @@
+// backtest replays klines from a CSV file or a local kline cache through a
+// registered strategy and prints the metrics the dashboard shows for live
+// bots. It runs offline, the symbol and interval come from the strategy's
+// params. With -db it replays the candles cmd/candles stored instead.
+//
+//	go run ./cmd/backtest -strategy sma_crossover \
+//		-params '{"symbol": "BTCUSDT", "interval": "1h", "fast": 9, "slow": 21, "quantity": 0.01}' \
+//		-data cmd/backtest/testdata
+//
+// cmd/backtest/testdata holds a synthetic BTCUSDT 1h series to try it with.
+// Real data can be taken from data.binance.vision, -data accepts those files
+// as they are.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	"trade/internal/backtest"
+	"trade/internal/binance"
+	"trade/internal/candles"
+	"trade/internal/database"
+	"trade/internal/strategy"
+
+	"github.com/joho/godotenv"
+	"github.com/shopspring/decimal"
+)
+
+func main() {
+	name := flag.String("strategy", "", "name of a registered strategy")
+	params := flag.String("params", "{}", "strategy params as JSON, or @file to read them from a file")
+	data := flag.String("data", "", "CSV file of klines, or a cache directory of SYMBOL-interval.csv files")
+	fromDB := flag.Bool("db", false, "replay the candles stored by cmd/candles instead of -data")
+	from := flag.String("from", "", "first day to replay, YYYY-MM-DD or RFC3339 (default: start of the data)")
+	to := flag.String("to", "", "day to stop before, YYYY-MM-DD or RFC3339 (default: end of the data)")
+	capital := flag.String("capital", "10000", "initial capital in the quote asset")
+	fee := flag.String("fee", "0.001", "fee per fill as a fraction of the notional")
+	slippage := flag.String("slippage", "0.0005", "fraction of the price market orders fill worse than the open")
+	quote := flag.String("quote", "", "quote asset of the symbol (default: guessed from the symbol)")
+	asJSON := flag.Bool("json", false, "print the result as JSON")
+	verbose := flag.Bool("v", false, "list every fill and rejected order")
+	flag.Parse()
+
+	if *name == "" || (*data == "") == !*fromDB {
+		fmt.Fprintln(os.Stderr, "-strategy and one of -data or -db are required")
+		fmt.Fprintln(os.Stderr, "strategies:")
+		for _, def := range strategy.Definitions() {
+			fmt.Fprintf(os.Stderr, "  %-15s %s\n", def.Name, def.Description)
+		}
+		os.Exit(2)
+	}
+
+	rawParams, err := readParams(*params)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	strat, err := strategy.New(*name, rawParams)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	start, err := parseDate(*from)
+	if err != nil {
+		log.Fatalf("invalid -from: %v", err)
+	}
+	end, err := parseDate(*to)
+	if err != nil {
+		log.Fatalf("invalid -to: %v", err)
+	}
+
+	cfg := backtest.Config{QuoteAsset: strings.ToUpper(*quote)}
+	for _, f := range []struct {
+		flag  string
+		value string
+		into  *decimal.Decimal
+	}{
+		{"capital", *capital, &cfg.InitialCapital},
+		{"fee", *fee, &cfg.Fee},
+		{"slippage", *slippage, &cfg.Slippage},
+	} {
+		d, err := decimal.NewFromString(f.value)
+		if err != nil || d.IsNegative() {
+			log.Fatalf("invalid -%s %q", f.flag, f.value)
+		}
+		*f.into = d
+	}
+
+	feed := strat.Feed()
+	if feed.Interval == "" {
+		log.Fatalf("strategy %s only reacts to signals, there is nothing to replay", *name)
+	}
+
+	var klines []binance.Kline
+	if *fromDB {
+		klines, err = loadStored(feed.Symbol, feed.Interval, start, end)
+	} else {
+		klines, err = backtest.LoadKlines(*data, feed.Symbol, feed.Interval, start, end)
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	result, err := backtest.Run(strat, klines, cfg)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if *asJSON {
+		printJSON(*name, result)
+		return
+	}
+	printReport(*name, result, *verbose)
+}
+
+// loadStored reads klines from the candles table
+func loadStored(symbol, interval string, start, end time.Time) ([]binance.Kline, error) {
+	if err := godotenv.Load(); err != nil {
+		return nil, fmt.Errorf("error loading .env file: %w", err)
+	}
+
+	db, err := database.New()
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	defer db.DBPool.Close()
+
+	return candles.New(db).Load(context.Background(), symbol, interval, start, end)
+}
+
+func readParams(params string) (json.RawMessage, error) {
+	if file, ok := strings.CutPrefix(params, "@"); ok {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("error reading params: %w", err)
+		}
+		return data, nil
+	}
+	return json.RawMessage(params), nil
+}
+
+func parseDate(s string) (time.Time, error) {
+	if s == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, s)
+}
+
+func printReport(name string, r backtest.Result, verbose bool) {
+	quote := ""
+	if len(r.Fills) > 0 {
+		quote = " " + r.Fills[0].CommissionAsset
+	}
+
+	fmt.Printf("%s on %s %s, %s to %s (%d candles)\n\n", name, r.Symbol, r.Interval,
+		r.Start.UTC().Format("2006-01-02 15:04"), r.End.UTC().Format("2006-01-02 15:04"), r.Candles)
+
+	fmt.Printf("Trades:         %d\n", r.Stats.Trades)
+	fmt.Printf("Win rate:       %s%%\n", r.Stats.WinRate.StringFixed(2))
+	fmt.Printf("Profit factor:  %s\n", r.Stats.ProfitFactor.StringFixed(2))
+	fmt.Printf("PnL:            %s%s\n", r.Stats.RealizedPnl.StringFixed(2), quote)
+	fmt.Printf("Max drawdown:   %s%%\n", r.MaxDrawdown.StringFixed(2))
+	fmt.Printf("Return:         %s%% (equity %s -> %s)\n", r.Return().StringFixed(2), r.StartEquity.StringFixed(2), r.EndEquity.StringFixed(2))
+	fmt.Printf("Fees:           %s%s\n", r.Fees.StringFixed(2), quote)
+	fmt.Printf("Rejected:       %d\n", len(r.Rejected))
+
+	if !verbose {
+		return
+	}
+
+	fmt.Println("\nFills:")
+	for _, f := range r.Fills {
+		fmt.Printf("  %s  %-4s %s @ %s  fee %s\n", f.Time.UTC().Format("2006-01-02 15:04"), f.Side, f.Quantity, f.Price.Round(8), f.Commission.Round(8))
+	}
+
+	if len(r.Rejected) > 0 {
+		fmt.Println("\nRejected:")
+		for _, rej := range r.Rejected {
+			fmt.Printf("  %s  %-4s %s: %s\n", rej.Time.UTC().Format("2006-01-02 15:04"), rej.Intent.Side, rej.Intent.Symbol, rej.Reason)
+		}
+	}
+}
+
+func printJSON(name string, r backtest.Result) {
+	out := map[string]any{
+		"strategy":      name,
+		"symbol":        r.Symbol,
+		"interval":      r.Interval,
+		"start":         r.Start,
+		"end":           r.End,
+		"candles":       r.Candles,
+		"trades":        r.Stats.Trades,
+		"win_rate":      r.Stats.WinRate,
+		"profit_factor": r.Stats.ProfitFactor,
+		"pnl":           r.Stats.RealizedPnl.Round(2),
+		"max_drawdown":  r.MaxDrawdown,
+		"return":        r.Return(),
+		"start_equity":  r.StartEquity,
+		"end_equity":    r.EndEquity.Round(2),
+		"fees":          r.Fees.Round(2),
+		"rejected":      len(r.Rejected),
+		"equity":        r.Equity,
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(out)
+}
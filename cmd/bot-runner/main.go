@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"trade/internal/database"
+	"trade/internal/orders"
+	"trade/internal/runner"
+
+	"github.com/joho/godotenv"
+)
+
+const shutdownTimeout = 30 * time.Second
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	db, err := database.New()
+	if err != nil {
+		log.Fatal("failed to connect to database,", err)
+	}
+	defer db.DBPool.Close()
+
+	r := runner.New(db, runner.StrategyWorkers(orders.New(db)))
+
+	addr := os.Getenv("BOT_RUNNER_HEALTH_ADDR")
+	if addr == "" {
+		addr = ":8082"
+	}
+	server := &http.Server{Addr: addr, Handler: healthHandler(r)}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		r.Run(ctx)
+	}()
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Printf("health server stopped: %v\n", err)
+		}
+	}()
+
+	fmt.Printf("bot-runner running, health on %s\n", addr)
+	<-ctx.Done()
+	fmt.Println("Shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	server.Shutdown(shutdownCtx)
+
+	<-done
+}
+
+// healthHandler serves the state of every running bot as JSON
+func healthHandler(r *runner.Runner) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, req *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(r.States())
+	})
+	return mux
+}
+
+func init() {
+	err := godotenv.Load()
+	if err != nil {
+		log.Fatal("error loading .evn file")
+	}
+}
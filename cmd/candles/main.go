@@ -0,0 +1,131 @@
+// candles downloads klines from Binance into the candles table. The first
+// run backfills each series from -from, later runs only fetch what closed
+// since the last one, so it can be run from cron or kept running with -watch.
+//
+//	go run ./cmd/candles -symbols BTCUSDT,ETHUSDT -intervals 1h,1d -from 2024-01-01
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"trade/internal/binance"
+	"trade/internal/candles"
+	"trade/internal/database"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	symbols := flag.String("symbols", "", "comma separated symbols, e.g. BTCUSDT,ETHUSDT")
+	intervals := flag.String("intervals", "1h", "comma separated kline intervals, e.g. 15m,1h,1d")
+	from := flag.String("from", "", "backfill new series from this day, YYYY-MM-DD (default: 30 days ago)")
+	baseURL := flag.String("base-url", "https://api.binance.com", "Binance API to download from")
+	watch := flag.Duration("watch", 0, "keep running and sync again at this interval, e.g. 1m")
+	flag.Parse()
+
+	targets, err := parseTargets(*symbols, *intervals)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	start := time.Now().AddDate(0, 0, -30).Truncate(24 * time.Hour)
+	if *from != "" {
+		start, err = time.Parse(time.DateOnly, *from)
+		if err != nil {
+			log.Fatalf("invalid -from %q", *from)
+		}
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	db, err := database.New()
+	if err != nil {
+		log.Fatal("failed to connect to database,", err)
+	}
+	defer db.DBPool.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	store := candles.New(db)
+	client := binance.NewPublic(*baseURL)
+
+	for {
+		failed := sync(ctx, store, client, targets, start)
+
+		if *watch <= 0 {
+			if failed > 0 {
+				os.Exit(1)
+			}
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(*watch):
+		}
+	}
+}
+
+// target is one series of klines to keep in sync
+type target struct {
+	symbol   string
+	interval string
+}
+
+func parseTargets(symbols, intervals string) ([]target, error) {
+	var targets []target
+	for _, interval := range strings.Split(intervals, ",") {
+		interval = strings.TrimSpace(interval)
+		if _, ok := binance.IntervalDuration(interval); !ok {
+			return nil, fmt.Errorf("unknown interval %q, use one of %s", interval, strings.Join(binance.Intervals(), ", "))
+		}
+
+		for _, symbol := range strings.Split(symbols, ",") {
+			symbol = strings.ToUpper(strings.TrimSpace(symbol))
+			if symbol == "" {
+				continue
+			}
+			targets = append(targets, target{symbol: symbol, interval: interval})
+		}
+	}
+
+	if len(targets) == 0 {
+		return nil, fmt.Errorf("-symbols is required")
+	}
+
+	return targets, nil
+}
+
+// sync brings every series up to date and returns how many failed
+func sync(ctx context.Context, store *candles.Store, client *binance.Client, targets []target, from time.Time) int {
+	failed := 0
+	for _, s := range targets {
+		written, err := store.Sync(ctx, client, s.symbol, s.interval, from)
+		if err != nil {
+			fmt.Printf("%s %s: %v\n", s.symbol, s.interval, err)
+			failed++
+		}
+		if written > 0 {
+			fmt.Printf("%s %s: stored %d candles\n", s.symbol, s.interval, written)
+		}
+
+		if ctx.Err() != nil {
+			break
+		}
+	}
+	return failed
+}
@@ -2,138 +2,114 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
 	"time"
-	"trade/internal/binance"
+
+	"trade/internal/cashflows"
 	"trade/internal/database"
-	db "trade/internal/db/sqlc"
+	"trade/internal/orders"
+	"trade/internal/rollup"
 
-	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/joho/godotenv"
 )
 
-type BalanceService struct {
-	db      *database.Database
-	UserID  int32
-	Running bool
-}
-
-func (s BalanceService) SetRunning(b bool) {
-	s.Running = b
-}
-
-func (s BalanceService) waitForNextMinute() {
-	now := time.Now()
-	nextMinute := now.Truncate(time.Minute).Add(time.Minute)
-
-	time.Sleep(time.Until(nextMinute))
-}
-
-func (s BalanceService) BalanceSnapshot(ch chan<- string) {
-	s.SetRunning(true)
-	defer s.SetRunning(false)
-
-	ctx := context.Background()
-
-	for {
-		s.waitForNextMinute()
-		accounts, err := s.db.Queries.GetUserBinanceAccountsWithStatus(ctx, s.UserID)
-		if err != nil {
-			fmt.Printf("failed to get accounts: %v", err)
-			return
-		}
-		var clients []binance.Client
-		for _, acc := range accounts {
-			client, err := binance.New(acc.ApiKey, acc.ApiSecret, acc.BaseUrl.String)
-			if err != nil {
-				fmt.Printf("failed to create client: %v", err)
-				return
-			}
-			clients = append(clients, *client)
-		}
-
-		for i, client := range clients {
-			info, err := client.GetMarginAccountInfo()
-			if err != nil {
-				fmt.Printf("error getting margin account info: %v", err)
-				fmt.Println("Sleep for 1 minute")
-				time.Sleep(time.Minute)
-				continue
-			}
-
-			// Convert info.TotalNetAssetOfUSDT to pgtype.Numeric
-			var totalUSDT pgtype.Numeric
-			if err = totalUSDT.Scan(info.TotalNetAssetOfUSDT); err != nil {
-				fmt.Printf("failed to convert TotalNetAssetOfUSDT to numeric: %v", err)
-				return
-			}
-
-			var now pgtype.Timestamptz
-			if err = now.Scan(time.Now()); err != nil {
-				fmt.Printf("failed to convert time.Now() to TimneStamptz: %v", err)
-				return
-			}
-
-			record, err := s.db.Queries.CreateBalanceRecord(ctx, db.CreateBalanceRecordParams{
-				BinanceAccountID: accounts[i].ID,
-				TotalBalanceUsd:  totalUSDT,
-				RecordedAt:       now,
-			})
-			if err != nil {
-				fmt.Printf("failed to create record in db: %v", err)
-				return
-			}
-
-			str := fmt.Sprintf("%v %v %v %v", record.ID, record.BinanceAccountID, record.TotalBalanceUsd, record.RecordedAt)
-
-			ch <- str
-		}
-	}
-}
-
-func MakeBalanceService(userID int32, db *database.Database) *BalanceService {
-	balanceService := BalanceService{
-		db:      db,
-		UserID:  userID,
-		Running: false,
-	}
-
-	return &balanceService
-}
+const shutdownTimeout = 30 * time.Second
 
 func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	// The database for queries
 	db, err := database.New()
 	if err != nil {
 		log.Fatal("failed to connect to database,", err)
 	}
+	defer db.DBPool.Close()
 
-	users, err := db.Queries.GetUsers(context.Background())
-	if err != nil {
-		log.Fatal(err)
-	}
+	scheduler := NewScheduler(db)
 
-	var snapshotServices []BalanceService
-
-	for _, user := range users {
-		snapshotService := MakeBalanceService(user.ID, db)
-		snapshotServices = append(snapshotServices, *snapshotService)
+	addr := os.Getenv("EOD_HEALTH_ADDR")
+	if addr == "" {
+		addr = ":8081"
 	}
+	server := &http.Server{Addr: addr, Handler: healthHandler(scheduler)}
+
+	var wg sync.WaitGroup
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		scheduler.Run(ctx)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		reconcileOrders(ctx, orders.New(db))
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		rollup.New(db, rollup.PolicyFromEnv()).Run(ctx)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		cashflows.New(db).Run(ctx)
+	}()
+
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Printf("health server stopped: %v\n", err)
+		}
+	}()
 
-	ch := make(chan string)
+	fmt.Printf("eod-service running, health on %s\n", addr)
+	<-ctx.Done()
+	fmt.Println("Shutting down")
 
-	for _, ss := range snapshotServices {
-		go ss.BalanceSnapshot(ch)
-	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	server.Shutdown(shutdownCtx)
 
-	reciver(ch)
+	wg.Wait()
+}
 
+// healthHandler serves the per-account snapshot health as JSON
+func healthHandler(s *Scheduler) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(s.Health())
+	})
+	return mux
 }
 
-func reciver(ch <-chan string) {
-	for message := range ch {
-		fmt.Println("Got:", message)
+// reconcileOrders keeps open orders and their fills in sync with Binance
+func reconcileOrders(ctx context.Context, s *orders.Service) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := s.Reconcile(ctx); err != nil {
+			fmt.Printf("failed to reconcile orders: %v\n", err)
+		}
 	}
 }
 
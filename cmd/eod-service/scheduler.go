@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"trade/internal/binance"
+	"trade/internal/database"
+	db "trade/internal/db/sqlc"
+	"trade/internal/exchange"
+)
+
+const (
+	minBackoff = time.Minute
+	maxBackoff = 30 * time.Minute
+)
+
+// AccountHealth is the snapshot state of one account
+type AccountHealth struct {
+	AccountID           int32      `json:"account_id"`
+	UserID              int32      `json:"user_id"`
+	Name                string     `json:"name"`
+	LastSuccess         *time.Time `json:"last_success"`
+	LastAttempt         *time.Time `json:"last_attempt"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastError           string     `json:"last_error,omitempty"`
+	NextAttempt         time.Time  `json:"next_attempt"`
+}
+
+// cachedExchange is reused until the account's credentials, environment or
+// futures access change
+type cachedExchange struct {
+	exchange exchange.Exchange
+	account  exchange.Account
+}
+
+// Scheduler snapshots the balance of every active account once a minute.
+// Accounts are looked up on every run, so new users and accounts are picked up
+// without a restart. A failing account backs off exponentially and never
+// stops the others.
+type Scheduler struct {
+	db *database.Database
+
+	mu       sync.RWMutex
+	health   map[int32]*AccountHealth
+	clients  map[int32]cachedExchange
+	inFlight map[int32]bool
+}
+
+func NewScheduler(db *database.Database) *Scheduler {
+	return &Scheduler{
+		db:       db,
+		health:   make(map[int32]*AccountHealth),
+		clients:  make(map[int32]cachedExchange),
+		inFlight: make(map[int32]bool),
+	}
+}
+
+// Run snapshots at the start of every minute until ctx is cancelled, then
+// waits for the snapshots in flight
+func (s *Scheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for {
+		now := time.Now()
+		next := now.Truncate(time.Minute).Add(time.Minute)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(time.Until(next)):
+		}
+
+		accounts, err := s.db.Queries.GetActiveBinanceAccounts(ctx)
+		if err != nil {
+			fmt.Printf("failed to get accounts: %v\n", err)
+			continue
+		}
+
+		s.forgetRemoved(accounts)
+
+		for _, acc := range accounts {
+			// A Binance futures testnet key that never passed validation has
+			// nothing to snapshot
+			if acc.Exchange == string(exchange.Binance) && !binance.Environment(acc.Environment).HasSpot() && !acc.FuturesEnabled {
+				continue
+			}
+			if !s.due(acc, next) {
+				continue
+			}
+
+			// Snapshots already started finish even when shutdown begins
+			wg.Add(1)
+			go func(acc db.GetActiveBinanceAccountsRow) {
+				defer wg.Done()
+				s.record(acc, s.snapshot(context.WithoutCancel(ctx), acc, next))
+			}(acc)
+		}
+	}
+}
+
+// Health returns the state of every known account
+func (s *Scheduler) Health() []AccountHealth {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	health := make([]AccountHealth, 0, len(s.health))
+	for _, h := range s.health {
+		health = append(health, *h)
+	}
+
+	sort.Slice(health, func(i, j int) bool {
+		return health[i].AccountID < health[j].AccountID
+	})
+
+	return health
+}
+
+// due registers new accounts and reports whether the account is out of
+// backoff and not still busy with the previous snapshot
+func (s *Scheduler) due(acc db.GetActiveBinanceAccountsRow, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	h, ok := s.health[acc.ID]
+	if !ok {
+		h = &AccountHealth{AccountID: acc.ID, UserID: acc.UserID}
+		s.health[acc.ID] = h
+	}
+	h.Name = acc.Name
+
+	if s.inFlight[acc.ID] || now.Before(h.NextAttempt) {
+		return false
+	}
+
+	s.inFlight[acc.ID] = true
+	return true
+}
+
+// forgetRemoved drops health and clients of accounts that were deleted
+func (s *Scheduler) forgetRemoved(accounts []db.GetActiveBinanceAccountsRow) {
+	active := make(map[int32]bool, len(accounts))
+	for _, acc := range accounts {
+		active[acc.ID] = true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id := range s.health {
+		if !active[id] {
+			delete(s.health, id)
+			delete(s.clients, id)
+		}
+	}
+}
+
+func (s *Scheduler) record(acc db.GetActiveBinanceAccountsRow, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.inFlight, acc.ID)
+
+	h, ok := s.health[acc.ID]
+	if !ok {
+		return
+	}
+
+	now := time.Now()
+	h.LastAttempt = &now
+
+	if err == nil {
+		h.LastSuccess = &now
+		h.ConsecutiveFailures = 0
+		h.LastError = ""
+		h.NextAttempt = time.Time{}
+		return
+	}
+
+	h.ConsecutiveFailures++
+	h.LastError = err.Error()
+	h.NextAttempt = now.Add(backoff(h.ConsecutiveFailures))
+
+	fmt.Printf("snapshot of account %d failed %d times in a row: %v\n", acc.ID, h.ConsecutiveFailures, err)
+}
+
+// backoff doubles the wait per consecutive failure, from minBackoff up to maxBackoff
+func backoff(failures int) time.Duration {
+	wait := minBackoff
+	for i := 1; i < failures && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxBackoff)
+}
+
+func (s *Scheduler) exchange(acc db.GetActiveBinanceAccountsRow) (exchange.Exchange, error) {
+	account := exchange.Account{
+		Exchange:        exchange.Name(acc.Exchange),
+		Environment:     acc.Environment,
+		EncryptedKey:    acc.ApiKey,
+		EncryptedSecret: acc.ApiSecret,
+		FuturesEnabled:  acc.FuturesEnabled,
+	}
+
+	s.mu.RLock()
+	cached, ok := s.clients[acc.ID]
+	s.mu.RUnlock()
+
+	if ok && cached.account == account {
+		return cached.exchange, nil
+	}
+
+	ex, err := exchange.Open(account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create client: %v", err)
+	}
+
+	s.mu.Lock()
+	s.clients[acc.ID] = cachedExchange{exchange: ex, account: account}
+	s.mu.Unlock()
+
+	return ex, nil
+}
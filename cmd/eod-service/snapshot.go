@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"trade/internal/database"
+	db "trade/internal/db/sqlc"
+	"trade/internal/exchange"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// snapshot stores the account's net value together with every asset it is
+// made of. A panic is turned into an error so one bad account can't take the
+// service down.
+func (s *Scheduler) snapshot(ctx context.Context, acc db.GetActiveBinanceAccountsRow, at time.Time) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	ex, err := s.exchange(acc)
+	if err != nil {
+		return err
+	}
+
+	total, err := ex.NetValue()
+	if err != nil {
+		return err
+	}
+
+	balances, err := ex.Balances()
+	if err != nil {
+		return err
+	}
+
+	prices, err := ex.Prices()
+	if err != nil {
+		return fmt.Errorf("error getting prices: %v", err)
+	}
+
+	recordedAt := pgtype.Timestamptz{Time: at, Valid: true}
+
+	tx, err := s.db.DBPool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %v", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.db.Queries.WithTx(tx)
+
+	record, err := qtx.CreateBalanceRecord(ctx, db.CreateBalanceRecordParams{
+		BinanceAccountID: acc.ID,
+		TotalBalanceUsd:  database.Numeric(total.Round(2)),
+		RecordedAt:       recordedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create record in db: %v", err)
+	}
+
+	for _, a := range snapshotAssets(balances) {
+		params := db.CreateBalanceAssetSnapshotParams{
+			BalanceHistoryID: record.ID,
+			BinanceAccountID: acc.ID,
+			Market:           string(a.Market),
+			Asset:            a.Asset,
+			Free:             database.Numeric(a.Free),
+			Locked:           database.Numeric(a.Locked),
+			Borrowed:         database.Numeric(a.Borrowed),
+			Interest:         database.Numeric(a.Interest),
+			NetAsset:         database.Numeric(a.Net),
+			RecordedAt:       recordedAt,
+		}
+
+		if price, ok := exchange.USDTPrice(prices, a.Asset); ok {
+			params.PriceUsdt = database.Numeric(price)
+			params.ValueUsdt = database.Numeric(a.Net.Mul(price))
+		}
+
+		if err := qtx.CreateBalanceAssetSnapshot(ctx, params); err != nil {
+			return fmt.Errorf("failed to create %s %s snapshot: %v", a.Market, a.Asset, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error committing snapshot: %v", err)
+	}
+
+	return nil
+}
+
+// snapshotAssets sums the balances of each market and asset, the snapshot
+// keeps one row per market and asset where isolated margin pairs each have
+// their own balances
+func snapshotAssets(balances []exchange.Balance) []exchange.Balance {
+	var assets []exchange.Balance
+	index := make(map[string]int)
+
+	for _, b := range balances {
+		key := string(b.Market) + "/" + b.Asset
+		i, ok := index[key]
+		if !ok {
+			i = len(assets)
+			index[key] = i
+			assets = append(assets, exchange.Balance{Market: b.Market, Asset: b.Asset})
+		}
+
+		assets[i].Free = assets[i].Free.Add(b.Free)
+		assets[i].Locked = assets[i].Locked.Add(b.Locked)
+		assets[i].Borrowed = assets[i].Borrowed.Add(b.Borrowed)
+		assets[i].Interest = assets[i].Interest.Add(b.Interest)
+		assets[i].Net = assets[i].Net.Add(b.Net)
+	}
+	return assets
+}
@@ -0,0 +1,88 @@
+// rotate-secrets re-encrypts the exchange credentials in exchange_accounts with
+// the current SECRETS_MASTER_KEY. Rows encrypted with a key listed in
+// SECRETS_OLD_MASTER_KEYS are decrypted with it first, plaintext rows are
+// encrypted. Rows already under the current key are left alone, so the
+// command can be re-run safely.
+//
+// To rotate: move the current key to SECRETS_OLD_MASTER_KEYS, set a new
+// SECRETS_MASTER_KEY (see -generate), run this command, then drop the old key.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"trade/internal/database"
+	db "trade/internal/db/sqlc"
+	"trade/internal/secrets"
+
+	"github.com/joho/godotenv"
+)
+
+func main() {
+	generate := flag.Bool("generate", false, "print a new master key and exit")
+	flag.Parse()
+
+	if *generate {
+		key, err := secrets.GenerateMasterKey()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Println(key)
+		return
+	}
+
+	if err := godotenv.Load(); err != nil {
+		log.Fatal("Error loading .env file")
+	}
+
+	db, err := database.New()
+	if err != nil {
+		log.Fatal("failed to connect to database,", err)
+	}
+
+	rotated, err := rotate(context.Background(), db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Printf("Re-encrypted %d accounts\n", rotated)
+}
+
+func rotate(ctx context.Context, database *database.Database) (int, error) {
+	accounts, err := database.Queries.GetAllBinanceAccountCredentials(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("error getting accounts: %v", err)
+	}
+
+	rotated := 0
+	for _, acc := range accounts {
+		apiKey, keyChanged, err := secrets.Rotate(acc.ApiKey)
+		if err != nil {
+			return rotated, fmt.Errorf("error rotating api key of account %d: %v", acc.ID, err)
+		}
+
+		apiSecret, secretChanged, err := secrets.Rotate(acc.ApiSecret)
+		if err != nil {
+			return rotated, fmt.Errorf("error rotating api secret of account %d: %v", acc.ID, err)
+		}
+
+		if !keyChanged && !secretChanged {
+			continue
+		}
+
+		err = database.Queries.UpdateBinanceAccountCredentials(ctx, db.UpdateBinanceAccountCredentialsParams{
+			ID:        acc.ID,
+			ApiKey:    apiKey,
+			ApiSecret: apiSecret,
+		})
+		if err != nil {
+			return rotated, fmt.Errorf("error updating account %d: %v", acc.ID, err)
+		}
+		rotated++
+	}
+
+	return rotated, nil
+}
@@ -0,0 +1,64 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// WebhookMaxAge is how far the timestamp of a signed webhook may be from now.
+// A captured request can't be replayed once it is older than this.
+const WebhookMaxAge = 5 * time.Minute
+
+func GenerateWebhookSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("error generating webhook secret: %v", err)
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// CheckWebhookSignature verifies a hex encoded HMAC-SHA256 of "<timestamp>.<body>"
+// made with secret. The timestamp is in unix seconds and must be within
+// WebhookMaxAge of now.
+func CheckWebhookSignature(secret, timestamp string, body []byte, signature string, now time.Time) error {
+	sec, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("timestamp is not unix seconds: %v", err)
+	}
+
+	age := now.Sub(time.Unix(sec, 0))
+	if age > WebhookMaxAge || age < -WebhookMaxAge {
+		return fmt.Errorf("timestamp is %v away from now", age.Round(time.Second))
+	}
+
+	got, err := hex.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("signature is not valid hex: %v", err)
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+
+	if !hmac.Equal(got, mac.Sum(nil)) {
+		return fmt.Errorf("signature does not match")
+	}
+
+	return nil
+}
+
+// CheckWebhookToken compares a plain token, for senders like TradingView that cannot sign requests
+func CheckWebhookToken(secret, token string) error {
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(token)) != 1 {
+		return fmt.Errorf("token does not match")
+	}
+
+	return nil
+}
@@ -0,0 +1,278 @@
+// Package backtest replays historical klines through a strategy and simulates
+// the fills a spot account would have got, so strategies can be evaluated
+// before a bot trades with real money.
+package backtest
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"trade/internal/binance"
+	"trade/internal/paper"
+	"trade/internal/stats"
+	"trade/internal/strategy"
+
+	"github.com/shopspring/decimal"
+)
+
+var hundred = decimal.NewFromInt(100)
+
+// Quote assets recognised in symbols, longer ones first so FDUSD wins over USD
+var quoteAssets = []string{"FDUSD", "USDT", "USDC", "TUSD", "BUSD", "BTC", "ETH", "BNB", "EUR", "TRY"}
+
+type Config struct {
+	QuoteAsset     string          // asset fees and capital are in, guessed from the symbol when empty
+	InitialCapital decimal.Decimal // in the quote asset
+	Fee            decimal.Decimal // fraction of each fill, 0.001 is 0.1%
+	Slippage       decimal.Decimal // fraction of the price market orders fill worse than the open
+}
+
+// Rejection is an intent the simulated account couldn't fill
+type Rejection struct {
+	Time   time.Time
+	Intent strategy.Intent
+	Reason string
+}
+
+type Result struct {
+	Symbol      string
+	Interval    string
+	Start       time.Time
+	End         time.Time
+	Candles     int
+	StartEquity decimal.Decimal
+	EndEquity   decimal.Decimal
+	Fees        decimal.Decimal
+	MaxDrawdown decimal.Decimal // in percent of the peak equity
+	Stats       stats.Stats     // the numbers GetBotStats shows for a live bot
+	Fills       []stats.Fill
+	Trades      []stats.Trade
+	Equity      []stats.EquityPoint
+	Rejected    []Rejection
+}
+
+// Return is the change in equity over the run in percent, including the
+// value of a position still open at the end
+func (r Result) Return() decimal.Decimal {
+	if !r.StartEquity.IsPositive() {
+		return decimal.Zero
+	}
+	return r.EndEquity.Sub(r.StartEquity).Div(r.StartEquity).Mul(hundred).Round(2)
+}
+
+// account is the simulated spot account of one symbol
+type account struct {
+	cfg    Config
+	symbol string
+	base   string
+	quote  string
+	// balances of the base and quote asset
+	balances map[string]decimal.Decimal
+
+	pending []strategy.Intent
+	result  *Result
+}
+
+// Run feeds klines to strat in order and fills its intents on the following
+// candles, so a strategy never trades on the candle it decided on. Market
+// orders fill at the next open moved against the order by the slippage,
+// limit orders rest until a candle trades through their price. Intents of
+// the last candle are never filled.
+func Run(strat strategy.Strategy, klines []binance.Kline, cfg Config) (Result, error) {
+	feed := strat.Feed()
+	if feed.Interval == "" {
+		return Result{}, errors.New("strategy doesn't trade on candles, nothing to backtest")
+	}
+	if len(klines) == 0 {
+		return Result{}, errors.New("no klines to backtest")
+	}
+	if !cfg.InitialCapital.IsPositive() {
+		return Result{}, errors.New("initial capital must be positive")
+	}
+
+	quote := cfg.QuoteAsset
+	if quote == "" {
+		quote = guessQuoteAsset(feed.Symbol)
+		if quote == "" {
+			return Result{}, fmt.Errorf("can't tell the quote asset of %s, set it explicitly", feed.Symbol)
+		}
+	}
+
+	result := Result{
+		Symbol:      feed.Symbol,
+		Interval:    feed.Interval,
+		Start:       klines[0].OpenTime,
+		End:         klines[len(klines)-1].CloseTime,
+		StartEquity: cfg.InitialCapital,
+		Fees:        decimal.Zero,
+	}
+	base := strings.TrimSuffix(feed.Symbol, quote)
+	acc := &account{
+		cfg:      cfg,
+		symbol:   feed.Symbol,
+		base:     base,
+		quote:    quote,
+		balances: map[string]decimal.Decimal{base: decimal.Zero, quote: cfg.InitialCapital},
+		result:   &result,
+	}
+
+	for _, k := range klines {
+		candle, err := strategy.CandleFromKline(feed.Symbol, feed.Interval, k)
+		if err != nil {
+			return Result{}, err
+		}
+
+		acc.fill(candle)
+
+		intents := strat.OnCandle(candle)
+		if feed.Ticks {
+			intents = append(intents, strat.OnTick(strategy.Tick{Symbol: feed.Symbol, Price: candle.Close, Time: candle.CloseTime})...)
+		}
+		for _, intent := range intents {
+			acc.place(candle.CloseTime, intent)
+		}
+
+		result.Candles++
+		result.Equity = append(result.Equity, stats.EquityPoint{
+			Time:   candle.CloseTime,
+			Equity: acc.equity(candle.Close),
+		})
+	}
+
+	result.EndEquity = result.Equity[len(result.Equity)-1].Equity
+	result.MaxDrawdown = stats.MaxDrawdown(result.Equity)
+	result.Trades = stats.RoundTrips(result.Fills)
+	result.Stats = stats.Summarize(result.Trades)
+
+	return result, nil
+}
+
+// place queues an intent for the next candles, or rejects it right away
+func (a *account) place(at time.Time, intent strategy.Intent) {
+	switch {
+	case intent.Symbol != a.symbol:
+		a.reject(at, intent, fmt.Sprintf("only %s is backtested", a.symbol))
+	case intent.Side != binance.SideBuy && intent.Side != binance.SideSell:
+		a.reject(at, intent, fmt.Sprintf("invalid side %q", intent.Side))
+	case intent.Type == binance.OrderTypeLimit && !intent.Price.IsPositive():
+		a.reject(at, intent, "limit order without a price")
+	case intent.Type != binance.OrderTypeMarket && intent.Type != binance.OrderTypeLimit:
+		a.reject(at, intent, fmt.Sprintf("order type %q is not simulated", intent.Type))
+	case !intent.Quantity.IsPositive() && !intent.Percent.IsPositive():
+		a.reject(at, intent, "no quantity or percent")
+	default:
+		a.pending = append(a.pending, intent)
+	}
+}
+
+// fill executes the pending orders the candle reaches
+func (a *account) fill(c strategy.Candle) {
+	var resting []strategy.Intent
+
+	for _, intent := range a.pending {
+		price, ok := a.fillPrice(intent, c)
+		if !ok {
+			resting = append(resting, intent)
+			continue
+		}
+
+		if err := a.execute(c.OpenTime, intent, price); err != nil {
+			a.reject(c.OpenTime, intent, err.Error())
+		}
+	}
+
+	a.pending = resting
+}
+
+func (a *account) fillPrice(intent strategy.Intent, c strategy.Candle) (decimal.Decimal, bool) {
+	if intent.Type == binance.OrderTypeMarket {
+		slip := decimal.NewFromInt(1).Add(a.cfg.Slippage)
+		if intent.Side == binance.SideSell {
+			slip = decimal.NewFromInt(1).Sub(a.cfg.Slippage)
+		}
+		return c.Open.Mul(slip), true
+	}
+
+	// A limit order fills at its price, or at the open when the candle
+	// opens through it
+	if intent.Side == binance.SideBuy {
+		if c.Low.GreaterThan(intent.Price) {
+			return decimal.Zero, false
+		}
+		return decimal.Min(c.Open, intent.Price), true
+	}
+
+	if c.High.LessThan(intent.Price) {
+		return decimal.Zero, false
+	}
+	return decimal.Max(c.Open, intent.Price), true
+}
+
+// equity is the value of the account in the quote asset at price
+func (a *account) equity(price decimal.Decimal) decimal.Decimal {
+	return a.balances[a.quote].Add(a.balances[a.base].Mul(price))
+}
+
+// execute fills intent at price and settles it the way paper bots do, see
+// paper.Settle
+func (a *account) execute(at time.Time, intent strategy.Intent, price decimal.Decimal) error {
+	held := a.balances[a.base]
+
+	quantity := intent.Quantity
+	if !quantity.IsPositive() {
+		share := intent.Percent.Div(hundred)
+		if intent.Side == binance.SideBuy {
+			quantity = a.balances[a.quote].Mul(share).Div(price)
+		} else {
+			quantity = held.Mul(share)
+		}
+	}
+
+	// The buy fee leaves less than was bought, so an exit sells what is
+	// held, like the strategy runner sizes exits from the bot's position
+	if intent.Side == binance.SideSell && held.IsPositive() {
+		quantity = decimal.Min(quantity, held)
+	}
+
+	if !quantity.IsPositive() {
+		return fmt.Errorf("nothing to %s", strings.ToLower(string(intent.Side)))
+	}
+
+	commission, commissionAsset, err := paper.Settle(a.balances, a.base, a.quote, intent.Side, quantity, price, a.cfg.Fee)
+	if err != nil {
+		return err
+	}
+
+	fee := commission
+	if commissionAsset == a.base {
+		fee = commission.Mul(price)
+	}
+
+	a.result.Fees = a.result.Fees.Add(fee)
+	a.result.Fills = append(a.result.Fills, stats.Fill{
+		Symbol:          a.symbol,
+		Side:            string(intent.Side),
+		Price:           price,
+		Quantity:        quantity,
+		Commission:      commission,
+		CommissionAsset: commissionAsset,
+		Time:            at,
+	})
+
+	return nil
+}
+
+func (a *account) reject(at time.Time, intent strategy.Intent, reason string) {
+	a.result.Rejected = append(a.result.Rejected, Rejection{Time: at, Intent: intent, Reason: reason})
+}
+
+func guessQuoteAsset(symbol string) string {
+	for _, quote := range quoteAssets {
+		if strings.HasSuffix(symbol, quote) && len(symbol) > len(quote) {
+			return quote
+		}
+	}
+	return ""
+}
@@ -0,0 +1,94 @@
+package backtest
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"trade/internal/stats"
+	"trade/internal/strategy"
+
+	"github.com/shopspring/decimal"
+)
+
+// TestRunSMACrossover replays the BTCUSDT fixture through a fixed SMA
+// crossover so changes to the simulation or the stats show up as a diff in
+// the numbers below.
+func TestRunSMACrossover(t *testing.T) {
+	f, err := os.Open("../../cmd/backtest/testdata/BTCUSDT-1h.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	klines, err := ReadCSV(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	strat, err := strategy.New("sma_crossover", json.RawMessage(`{"symbol":"BTCUSDT","interval":"1h","fast":9,"slow":21,"quantity":0.1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, err := Run(strat, klines, Config{
+		InitialCapital: decimal.NewFromInt(10000),
+		Fee:            decimal.RequireFromString("0.001"),
+		Slippage:       decimal.RequireFromString("0.0005"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result.Candles != 1000 {
+		t.Errorf("candles = %d, want 1000", result.Candles)
+	}
+	if result.Stats.Trades != 11 {
+		t.Errorf("trades = %d, want 11", result.Stats.Trades)
+	}
+	if result.Stats.Wins != 4 {
+		t.Errorf("wins = %d, want 4", result.Stats.Wins)
+	}
+
+	for _, c := range []struct {
+		name string
+		got  decimal.Decimal
+		want string
+	}{
+		{"win rate", result.Stats.WinRate, "36.36"},
+		{"profit factor", result.Stats.ProfitFactor, "1.57"},
+		{"realized pnl", result.Stats.RealizedPnl, "858.610433508198"},
+		{"fees", result.Fees, "215.507270095302"},
+		{"max drawdown", result.MaxDrawdown, "12.21"},
+		{"end equity", result.EndEquity, "10858.610433508198"},
+	} {
+		if !c.got.Equal(decimal.RequireFromString(c.want)) {
+			t.Errorf("%s = %s, want %s", c.name, c.got, c.want)
+		}
+	}
+
+	// The buy fee is taken in BTC, the sells still have to leave the
+	// position flat
+	last := -1
+	for i, f := range result.Fills {
+		if f.Side == "SELL" {
+			last = i
+		} else if f.CommissionAsset != "BTC" {
+			t.Errorf("buy at %s paid its fee in %s, want BTC", f.Time, f.CommissionAsset)
+		}
+	}
+	if last < 0 {
+		t.Fatal("no sells")
+	}
+	if open := stats.OpenPositions(result.Fills[:last+1]); len(open) > 0 {
+		t.Errorf("%s %s left open after the last sell", open[0].Side, open[0].Quantity)
+	}
+
+	// The report has to agree with what the bot stats compute from the same
+	// fills, since both are shown side by side.
+	if want := stats.Compute(result.Fills); result.Stats.Trades != want.Trades ||
+		!result.Stats.RealizedPnl.Equal(want.RealizedPnl) ||
+		!result.Stats.ProfitFactor.Equal(want.ProfitFactor) {
+		t.Errorf("stats = %+v, want %+v", result.Stats, want)
+	}
+}
@@ -0,0 +1,151 @@
+package backtest
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"trade/internal/binance"
+)
+
+// ReadCSV reads klines in the layout of Binance's public kline dumps
+// (data.binance.vision): open time, open, high, low, close, volume, close
+// time, then optional columns that are ignored. A header row is skipped.
+// Times may be in milliseconds or, as in newer dumps, microseconds.
+func ReadCSV(r io.Reader) ([]binance.Kline, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	var klines []binance.Kline
+	for line := 1; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading line %d: %w", line, err)
+		}
+
+		if len(record) < 7 {
+			return nil, fmt.Errorf("line %d has %d columns, expected at least 7", line, len(record))
+		}
+
+		openTime, err := parseTime(record[0])
+		if err != nil {
+			if line == 1 {
+				continue // header
+			}
+			return nil, fmt.Errorf("line %d: invalid open time %q", line, record[0])
+		}
+
+		closeTime, err := parseTime(record[6])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid close time %q", line, record[6])
+		}
+
+		k := binance.Kline{
+			OpenTime:  openTime,
+			CloseTime: closeTime,
+			Open:      record[1],
+			High:      record[2],
+			Low:       record[3],
+			Close:     record[4],
+			Volume:    record[5],
+		}
+		if len(record) > 8 {
+			k.Trades, _ = strconv.ParseInt(record[8], 10, 64)
+		}
+
+		klines = append(klines, k)
+	}
+
+	sort.SliceStable(klines, func(i, j int) bool {
+		return klines[i].OpenTime.Before(klines[j].OpenTime)
+	})
+
+	return klines, nil
+}
+
+// WriteCSV writes klines in the layout ReadCSV reads, with millisecond times
+func WriteCSV(w io.Writer, klines []binance.Kline) error {
+	writer := csv.NewWriter(w)
+	writer.Write([]string{"open_time", "open", "high", "low", "close", "volume", "close_time", "quote_volume", "trades"})
+
+	for _, k := range klines {
+		writer.Write([]string{
+			strconv.FormatInt(k.OpenTime.UnixMilli(), 10),
+			k.Open, k.High, k.Low, k.Close, k.Volume,
+			strconv.FormatInt(k.CloseTime.UnixMilli(), 10),
+			"",
+			strconv.FormatInt(k.Trades, 10),
+		})
+	}
+
+	writer.Flush()
+	return writer.Error()
+}
+
+// Anything after this is taken to be in microseconds. Millisecond times only
+// get here in the year 5138.
+const maxMillis = 1e14
+
+func parseTime(s string) (time.Time, error) {
+	n, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if n > maxMillis {
+		return time.UnixMicro(n), nil
+	}
+	return time.UnixMilli(n), nil
+}
+
+// CachePath is where a local kline cache keeps the klines of symbol and interval
+func CachePath(dir, symbol, interval string) string {
+	return filepath.Join(dir, fmt.Sprintf("%s-%s.csv", symbol, interval))
+}
+
+// LoadKlines reads the klines of symbol and interval between start and end,
+// either from a CSV file or from a cache directory holding one
+// SYMBOL-interval.csv file per series. Zero start or end times are open.
+func LoadKlines(path, symbol, interval string, start, end time.Time) ([]binance.Kline, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if info.IsDir() {
+		path = CachePath(path, symbol, interval)
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	klines, err := ReadCSV(f)
+	if err != nil {
+		return nil, fmt.Errorf("error reading %s: %w", path, err)
+	}
+
+	filtered := klines[:0]
+	for _, k := range klines {
+		if !start.IsZero() && k.OpenTime.Before(start) {
+			continue
+		}
+		if !end.IsZero() && !k.OpenTime.Before(end) {
+			continue
+		}
+		filtered = append(filtered, k)
+	}
+
+	return filtered, nil
+}
@@ -6,21 +6,22 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
-	"log"
 	"net/http"
+	"net/url"
 	"strconv"
 	"time"
 )
 
 type Client struct {
-	ApiKey     string
-	ApiSecret  string
-	BaseURL    string
-	HttpClient *http.Client
+	ApiKey      string
+	ApiSecret   string
+	BaseURL     string
+	Environment Environment // set by NewForEnvironment
+	HttpClient  *http.Client
 }
 
 type AccountInfo struct {
+	CanTrade bool `json:"canTrade"`
 	Balances []Balance
 }
 
@@ -52,37 +53,90 @@ type CrossMarginAsset struct {
 }
 
 type ValidationResult struct {
-	IsValid       bool   `json:"is_valid"`
-	SpotEnabled   bool   `json:"spot_enabled"`
-	MarginEnabled bool   `json:"margin_enabled"`
-	ErrorMessage  string `json:"error_message"`
+	IsValid        bool             `json:"is_valid"`
+	SpotEnabled    bool             `json:"spot_enabled"`
+	MarginEnabled  bool             `json:"margin_enabled"`
+	FuturesEnabled bool             `json:"futures_enabled"`
+	Restrictions   *APIRestrictions `json:"restrictions,omitempty"` // nil on the testnets, which don't have the endpoint
+	ErrorMessage   string           `json:"error_message"`
 }
 
+// APIRestrictions are the permissions set on the API key
+type APIRestrictions struct {
+	IPRestrict                 bool  `json:"ipRestrict"`
+	CreateTime                 int64 `json:"createTime"`
+	EnableReading              bool  `json:"enableReading"`
+	EnableSpotAndMarginTrading bool  `json:"enableSpotAndMarginTrading"`
+	EnableMargin               bool  `json:"enableMargin"`
+	EnableFutures              bool  `json:"enableFutures"`
+	EnableWithdrawals          bool  `json:"enableWithdrawals"`
+	EnableInternalTransfer     bool  `json:"enableInternalTransfer"`
+	PermitsUniversalTransfer   bool  `json:"permitsUniversalTransfer"`
+}
+
+func (c Client) GetAPIRestrictions() (APIRestrictions, error) {
+	var restrictions APIRestrictions
+	if err := c.signedRequest(http.MethodGet, "/sapi/v1/account/apiRestrictions", nil, &restrictions); err != nil {
+		return APIRestrictions{}, err
+	}
+
+	return restrictions, nil
+}
+
+// ValidateAccount checks the key against the APIs of the client's
+// environment. A key Binance refuses gives an invalid result, other failures
+// such as timeouts are returned as errors since they say nothing about the key.
 func (c Client) ValidateAccount() (ValidationResult, error) {
 	var res ValidationResult
 
-	_, err := c.GetAccountInfo()
-	if err != nil {
-		res.IsValid = false
-		res.SpotEnabled = false
-		res.MarginEnabled = false
-		res.ErrorMessage = fmt.Sprintf("Spot API failed: %s", err.Error())
-		return res, nil
+	if c.BaseURL != "" {
+		account, err := c.GetAccountInfo()
+		if err != nil {
+			return refusedKey("Spot", err)
+		}
+		res.SpotEnabled = account.CanTrade
+
+		// The testnets have no margin or wallet endpoints
+		if !c.Environment.IsTestnet() {
+			_, err = c.GetMarginAccountInfo()
+			res.MarginEnabled = err == nil
+
+			restrictions, err := c.GetAPIRestrictions()
+			if err != nil {
+				return refusedKey("API restrictions", err)
+			}
+			res.Restrictions = &restrictions
+		}
 	}
 
-	res.IsValid = true
-	res.SpotEnabled = true
+	if c.Environment.FuturesURL() != "" {
+		account, err := c.GetFuturesAccount()
+		if err != nil && c.BaseURL == "" {
+			// Futures is all a futures testnet key can do
+			return refusedKey("Futures", err)
+		}
+		res.FuturesEnabled = err == nil && account.CanTrade
+	}
 
-	_, err = c.GetMarginAccountInfo()
-	if err != nil {
-		res.MarginEnabled = false
-	} else {
-		res.MarginEnabled = true
+	if res.Restrictions != nil {
+		res.SpotEnabled = res.SpotEnabled && res.Restrictions.EnableSpotAndMarginTrading
+		res.MarginEnabled = res.MarginEnabled && res.Restrictions.EnableSpotAndMarginTrading && res.Restrictions.EnableMargin
+		res.FuturesEnabled = res.FuturesEnabled && res.Restrictions.EnableFutures
 	}
 
+	res.IsValid = true
 	return res, nil
 }
 
+// refusedKey turns an error that means Binance refused the key into an
+// invalid result
+func refusedKey(api string, err error) (ValidationResult, error) {
+	if errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrForbidden) || errors.Is(err, ErrInvalidSignature) {
+		return ValidationResult{ErrorMessage: fmt.Sprintf("%s API failed: %s", api, err.Error())}, nil
+	}
+	return ValidationResult{}, fmt.Errorf("error validating key against the %s API: %w", api, err)
+}
+
 func New(key, secret, baseURL string) (*Client, error) {
 	if baseURL == "" {
 		baseURL = "https://api.binance.com"
@@ -106,6 +160,19 @@ func New(key, secret, baseURL string) (*Client, error) {
 	return &client, nil
 }
 
+// NewPublic creates a client without credentials, for market data endpoints
+// such as klines and prices
+func NewPublic(baseURL string) *Client {
+	if baseURL == "" {
+		baseURL = "https://api.binance.com"
+	}
+
+	return &Client{
+		BaseURL:    baseURL,
+		HttpClient: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
 func (c Client) signRequest(queryString string) string {
 	mac := hmac.New(sha256.New, []byte(c.ApiSecret))
 	mac.Write([]byte(queryString))
@@ -181,17 +248,7 @@ func (c Client) GetAccountInfo() (AccountInfo, error) {
 	timeStamp := time.Now().UnixMilli()
 	queryString := fmt.Sprintf("timestamp=%d", timeStamp)
 	signature := c.signRequest(queryString)
-
-	// Debug output
-	log.Printf("API Key: %s", c.ApiKey)
-	log.Printf("API Secret: %s", c.ApiSecret)
-	log.Printf("Timestamp: %d", timeStamp)
-	log.Printf("Query string: '%s'", queryString)
-	log.Printf("Signature: %s", signature)
-
 	finalQuery := fmt.Sprintf("%s&signature=%s", queryString, signature)
-	log.Printf("Final URL: %s/api/v3/account?%s", c.BaseURL, finalQuery)
-
 	url := fmt.Sprintf("%s/api/v3/account?%s", c.BaseURL, finalQuery)
 
 	req, err := http.NewRequest("GET", url, nil)
@@ -255,18 +312,121 @@ func (c Client) GetPrice(symbol string) (PriceData, error) {
 	return priceData, nil
 }
 
-func (c Client) CheckStatus(resp *http.Response) error {
-	switch resp.StatusCode {
-	case 200:
-		return nil
-	case 401:
-		return fmt.Errorf("unauthorized - check your API key")
-	case 403:
-		return fmt.Errorf("forbidden - check your API permissions")
-	case 429:
-		return fmt.Errorf("rate limit exceeded")
-	default:
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API error %d: %s", resp.StatusCode, string(body))
+// GetPrices returns the latest price per symbol in a single request.
+// Without symbols it returns every symbol on the exchange.
+func (c Client) GetPrices(symbols ...string) (map[string]string, error) {
+	endpoint := fmt.Sprintf("%s/api/v3/ticker/price", c.BaseURL)
+	if len(symbols) > 0 {
+		encoded, err := json.Marshal(symbols)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding symbols %v", err)
+		}
+		endpoint = fmt.Sprintf("%s?symbols=%s", endpoint, url.QueryEscape(string(encoded)))
+	}
+
+	req, err := http.NewRequest("GET", endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error making the request %v", err)
+	}
+
+	resp, err := c.HttpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error sending the request %v", err)
+	}
+	defer resp.Body.Close()
+
+	err = c.CheckStatus(resp)
+	if err != nil {
+		return nil, err
 	}
+
+	var priceData []PriceData
+	err = json.NewDecoder(resp.Body).Decode(&priceData)
+	if err != nil {
+		return nil, fmt.Errorf("error decoding the response %v", err)
+	}
+
+	prices := make(map[string]string, len(priceData))
+	for _, p := range priceData {
+		prices[p.Symbol] = p.Price
+	}
+
+	return prices, nil
+}
+
+// BookTicker is the best bid and ask on the order book
+type BookTicker struct {
+	Symbol   string `json:"symbol"`
+	BidPrice string `json:"bidPrice"`
+	BidQty   string `json:"bidQty"`
+	AskPrice string `json:"askPrice"`
+	AskQty   string `json:"askQty"`
+}
+
+func (c Client) GetBookTicker(symbol string) (BookTicker, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+
+	var ticker BookTicker
+	if err := c.publicRequest("/api/v3/ticker/bookTicker", params, &ticker); err != nil {
+		return BookTicker{}, err
+	}
+
+	return ticker, nil
+}
+
+type SymbolInfo struct {
+	Symbol              string           `json:"symbol"`
+	Status              string           `json:"status"`
+	BaseAsset           string           `json:"baseAsset"`
+	QuoteAsset          string           `json:"quoteAsset"`
+	BaseAssetPrecision  int32            `json:"baseAssetPrecision"`
+	QuoteAssetPrecision int32            `json:"quoteAssetPrecision"`
+	Filters             []map[string]any `json:"filters"`
+}
+
+// StepSize returns the LOT_SIZE step size, or an empty string if the symbol has none
+func (s SymbolInfo) StepSize() string {
+	for _, filter := range s.Filters {
+		if filter["filterType"] == "LOT_SIZE" {
+			stepSize, _ := filter["stepSize"].(string)
+			return stepSize
+		}
+	}
+	return ""
+}
+
+func (c Client) GetSymbolInfo(symbol string) (SymbolInfo, error) {
+	url := fmt.Sprintf("%s/api/v3/exchangeInfo?symbol=%s", c.BaseURL, symbol)
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return SymbolInfo{}, fmt.Errorf("error making the request %v", err)
+	}
+
+	resp, err := c.HttpClient.Do(req)
+	if err != nil {
+		return SymbolInfo{}, fmt.Errorf("error sending the request %v", err)
+	}
+	defer resp.Body.Close()
+
+	err = c.CheckStatus(resp)
+	if err != nil {
+		return SymbolInfo{}, err
+	}
+
+	var exchangeInfo struct {
+		Symbols []SymbolInfo `json:"symbols"`
+	}
+
+	err = json.NewDecoder(resp.Body).Decode(&exchangeInfo)
+	if err != nil {
+		return SymbolInfo{}, fmt.Errorf("error decoding the response %v", err)
+	}
+
+	if len(exchangeInfo.Symbols) == 0 {
+		return SymbolInfo{}, fmt.Errorf("%w: %s", ErrInvalidSymbol, symbol)
+	}
+
+	return exchangeInfo.Symbols[0], nil
 }
@@ -0,0 +1,24 @@
+package binance
+
+import (
+	"fmt"
+
+	"trade/internal/secrets"
+)
+
+// NewFromEncrypted creates a client for an account of env from credentials
+// as stored in exchange_accounts. The plaintext key and secret only live in
+// the client.
+func NewFromEncrypted(encryptedKey, encryptedSecret string, env Environment) (*Client, error) {
+	key, err := secrets.Decrypt(encryptedKey)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting api key: %w", err)
+	}
+
+	secret, err := secrets.Decrypt(encryptedSecret)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting api secret: %w", err)
+	}
+
+	return NewForEnvironment(key, secret, env)
+}
@@ -0,0 +1,85 @@
+package binance
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Environment is the Binance deployment an account lives on. Each resolves to
+// fixed API hosts, base URLs are never taken from user input.
+type Environment string
+
+const (
+	Mainnet        Environment = "mainnet"
+	SpotTestnet    Environment = "spot-testnet"
+	FuturesTestnet Environment = "futures-testnet"
+)
+
+var (
+	// ErrNoSpotAPI is returned for spot requests on an environment without a spot API
+	ErrNoSpotAPI = errors.New("environment has no spot API")
+	// ErrNoFuturesAPI is returned for futures requests on an environment without a futures API
+	ErrNoFuturesAPI = errors.New("environment has no futures API")
+)
+
+type endpoints struct {
+	spot    string
+	futures string
+}
+
+// The spot testnet has no futures API and the futures testnet no spot API
+var environments = map[Environment]endpoints{
+	Mainnet:        {spot: "https://api.binance.com", futures: "https://fapi.binance.com"},
+	SpotTestnet:    {spot: "https://testnet.binance.vision"},
+	FuturesTestnet: {futures: "https://testnet.binancefuture.com"},
+}
+
+// Environments lists the environments an account can be on, mainnet first
+func Environments() []Environment {
+	return []Environment{Mainnet, SpotTestnet, FuturesTestnet}
+}
+
+func (e Environment) IsValid() bool {
+	_, ok := environments[e]
+	return ok
+}
+
+// IsTestnet reports whether the environment trades with test funds
+func (e Environment) IsTestnet() bool {
+	return e == SpotTestnet || e == FuturesTestnet
+}
+
+// SpotURL is the base URL of the spot and margin API, empty when the
+// environment has none
+func (e Environment) SpotURL() string {
+	return environments[e].spot
+}
+
+// FuturesURL is the base URL of the USDⓈ-M futures API, empty when the
+// environment has none
+func (e Environment) FuturesURL() string {
+	return environments[e].futures
+}
+
+// HasSpot reports whether the environment has a spot API
+func (e Environment) HasSpot() bool {
+	return e.SpotURL() != ""
+}
+
+// NewForEnvironment creates a client for the APIs of env. A futures testnet
+// client has no spot API, its spot requests fail with ErrNoSpotAPI.
+func NewForEnvironment(key, secret string, env Environment) (*Client, error) {
+	if !env.IsValid() {
+		return nil, fmt.Errorf("unknown Binance environment %q", env)
+	}
+
+	client, err := New(key, secret, env.SpotURL())
+	if err != nil {
+		return nil, err
+	}
+	// New falls back to mainnet without a URL, a testnet key must never reach it
+	client.BaseURL = env.SpotURL()
+	client.Environment = env
+
+	return client, nil
+}
@@ -0,0 +1,81 @@
+package binance
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+var (
+	ErrUnauthorized        = errors.New("unauthorized - check your API key")
+	ErrForbidden           = errors.New("forbidden - check your API permissions")
+	ErrRateLimited         = errors.New("rate limit exceeded")
+	ErrIPBanned            = errors.New("IP banned for exceeding rate limits")
+	ErrInvalidSignature    = errors.New("invalid request signature")
+	ErrTimestamp           = errors.New("request timestamp outside recvWindow")
+	ErrInvalidSymbol       = errors.New("invalid symbol")
+	ErrInvalidOrder        = errors.New("invalid order parameters")
+	ErrOrderRejected       = errors.New("order rejected")
+	ErrInsufficientBalance = errors.New("insufficient balance")
+	ErrUnknownOrder        = errors.New("unknown order")
+	ErrNoMarginAccount     = errors.New("no margin account")
+)
+
+// APIError is an error response from the Binance API.
+// It matches the sentinel errors above with errors.Is.
+type APIError struct {
+	StatusCode int    `json:"-"`
+	Code       int    `json:"code"`
+	Message    string `json:"msg"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error %d (code %d): %s", e.StatusCode, e.Code, e.Message)
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.Code == -2014 || e.Code == -2015
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests || e.Code == -1003 || e.Code == -1015
+	case ErrIPBanned:
+		return e.StatusCode == http.StatusTeapot
+	case ErrInvalidSignature:
+		return e.Code == -1022
+	case ErrTimestamp:
+		return e.Code == -1021
+	case ErrInvalidSymbol:
+		return e.Code == -1121
+	case ErrInvalidOrder:
+		return e.Code == -1013 || e.Code == -1100 || e.Code == -1102 || e.Code == -1106 || e.Code == -1111 || e.Code == -1116 || e.Code == -1117
+	case ErrOrderRejected:
+		return e.Code == -2010 || e.Code == -2011
+	case ErrInsufficientBalance:
+		return e.Code == -2018 || e.Code == -3041 || (e.Code == -2010 && e.Message == "Account has insufficient balance for requested action.")
+	case ErrUnknownOrder:
+		return e.Code == -2013 || (e.Code == -2011 && e.Message == "Unknown order sent.")
+	case ErrNoMarginAccount:
+		return e.Code == -3003
+	}
+	return false
+}
+
+func (c Client) CheckStatus(resp *http.Response) error {
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+	if err := json.Unmarshal(body, apiErr); err != nil || apiErr.Message == "" {
+		apiErr.Message = string(body)
+	}
+
+	return apiErr
+}
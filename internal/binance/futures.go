@@ -0,0 +1,278 @@
+package binance
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+)
+
+// FuturesAccount is the USDⓈ-M futures account. The margin balance is the
+// wallet balance plus the unrealized PnL of the open positions.
+type FuturesAccount struct {
+	CanTrade              bool           `json:"canTrade"`
+	TotalWalletBalance    string         `json:"totalWalletBalance"`
+	TotalUnrealizedProfit string         `json:"totalUnrealizedProfit"`
+	TotalMarginBalance    string         `json:"totalMarginBalance"`
+	AvailableBalance      string         `json:"availableBalance"`
+	MaxWithdrawAmount     string         `json:"maxWithdrawAmount"`
+	Assets                []FuturesAsset `json:"assets"`
+}
+
+// FuturesAsset is one margin asset of the futures wallet
+type FuturesAsset struct {
+	Asset            string `json:"asset"`
+	WalletBalance    string `json:"walletBalance"`
+	UnrealizedProfit string `json:"unrealizedProfit"`
+	MarginBalance    string `json:"marginBalance"`
+	AvailableBalance string `json:"availableBalance"`
+}
+
+// FuturesPosition is a position from positionRisk. PositionAmt is negative
+// for shorts in one-way mode.
+type FuturesPosition struct {
+	Symbol           string       `json:"symbol"`
+	PositionAmt      string       `json:"positionAmt"`
+	EntryPrice       string       `json:"entryPrice"`
+	MarkPrice        string       `json:"markPrice"`
+	UnRealizedProfit string       `json:"unRealizedProfit"`
+	LiquidationPrice string       `json:"liquidationPrice"`
+	Leverage         string       `json:"leverage"`
+	MarginType       string       `json:"marginType"` // "cross" or "isolated"
+	IsolatedMargin   string       `json:"isolatedMargin"`
+	PositionSide     PositionSide `json:"positionSide"`
+	Notional         string       `json:"notional"`
+	UpdateTime       int64        `json:"updateTime"`
+}
+
+// PositionSide is BOTH in one-way mode, LONG or SHORT in hedge mode
+type PositionSide string
+
+const (
+	PositionSideBoth  PositionSide = "BOTH"
+	PositionSideLong  PositionSide = "LONG"
+	PositionSideShort PositionSide = "SHORT"
+)
+
+type MarginType string
+
+const (
+	MarginTypeIsolated MarginType = "ISOLATED"
+	MarginTypeCrossed  MarginType = "CROSSED"
+)
+
+// Futures only order types
+const (
+	OrderTypeStopMarket       OrderType = "STOP_MARKET"
+	OrderTypeTakeProfitMarket OrderType = "TAKE_PROFIT_MARKET"
+)
+
+type FuturesOrderRequest struct {
+	Symbol           string
+	Side             OrderSide
+	PositionSide     PositionSide // empty in one-way mode
+	Type             OrderType
+	TimeInForce      TimeInForce
+	Quantity         string
+	Price            string
+	StopPrice        string
+	ReduceOnly       bool
+	NewClientOrderID string
+}
+
+func (r FuturesOrderRequest) validate() error {
+	if r.Symbol == "" {
+		return fmt.Errorf("%w: symbol is required", ErrInvalidOrder)
+	}
+
+	if r.Side != SideBuy && r.Side != SideSell {
+		return fmt.Errorf("%w: invalid side %q", ErrInvalidOrder, r.Side)
+	}
+
+	switch r.Type {
+	case OrderTypeMarket:
+		if r.Quantity == "" {
+			return fmt.Errorf("%w: MARKET orders need quantity", ErrInvalidOrder)
+		}
+	case OrderTypeLimit:
+		if r.Quantity == "" || r.Price == "" {
+			return fmt.Errorf("%w: LIMIT orders need quantity and price", ErrInvalidOrder)
+		}
+	case OrderTypeStopMarket, OrderTypeTakeProfitMarket:
+		if r.Quantity == "" || r.StopPrice == "" {
+			return fmt.Errorf("%w: %s orders need quantity and stop price", ErrInvalidOrder, r.Type)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported futures order type %q", ErrInvalidOrder, r.Type)
+	}
+
+	return nil
+}
+
+func (r FuturesOrderRequest) params() url.Values {
+	params := url.Values{}
+	params.Set("symbol", r.Symbol)
+	params.Set("side", string(r.Side))
+	params.Set("type", string(r.Type))
+	params.Set("newOrderRespType", "RESULT")
+
+	if r.Type == OrderTypeLimit {
+		timeInForce := r.TimeInForce
+		if timeInForce == "" {
+			timeInForce = TimeInForceGTC
+		}
+		params.Set("timeInForce", string(timeInForce))
+	}
+
+	// Binance rejects reduceOnly in hedge mode, the position side says it all
+	if r.ReduceOnly && r.PositionSide == "" {
+		params.Set("reduceOnly", "true")
+	}
+
+	setIfNotEmpty(params, "positionSide", string(r.PositionSide))
+	setIfNotEmpty(params, "quantity", r.Quantity)
+	setIfNotEmpty(params, "price", r.Price)
+	setIfNotEmpty(params, "stopPrice", r.StopPrice)
+	setIfNotEmpty(params, "newClientOrderId", r.NewClientOrderID)
+
+	return params
+}
+
+type FuturesOrder struct {
+	OrderID       int64        `json:"orderId"`
+	Symbol        string       `json:"symbol"`
+	Status        OrderStatus  `json:"status"`
+	ClientOrderID string       `json:"clientOrderId"`
+	Price         string       `json:"price"`
+	AvgPrice      string       `json:"avgPrice"`
+	OrigQty       string       `json:"origQty"`
+	ExecutedQty   string       `json:"executedQty"`
+	CumQuote      string       `json:"cumQuote"`
+	TimeInForce   TimeInForce  `json:"timeInForce"`
+	Type          OrderType    `json:"type"`
+	ReduceOnly    bool         `json:"reduceOnly"`
+	Side          OrderSide    `json:"side"`
+	PositionSide  PositionSide `json:"positionSide"`
+	StopPrice     string       `json:"stopPrice"`
+	UpdateTime    int64        `json:"updateTime"`
+}
+
+// Leverage is the leverage of a symbol after a change
+type Leverage struct {
+	Symbol           string `json:"symbol"`
+	Leverage         int    `json:"leverage"`
+	MaxNotionalValue string `json:"maxNotionalValue"`
+}
+
+func (c Client) GetFuturesAccount() (FuturesAccount, error) {
+	var account FuturesAccount
+	if err := c.futuresRequest(http.MethodGet, "/fapi/v2/account", nil, &account); err != nil {
+		return FuturesAccount{}, err
+	}
+
+	return account, nil
+}
+
+// GetFuturesPositions returns the positions of symbol, or of all symbols if
+// symbol is empty. Binance lists flat positions too.
+func (c Client) GetFuturesPositions(symbol string) ([]FuturesPosition, error) {
+	params := url.Values{}
+	setIfNotEmpty(params, "symbol", symbol)
+
+	var positions []FuturesPosition
+	if err := c.futuresRequest(http.MethodGet, "/fapi/v2/positionRisk", params, &positions); err != nil {
+		return nil, err
+	}
+
+	return positions, nil
+}
+
+func (c Client) PlaceFuturesOrder(req FuturesOrderRequest) (FuturesOrder, error) {
+	if err := req.validate(); err != nil {
+		return FuturesOrder{}, err
+	}
+
+	var order FuturesOrder
+	if err := c.futuresRequest(http.MethodPost, "/fapi/v1/order", req.params(), &order); err != nil {
+		return FuturesOrder{}, err
+	}
+
+	return order, nil
+}
+
+func (c Client) CancelFuturesOrder(symbol string, orderID int64) (FuturesOrder, error) {
+	return c.futuresOrderByID(http.MethodDelete, symbol, orderID)
+}
+
+func (c Client) GetFuturesOrder(symbol string, orderID int64) (FuturesOrder, error) {
+	return c.futuresOrderByID(http.MethodGet, symbol, orderID)
+}
+
+func (c Client) futuresOrderByID(method, symbol string, orderID int64) (FuturesOrder, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("orderId", strconv.FormatInt(orderID, 10))
+
+	var order FuturesOrder
+	if err := c.futuresRequest(method, "/fapi/v1/order", params, &order); err != nil {
+		return FuturesOrder{}, err
+	}
+
+	return order, nil
+}
+
+// GetFuturesOpenOrders returns open orders for symbol, or for all symbols if symbol is empty
+func (c Client) GetFuturesOpenOrders(symbol string) ([]FuturesOrder, error) {
+	params := url.Values{}
+	setIfNotEmpty(params, "symbol", symbol)
+
+	var orders []FuturesOrder
+	if err := c.futuresRequest(http.MethodGet, "/fapi/v1/openOrders", params, &orders); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+// ChangeLeverage sets the initial leverage of symbol, from 1 up to the
+// symbol's maximum
+func (c Client) ChangeLeverage(symbol string, leverage int) (Leverage, error) {
+	if leverage < 1 || leverage > 125 {
+		return Leverage{}, fmt.Errorf("%w: leverage %d out of range", ErrInvalidOrder, leverage)
+	}
+
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("leverage", strconv.Itoa(leverage))
+
+	var res Leverage
+	if err := c.futuresRequest(http.MethodPost, "/fapi/v1/leverage", params, &res); err != nil {
+		return Leverage{}, err
+	}
+
+	return res, nil
+}
+
+// ChangeMarginType switches symbol between isolated and cross margin.
+// Switching to the margin type the symbol already has is not an error.
+func (c Client) ChangeMarginType(symbol string, marginType MarginType) error {
+	if marginType != MarginTypeIsolated && marginType != MarginTypeCrossed {
+		return fmt.Errorf("%w: invalid margin type %q", ErrInvalidOrder, marginType)
+	}
+
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("marginType", string(marginType))
+
+	var res struct{}
+	err := c.futuresRequest(http.MethodPost, "/fapi/v1/marginType", params, &res)
+
+	// -4046: No need to change margin type
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Code == -4046 {
+		return nil
+	}
+
+	return err
+}
@@ -0,0 +1,194 @@
+package binance
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// Binance answers history requests for at most this range at once
+const HistoryWindow = 30 * 24 * time.Hour
+
+// Deposit statuses that mean the funds were credited. Credited deposits can't
+// be withdrawn yet, but are already part of the balance.
+const (
+	DepositStatusSuccess  = 1
+	DepositStatusCredited = 6
+)
+
+// WithdrawalStatusCompleted is the status of a withdrawal that left the account
+const WithdrawalStatusCompleted = 6
+
+// Transfer types between the spot, cross margin and USDⓈ-M futures wallets
+const (
+	TransferMainToMargin    = "MAIN_MARGIN"
+	TransferMarginToMain    = "MARGIN_MAIN"
+	TransferMainToFutures   = "MAIN_UMFUTURE"
+	TransferFuturesToMain   = "UMFUTURE_MAIN"
+	TransferMarginToFutures = "MARGIN_UMFUTURE"
+	TransferFuturesToMargin = "UMFUTURE_MARGIN"
+)
+
+// Transfer types between the cross margin wallet and the wallet of an
+// isolated pair. Their history is kept per pair, see GetIsolatedTransfers.
+const (
+	TransferIsolatedToMargin = "ISOLATEDMARGIN_MARGIN"
+	TransferMarginToIsolated = "MARGIN_ISOLATEDMARGIN"
+)
+
+// Wallets an isolated margin transfer moves funds from or to
+const (
+	IsolatedTransferSpot     = "SPOT"
+	IsolatedTransferIsolated = "ISOLATED_MARGIN"
+)
+
+type Deposit struct {
+	ID         string `json:"id"`
+	Amount     string `json:"amount"`
+	Coin       string `json:"coin"`
+	Network    string `json:"network"`
+	Status     int    `json:"status"`
+	TxID       string `json:"txId"`
+	InsertTime int64  `json:"insertTime"`
+}
+
+type Withdrawal struct {
+	ID             string `json:"id"`
+	Amount         string `json:"amount"`
+	TransactionFee string `json:"transactionFee"`
+	Coin           string `json:"coin"`
+	Network        string `json:"network"`
+	Status         int    `json:"status"`
+	TxID           string `json:"txId"`
+	ApplyTime      string `json:"applyTime"` // UTC, "2006-01-02 15:04:05"
+}
+
+// Time parses ApplyTime
+func (w Withdrawal) Time() (time.Time, error) {
+	return time.Parse(time.DateTime, w.ApplyTime)
+}
+
+type Transfer struct {
+	TranID    int64  `json:"tranId"`
+	Asset     string `json:"asset"`
+	Amount    string `json:"amount"`
+	Type      string `json:"type"`
+	Status    string `json:"status"`
+	Timestamp int64  `json:"timestamp"`
+}
+
+// MarginTransfer is a transfer into or out of the wallet of an isolated pair
+type MarginTransfer struct {
+	TxID      int64  `json:"txId"`
+	Asset     string `json:"asset"`
+	Amount    string `json:"amount"`
+	Type      string `json:"type"` // ROLL_IN or ROLL_OUT
+	Status    string `json:"status"`
+	Timestamp int64  `json:"timestamp"`
+	TransFrom string `json:"transFrom"`
+	TransTo   string `json:"transTo"`
+}
+
+// GetDeposits returns the deposits between start and end, at most HistoryWindow apart
+func (c Client) GetDeposits(start, end time.Time) ([]Deposit, error) {
+	params := url.Values{}
+	params.Set("startTime", strconv.FormatInt(start.UnixMilli(), 10))
+	params.Set("endTime", strconv.FormatInt(end.UnixMilli(), 10))
+	params.Set("limit", "1000")
+
+	var deposits []Deposit
+	if err := c.signedRequest("GET", "/sapi/v1/capital/deposit/hisrec", params, &deposits); err != nil {
+		return nil, err
+	}
+
+	return deposits, nil
+}
+
+// GetWithdrawals returns the withdrawals between start and end, at most HistoryWindow apart
+func (c Client) GetWithdrawals(start, end time.Time) ([]Withdrawal, error) {
+	params := url.Values{}
+	params.Set("startTime", strconv.FormatInt(start.UnixMilli(), 10))
+	params.Set("endTime", strconv.FormatInt(end.UnixMilli(), 10))
+	params.Set("limit", "1000")
+
+	var withdrawals []Withdrawal
+	if err := c.signedRequest("GET", "/sapi/v1/capital/withdraw/history", params, &withdrawals); err != nil {
+		return nil, err
+	}
+
+	return withdrawals, nil
+}
+
+// GetTransfers returns the transfers of one type between start and end, at
+// most HistoryWindow apart. It pages through the results.
+func (c Client) GetTransfers(transferType string, start, end time.Time) ([]Transfer, error) {
+	params := url.Values{}
+	params.Set("type", transferType)
+	return pagedHistory[Transfer](c, "/sapi/v1/asset/transfer", params, start, end)
+}
+
+// GetIsolatedTransfers returns the transfers of one type between the cross
+// margin wallet and the wallet of the isolated pair symbol
+func (c Client) GetIsolatedTransfers(transferType, symbol string, start, end time.Time) ([]Transfer, error) {
+	params := url.Values{}
+	params.Set("type", transferType)
+	if transferType == TransferIsolatedToMargin {
+		params.Set("fromSymbol", symbol)
+	} else {
+		params.Set("toSymbol", symbol)
+	}
+	return pagedHistory[Transfer](c, "/sapi/v1/asset/transfer", params, start, end)
+}
+
+// GetIsolatedMarginTransfers returns the transfers into and out of the
+// wallet of the isolated pair symbol between start and end
+func (c Client) GetIsolatedMarginTransfers(symbol string, start, end time.Time) ([]MarginTransfer, error) {
+	params := url.Values{}
+	params.Set("isolatedSymbol", symbol)
+	return pagedHistory[MarginTransfer](c, "/sapi/v1/margin/transfer", params, start, end)
+}
+
+// pagedHistory pages through a history endpoint that answers with the rows
+// of one page and the total number of rows
+func pagedHistory[T any](c Client, path string, params url.Values, start, end time.Time) ([]T, error) {
+	const pageSize = 100
+
+	params.Set("startTime", strconv.FormatInt(start.UnixMilli(), 10))
+	params.Set("endTime", strconv.FormatInt(end.UnixMilli(), 10))
+	params.Set("size", strconv.Itoa(pageSize))
+
+	var rows []T
+	for page := 1; ; page++ {
+		params.Set("current", strconv.Itoa(page))
+
+		var result struct {
+			Total int `json:"total"`
+			Rows  []T `json:"rows"`
+		}
+		if err := c.signedRequest("GET", path, params, &result); err != nil {
+			return nil, err
+		}
+
+		rows = append(rows, result.Rows...)
+		if len(result.Rows) < pageSize || len(rows) >= result.Total {
+			return rows, nil
+		}
+	}
+}
+
+// GetPriceAt returns the open price of symbol in the minute containing at
+func (c Client) GetPriceAt(symbol string, at time.Time) (decimal.Decimal, error) {
+	klines, err := c.GetKlines(symbol, "1m", at.Truncate(time.Minute), time.Time{}, 1)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	if len(klines) == 0 {
+		return decimal.Zero, fmt.Errorf("no price for %s at %s", symbol, at.Format(time.RFC3339))
+	}
+
+	return decimal.NewFromString(klines[0].Open)
+}
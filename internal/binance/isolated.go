@@ -0,0 +1,118 @@
+package binance
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// IsolatedMarginAccount holds the isolated margin account of every pair
+// that has one. Each pair borrows against its own collateral only.
+type IsolatedMarginAccount struct {
+	Assets              []IsolatedMarginPair `json:"assets"`
+	TotalAssetOfBtc     string               `json:"totalAssetOfBtc"`
+	TotalLiabilityOfBtc string               `json:"totalLiabilityOfBtc"`
+	TotalNetAssetOfBtc  string               `json:"totalNetAssetOfBtc"`
+	TotalNetAssetOfUSDT string               `json:"totalNetAssetOfUsdt"`
+}
+
+type IsolatedMarginPair struct {
+	Symbol            string              `json:"symbol"`
+	BaseAsset         IsolatedMarginAsset `json:"baseAsset"`
+	QuoteAsset        IsolatedMarginAsset `json:"quoteAsset"`
+	IsolatedCreated   bool                `json:"isolatedCreated"`
+	Enabled           bool                `json:"enabled"`
+	TradeEnabled      bool                `json:"tradeEnabled"`
+	MarginLevel       string              `json:"marginLevel"`
+	MarginLevelStatus string              `json:"marginLevelStatus"`
+	MarginRatio       string              `json:"marginRatio"`
+	IndexPrice        string              `json:"indexPrice"`
+	LiquidatePrice    string              `json:"liquidatePrice"`
+	LiquidateRate     string              `json:"liquidateRate"`
+}
+
+type IsolatedMarginAsset struct {
+	Asset         string `json:"asset"`
+	BorrowEnabled bool   `json:"borrowEnabled"`
+	RepayEnabled  bool   `json:"repayEnabled"`
+	Borrowed      string `json:"borrowed"`
+	Free          string `json:"free"`
+	Interest      string `json:"interest"`
+	Locked        string `json:"locked"`
+	NetAsset      string `json:"netAsset"`
+	NetAssetOfBtc string `json:"netAssetOfBtc"`
+	TotalAsset    string `json:"totalAsset"`
+}
+
+// Liability is what the pair owes in the asset, the loan plus its interest
+func (a IsolatedMarginAsset) Liability() decimal.Decimal {
+	borrowed, _ := decimal.NewFromString(a.Borrowed)
+	interest, _ := decimal.NewFromString(a.Interest)
+	return borrowed.Add(interest)
+}
+
+// GetIsolatedMarginAccount returns the isolated margin pairs, limited to
+// symbols when given. Binance takes at most 5 symbols per request.
+func (c Client) GetIsolatedMarginAccount(symbols ...string) (IsolatedMarginAccount, error) {
+	if len(symbols) > 5 {
+		return IsolatedMarginAccount{}, fmt.Errorf("at most 5 isolated symbols per request, got %d", len(symbols))
+	}
+
+	params := url.Values{}
+	if len(symbols) > 0 {
+		params.Set("symbols", strings.Join(symbols, ","))
+	}
+
+	var account IsolatedMarginAccount
+	if err := c.signedRequest(http.MethodGet, "/sapi/v1/margin/isolated/account", params, &account); err != nil {
+		return IsolatedMarginAccount{}, err
+	}
+
+	asset, err := c.BtcAsset2Usdt(account.TotalAssetOfBtc)
+	if err != nil {
+		return IsolatedMarginAccount{}, fmt.Errorf("error getting the isolated margin balance %v", err)
+	}
+
+	liability, err := c.BtcAsset2Usdt(account.TotalLiabilityOfBtc)
+	if err != nil {
+		return IsolatedMarginAccount{}, fmt.Errorf("error getting the isolated liabilities %v", err)
+	}
+
+	account.TotalNetAssetOfUSDT = fmt.Sprintf("%.2f", (asset - liability))
+
+	return account, nil
+}
+
+// PlaceIsolatedMarginOrder places req on the isolated margin account of its symbol
+func (c Client) PlaceIsolatedMarginOrder(req OrderRequest) (Order, error) {
+	req.IsIsolated = true
+	return c.placeOrder("/sapi/v1/margin/order", req)
+}
+
+func (c Client) CancelIsolatedMarginOrder(symbol string, orderID int64) (Order, error) {
+	return c.orderByID(http.MethodDelete, "/sapi/v1/margin/order", symbol, orderID, true)
+}
+
+func (c Client) GetIsolatedMarginOrder(symbol string, orderID int64) (Order, error) {
+	return c.orderByID(http.MethodGet, "/sapi/v1/margin/order", symbol, orderID, true)
+}
+
+func (c Client) GetIsolatedMarginOrderByClientID(symbol, clientOrderID string) (Order, error) {
+	return c.orderByClientID("/sapi/v1/margin/order", symbol, clientOrderID, true)
+}
+
+// GetIsolatedMarginOpenOrders returns the open orders of one isolated pair,
+// Binance has no listing across pairs
+func (c Client) GetIsolatedMarginOpenOrders(symbol string) ([]Order, error) {
+	if symbol == "" {
+		return nil, fmt.Errorf("%w: isolated open orders need a symbol", ErrInvalidOrder)
+	}
+	return c.openOrders("/sapi/v1/margin/openOrders", symbol, true)
+}
+
+func (c Client) GetIsolatedMarginOrderTrades(symbol string, orderID int64) ([]Trade, error) {
+	return c.orderTrades("/sapi/v1/margin/myTrades", symbol, orderID, true)
+}
@@ -0,0 +1,159 @@
+package binance
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Kline intervals supported by Binance and their length. 1M is left out as
+// months don't have a fixed length.
+var intervals = map[string]time.Duration{
+	"1m":  time.Minute,
+	"3m":  3 * time.Minute,
+	"5m":  5 * time.Minute,
+	"15m": 15 * time.Minute,
+	"30m": 30 * time.Minute,
+	"1h":  time.Hour,
+	"2h":  2 * time.Hour,
+	"4h":  4 * time.Hour,
+	"6h":  6 * time.Hour,
+	"8h":  8 * time.Hour,
+	"12h": 12 * time.Hour,
+	"1d":  24 * time.Hour,
+	"3d":  3 * 24 * time.Hour,
+	"1w":  7 * 24 * time.Hour,
+}
+
+// Intervals returns the supported kline intervals from shortest to longest
+func Intervals() []string {
+	names := make([]string, 0, len(intervals))
+	for name := range intervals {
+		names = append(names, name)
+	}
+	sort.Slice(names, func(i, j int) bool {
+		return intervals[names[i]] < intervals[names[j]]
+	})
+	return names
+}
+
+// IntervalDuration returns the length of a kline interval such as "15m"
+func IntervalDuration(interval string) (time.Duration, bool) {
+	d, ok := intervals[interval]
+	return d, ok
+}
+
+type Kline struct {
+	OpenTime  time.Time
+	CloseTime time.Time
+	Open      string
+	High      string
+	Low       string
+	Close     string
+	Volume    string
+	Trades    int64
+}
+
+// UnmarshalJSON decodes a kline from the array Binance sends
+func (k *Kline) UnmarshalJSON(data []byte) error {
+	var fields []json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	if len(fields) < 9 {
+		return fmt.Errorf("kline has %d fields, expected at least 9", len(fields))
+	}
+
+	var openTime, closeTime int64
+	targets := []struct {
+		index int
+		into  any
+	}{
+		{0, &openTime}, {1, &k.Open}, {2, &k.High}, {3, &k.Low}, {4, &k.Close},
+		{5, &k.Volume}, {6, &closeTime}, {8, &k.Trades},
+	}
+	for _, t := range targets {
+		if err := json.Unmarshal(fields[t.index], t.into); err != nil {
+			return fmt.Errorf("kline field %d: %w", t.index, err)
+		}
+	}
+
+	k.OpenTime = time.UnixMilli(openTime)
+	k.CloseTime = time.UnixMilli(closeTime)
+	return nil
+}
+
+// GetKlines returns up to limit klines of symbol from start to end. Zero
+// start or end times are left to Binance, which then returns the latest klines.
+func (c Client) GetKlines(symbol, interval string, start, end time.Time, limit int) ([]Kline, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("interval", interval)
+	if !start.IsZero() {
+		params.Set("startTime", strconv.FormatInt(start.UnixMilli(), 10))
+	}
+	if !end.IsZero() {
+		params.Set("endTime", strconv.FormatInt(end.UnixMilli(), 10))
+	}
+	if limit > 0 {
+		params.Set("limit", strconv.Itoa(limit))
+	}
+
+	var klines []Kline
+	if err := c.publicRequest("/api/v3/klines", params, &klines); err != nil {
+		return nil, err
+	}
+
+	return klines, nil
+}
+
+// Most klines Binance returns per request
+const maxKlinesPerRequest = 1000
+
+// PageKlines walks the klines of symbol from start until end, one request of
+// up to 1000 klines at a time, and hands every page to page in order. A zero
+// end walks up to the latest kline. Stopping early is done by returning an
+// error from page.
+func (c Client) PageKlines(symbol, interval string, start, end time.Time, page func([]Kline) error) error {
+	if _, ok := IntervalDuration(interval); !ok {
+		return fmt.Errorf("unknown kline interval %q", interval)
+	}
+
+	cursor := start
+	for {
+		klines, err := c.GetKlines(symbol, interval, cursor, end, maxKlinesPerRequest)
+		if err != nil {
+			return err
+		}
+		if len(klines) == 0 {
+			return nil
+		}
+
+		if err := page(klines); err != nil {
+			return err
+		}
+
+		last := klines[len(klines)-1]
+		if len(klines) < maxKlinesPerRequest || (!end.IsZero() && !last.CloseTime.Before(end)) {
+			return nil
+		}
+		cursor = last.CloseTime.Add(time.Millisecond)
+	}
+}
+
+// GetKlinesRange returns every kline of symbol from start until end, however
+// many requests that takes
+func (c Client) GetKlinesRange(symbol, interval string, start, end time.Time) ([]Kline, error) {
+	var all []Kline
+	err := c.PageKlines(symbol, interval, start, end, func(klines []Kline) error {
+		all = append(all, klines...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return all, nil
+}
@@ -0,0 +1,116 @@
+package binance
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// klineServer serves hourly klines from first until last the way
+// /api/v3/klines does, and counts the requests it gets
+type klineServer struct {
+	first, last time.Time
+	requests    int
+}
+
+func (s *klineServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.requests++
+	query := r.URL.Query()
+
+	limit := 500
+	if v := query.Get("limit"); v != "" {
+		limit, _ = strconv.Atoi(v)
+	}
+	start := s.first
+	if v := query.Get("startTime"); v != "" {
+		ms, _ := strconv.ParseInt(v, 10, 64)
+		start = time.UnixMilli(ms)
+	}
+	end := s.last
+	if v := query.Get("endTime"); v != "" {
+		ms, _ := strconv.ParseInt(v, 10, 64)
+		if t := time.UnixMilli(ms); t.Before(end) {
+			end = t
+		}
+	}
+
+	klines := [][]any{}
+	for open := s.first; !open.After(end) && len(klines) < limit; open = open.Add(time.Hour) {
+		if open.Before(start) {
+			continue
+		}
+		closeTime := open.Add(time.Hour - time.Millisecond).UnixMilli()
+		klines = append(klines, []any{open.UnixMilli(), "1", "2", "0.5", "1.5", "10", closeTime, "15", 3, "5", "7", "0"})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(klines)
+}
+
+func newKlineServer(t *testing.T, first time.Time, count int) (*klineServer, *Client) {
+	s := &klineServer{first: first, last: first.Add(time.Duration(count-1) * time.Hour)}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	return s, NewPublic(server.URL)
+}
+
+func TestGetKlinesRangePagesPastLimit(t *testing.T) {
+	first := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s, client := newKlineServer(t, first, 2500)
+
+	klines, err := client.GetKlinesRange("BTCUSDT", "1h", first, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(klines) != 2500 {
+		t.Fatalf("got %d klines, want 2500", len(klines))
+	}
+	for i, k := range klines {
+		if want := first.Add(time.Duration(i) * time.Hour); !k.OpenTime.Equal(want) {
+			t.Fatalf("kline %d opens at %s, want %s", i, k.OpenTime.UTC(), want)
+		}
+	}
+	// Two full pages and a short one that ends the walk
+	if s.requests != 3 {
+		t.Errorf("made %d requests, want 3", s.requests)
+	}
+}
+
+func TestGetKlinesRangeStopsAtEnd(t *testing.T) {
+	first := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	for _, c := range []struct {
+		name     string
+		hours    int
+		requests int
+	}{
+		// The end falls inside the second page
+		{"mid page", 1500, 2},
+		// The first page ends exactly at the end, so no empty page is asked for
+		{"page boundary", 1000, 1},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			s, client := newKlineServer(t, first, 5000)
+			end := first.Add(time.Duration(c.hours)*time.Hour - time.Millisecond)
+
+			klines, err := client.GetKlinesRange("BTCUSDT", "1h", first, end)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if len(klines) != c.hours {
+				t.Fatalf("got %d klines, want %d", len(klines), c.hours)
+			}
+			if last := klines[len(klines)-1]; !last.CloseTime.Equal(end) {
+				t.Errorf("last kline closes at %s, want %s", last.CloseTime.UTC(), end)
+			}
+			if s.requests != c.requests {
+				t.Errorf("made %d requests, want %d", s.requests, c.requests)
+			}
+		})
+	}
+}
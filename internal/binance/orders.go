@@ -0,0 +1,488 @@
+package binance
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+type OrderSide string
+
+const (
+	SideBuy  OrderSide = "BUY"
+	SideSell OrderSide = "SELL"
+)
+
+type OrderType string
+
+const (
+	OrderTypeMarket        OrderType = "MARKET"
+	OrderTypeLimit         OrderType = "LIMIT"
+	OrderTypeStopLossLimit OrderType = "STOP_LOSS_LIMIT"
+	OrderTypeLimitMaker    OrderType = "LIMIT_MAKER"
+)
+
+type TimeInForce string
+
+const (
+	TimeInForceGTC TimeInForce = "GTC"
+	TimeInForceIOC TimeInForce = "IOC"
+	TimeInForceFOK TimeInForce = "FOK"
+)
+
+type OrderStatus string
+
+const (
+	OrderStatusNew             OrderStatus = "NEW"
+	OrderStatusPartiallyFilled OrderStatus = "PARTIALLY_FILLED"
+	OrderStatusFilled          OrderStatus = "FILLED"
+	OrderStatusCanceled        OrderStatus = "CANCELED"
+	OrderStatusPendingCancel   OrderStatus = "PENDING_CANCEL"
+	OrderStatusRejected        OrderStatus = "REJECTED"
+	OrderStatusExpired         OrderStatus = "EXPIRED"
+)
+
+// IsFinal reports whether the order can no longer change
+func (s OrderStatus) IsFinal() bool {
+	switch s {
+	case OrderStatusFilled, OrderStatusCanceled, OrderStatusRejected, OrderStatusExpired:
+		return true
+	default:
+		return false
+	}
+}
+
+// SideEffectType controls auto borrow/repay on margin orders
+type SideEffectType string
+
+const (
+	SideEffectNone        SideEffectType = "NO_SIDE_EFFECT"
+	SideEffectMarginBuy   SideEffectType = "MARGIN_BUY"
+	SideEffectAutoRepay   SideEffectType = "AUTO_REPAY"
+	SideEffectBorrowRepay SideEffectType = "AUTO_BORROW_REPAY"
+)
+
+type OrderRequest struct {
+	Symbol           string
+	Side             OrderSide
+	Type             OrderType
+	TimeInForce      TimeInForce
+	Quantity         string
+	QuoteOrderQty    string
+	Price            string
+	StopPrice        string
+	NewClientOrderID string
+	// Margin only
+	SideEffectType SideEffectType
+	IsIsolated     bool // trade on the isolated margin account of the symbol
+}
+
+func (r OrderRequest) validate() error {
+	if r.Symbol == "" {
+		return fmt.Errorf("%w: symbol is required", ErrInvalidOrder)
+	}
+
+	if r.Side != SideBuy && r.Side != SideSell {
+		return fmt.Errorf("%w: invalid side %q", ErrInvalidOrder, r.Side)
+	}
+
+	switch r.Type {
+	case OrderTypeMarket:
+		if r.Quantity == "" && r.QuoteOrderQty == "" {
+			return fmt.Errorf("%w: MARKET orders need quantity or quote order quantity", ErrInvalidOrder)
+		}
+	case OrderTypeLimit:
+		if r.Quantity == "" || r.Price == "" {
+			return fmt.Errorf("%w: LIMIT orders need quantity and price", ErrInvalidOrder)
+		}
+	case OrderTypeStopLossLimit:
+		if r.Quantity == "" || r.Price == "" || r.StopPrice == "" {
+			return fmt.Errorf("%w: STOP_LOSS_LIMIT orders need quantity, price and stop price", ErrInvalidOrder)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported order type %q", ErrInvalidOrder, r.Type)
+	}
+
+	return nil
+}
+
+func (r OrderRequest) params() url.Values {
+	params := url.Values{}
+	params.Set("symbol", r.Symbol)
+	params.Set("side", string(r.Side))
+	params.Set("type", string(r.Type))
+	params.Set("newOrderRespType", "FULL")
+
+	if r.Type != OrderTypeMarket {
+		timeInForce := r.TimeInForce
+		if timeInForce == "" {
+			timeInForce = TimeInForceGTC
+		}
+		params.Set("timeInForce", string(timeInForce))
+	}
+
+	setIfNotEmpty(params, "quantity", r.Quantity)
+	setIfNotEmpty(params, "quoteOrderQty", r.QuoteOrderQty)
+	setIfNotEmpty(params, "price", r.Price)
+	setIfNotEmpty(params, "stopPrice", r.StopPrice)
+	setIfNotEmpty(params, "newClientOrderId", r.NewClientOrderID)
+	setIfNotEmpty(params, "sideEffectType", string(r.SideEffectType))
+	if r.IsIsolated {
+		params.Set("isIsolated", "TRUE")
+	}
+
+	return params
+}
+
+// OCORequest places a limit maker order with a stop loss limit order,
+// where one fill cancels the other
+type OCORequest struct {
+	Symbol               string
+	Side                 OrderSide
+	Quantity             string
+	Price                string
+	StopPrice            string
+	StopLimitPrice       string
+	StopLimitTimeInForce TimeInForce
+	ListClientOrderID    string
+	// Margin only
+	SideEffectType SideEffectType
+}
+
+func (r OCORequest) validate() error {
+	if r.Symbol == "" {
+		return fmt.Errorf("%w: symbol is required", ErrInvalidOrder)
+	}
+
+	if r.Side != SideBuy && r.Side != SideSell {
+		return fmt.Errorf("%w: invalid side %q", ErrInvalidOrder, r.Side)
+	}
+
+	if r.Quantity == "" || r.Price == "" || r.StopPrice == "" {
+		return fmt.Errorf("%w: OCO orders need quantity, price and stop price", ErrInvalidOrder)
+	}
+
+	return nil
+}
+
+func (r OCORequest) params() url.Values {
+	params := url.Values{}
+	params.Set("symbol", r.Symbol)
+	params.Set("side", string(r.Side))
+	params.Set("quantity", r.Quantity)
+	params.Set("price", r.Price)
+	params.Set("stopPrice", r.StopPrice)
+	params.Set("newOrderRespType", "FULL")
+
+	if r.StopLimitPrice != "" {
+		timeInForce := r.StopLimitTimeInForce
+		if timeInForce == "" {
+			timeInForce = TimeInForceGTC
+		}
+		params.Set("stopLimitPrice", r.StopLimitPrice)
+		params.Set("stopLimitTimeInForce", string(timeInForce))
+	}
+
+	setIfNotEmpty(params, "listClientOrderId", r.ListClientOrderID)
+	setIfNotEmpty(params, "sideEffectType", string(r.SideEffectType))
+
+	return params
+}
+
+type Order struct {
+	Symbol              string      `json:"symbol"`
+	OrderID             int64       `json:"orderId"`
+	OrderListID         int64       `json:"orderListId"`
+	ClientOrderID       string      `json:"clientOrderId"`
+	Price               string      `json:"price"`
+	OrigQty             string      `json:"origQty"`
+	ExecutedQty         string      `json:"executedQty"`
+	CummulativeQuoteQty string      `json:"cummulativeQuoteQty"`
+	Status              OrderStatus `json:"status"`
+	TimeInForce         TimeInForce `json:"timeInForce"`
+	Type                OrderType   `json:"type"`
+	Side                OrderSide   `json:"side"`
+	StopPrice           string      `json:"stopPrice"`
+	Time                int64       `json:"time"`
+	UpdateTime          int64       `json:"updateTime"`
+	TransactTime        int64       `json:"transactTime"`
+	IsIsolated          bool        `json:"isIsolated"`
+	Fills               []Fill      `json:"fills"`
+}
+
+type Fill struct {
+	TradeID         int64  `json:"tradeId"`
+	Price           string `json:"price"`
+	Qty             string `json:"qty"`
+	Commission      string `json:"commission"`
+	CommissionAsset string `json:"commissionAsset"`
+}
+
+type OrderList struct {
+	OrderListID       int64           `json:"orderListId"`
+	ContingencyType   string          `json:"contingencyType"`
+	ListStatusType    string          `json:"listStatusType"`
+	ListOrderStatus   string          `json:"listOrderStatus"`
+	ListClientOrderID string          `json:"listClientOrderId"`
+	TransactionTime   int64           `json:"transactionTime"`
+	Symbol            string          `json:"symbol"`
+	Orders            []OrderListItem `json:"orders"`
+	OrderReports      []Order         `json:"orderReports"`
+}
+
+type OrderListItem struct {
+	Symbol        string `json:"symbol"`
+	OrderID       int64  `json:"orderId"`
+	ClientOrderID string `json:"clientOrderId"`
+}
+
+func (c Client) PlaceOrder(req OrderRequest) (Order, error) {
+	return c.placeOrder("/api/v3/order", req)
+}
+
+func (c Client) PlaceMarginOrder(req OrderRequest) (Order, error) {
+	return c.placeOrder("/sapi/v1/margin/order", req)
+}
+
+func (c Client) placeOrder(path string, req OrderRequest) (Order, error) {
+	if err := req.validate(); err != nil {
+		return Order{}, err
+	}
+
+	var order Order
+	if err := c.signedRequest(http.MethodPost, path, req.params(), &order); err != nil {
+		return Order{}, err
+	}
+
+	return order, nil
+}
+
+func (c Client) PlaceOCO(req OCORequest) (OrderList, error) {
+	return c.placeOCO("/api/v3/order/oco", req)
+}
+
+func (c Client) PlaceMarginOCO(req OCORequest) (OrderList, error) {
+	return c.placeOCO("/sapi/v1/margin/order/oco", req)
+}
+
+func (c Client) placeOCO(path string, req OCORequest) (OrderList, error) {
+	if err := req.validate(); err != nil {
+		return OrderList{}, err
+	}
+
+	var orderList OrderList
+	if err := c.signedRequest(http.MethodPost, path, req.params(), &orderList); err != nil {
+		return OrderList{}, err
+	}
+
+	return orderList, nil
+}
+
+func (c Client) CancelOrder(symbol string, orderID int64) (Order, error) {
+	return c.orderByID(http.MethodDelete, "/api/v3/order", symbol, orderID, false)
+}
+
+func (c Client) CancelMarginOrder(symbol string, orderID int64) (Order, error) {
+	return c.orderByID(http.MethodDelete, "/sapi/v1/margin/order", symbol, orderID, false)
+}
+
+func (c Client) GetOrder(symbol string, orderID int64) (Order, error) {
+	return c.orderByID(http.MethodGet, "/api/v3/order", symbol, orderID, false)
+}
+
+func (c Client) GetMarginOrder(symbol string, orderID int64) (Order, error) {
+	return c.orderByID(http.MethodGet, "/sapi/v1/margin/order", symbol, orderID, false)
+}
+
+// GetOrderByClientID looks an order up by the client order id it was placed
+// with, for orders whose placement never got an answer
+func (c Client) GetOrderByClientID(symbol, clientOrderID string) (Order, error) {
+	return c.orderByClientID("/api/v3/order", symbol, clientOrderID, false)
+}
+
+func (c Client) GetMarginOrderByClientID(symbol, clientOrderID string) (Order, error) {
+	return c.orderByClientID("/sapi/v1/margin/order", symbol, clientOrderID, false)
+}
+
+func (c Client) orderByClientID(path, symbol, clientOrderID string, isolated bool) (Order, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("origClientOrderId", clientOrderID)
+	return c.order(http.MethodGet, path, params, isolated)
+}
+
+func (c Client) orderByID(method, path, symbol string, orderID int64, isolated bool) (Order, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("orderId", strconv.FormatInt(orderID, 10))
+	return c.order(method, path, params, isolated)
+}
+
+func (c Client) order(method, path string, params url.Values, isolated bool) (Order, error) {
+	if isolated {
+		params.Set("isIsolated", "TRUE")
+	}
+
+	var order Order
+	if err := c.signedRequest(method, path, params, &order); err != nil {
+		return Order{}, err
+	}
+
+	return order, nil
+}
+
+// GetOpenOrders returns open orders for symbol, or for all symbols if symbol is empty
+func (c Client) GetOpenOrders(symbol string) ([]Order, error) {
+	return c.openOrders("/api/v3/openOrders", symbol, false)
+}
+
+func (c Client) GetMarginOpenOrders(symbol string) ([]Order, error) {
+	return c.openOrders("/sapi/v1/margin/openOrders", symbol, false)
+}
+
+func (c Client) openOrders(path, symbol string, isolated bool) ([]Order, error) {
+	params := url.Values{}
+	setIfNotEmpty(params, "symbol", symbol)
+	if isolated {
+		params.Set("isIsolated", "TRUE")
+	}
+
+	var orders []Order
+	if err := c.signedRequest(http.MethodGet, path, params, &orders); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+type Trade struct {
+	ID              int64  `json:"id"`
+	OrderID         int64  `json:"orderId"`
+	Symbol          string `json:"symbol"`
+	Price           string `json:"price"`
+	Qty             string `json:"qty"`
+	QuoteQty        string `json:"quoteQty"`
+	Commission      string `json:"commission"`
+	CommissionAsset string `json:"commissionAsset"`
+	Time            int64  `json:"time"`
+	IsBuyer         bool   `json:"isBuyer"`
+	IsMaker         bool   `json:"isMaker"`
+}
+
+// GetOrderTrades returns the trades (fills) of a spot order
+func (c Client) GetOrderTrades(symbol string, orderID int64) ([]Trade, error) {
+	return c.orderTrades("/api/v3/myTrades", symbol, orderID, false)
+}
+
+func (c Client) GetMarginOrderTrades(symbol string, orderID int64) ([]Trade, error) {
+	return c.orderTrades("/sapi/v1/margin/myTrades", symbol, orderID, false)
+}
+
+func (c Client) orderTrades(path, symbol string, orderID int64, isolated bool) ([]Trade, error) {
+	params := url.Values{}
+	params.Set("symbol", symbol)
+	params.Set("orderId", strconv.FormatInt(orderID, 10))
+	if isolated {
+		params.Set("isIsolated", "TRUE")
+	}
+
+	var trades []Trade
+	if err := c.signedRequest(http.MethodGet, path, params, &trades); err != nil {
+		return nil, err
+	}
+
+	return trades, nil
+}
+
+// signedRequest sends a request to the spot API signed with the API secret and
+// decodes the response into out
+func (c Client) signedRequest(method, path string, params url.Values, out any) error {
+	if c.BaseURL == "" {
+		return fmt.Errorf("%s: %w", c.Environment, ErrNoSpotAPI)
+	}
+	return c.signedRequestTo(c.BaseURL, method, path, params, out)
+}
+
+// futuresRequest is a signed request to the USDⓈ-M futures API of the
+// client's environment
+func (c Client) futuresRequest(method, path string, params url.Values, out any) error {
+	baseURL := c.Environment.FuturesURL()
+	if baseURL == "" {
+		return fmt.Errorf("%s: %w", c.Environment, ErrNoFuturesAPI)
+	}
+	return c.signedRequestTo(baseURL, method, path, params, out)
+}
+
+func (c Client) signedRequestTo(baseURL, method, path string, params url.Values, out any) error {
+	if params == nil {
+		params = url.Values{}
+	}
+	params.Set("timestamp", strconv.FormatInt(time.Now().UnixMilli(), 10))
+
+	queryString := params.Encode()
+	signature := c.signRequest(queryString)
+	finalQuery := fmt.Sprintf("%s&signature=%s", queryString, signature)
+	reqURL := fmt.Sprintf("%s%s?%s", baseURL, path, finalQuery)
+
+	req, err := http.NewRequest(method, reqURL, nil)
+	if err != nil {
+		return fmt.Errorf("error making new request %v", err)
+	}
+
+	req.Header.Set("X-MBX-APIKEY", c.ApiKey)
+	resp, err := c.HttpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending the request %v", err)
+	}
+	defer resp.Body.Close()
+
+	err = c.CheckStatus(resp)
+	if err != nil {
+		return err
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("error decoding the response %v", err)
+	}
+
+	return nil
+}
+
+// publicRequest sends an unsigned GET request and decodes the response into out
+func (c Client) publicRequest(path string, params url.Values, out any) error {
+	if c.BaseURL == "" {
+		return fmt.Errorf("%s: %w", c.Environment, ErrNoSpotAPI)
+	}
+	reqURL := fmt.Sprintf("%s%s?%s", c.BaseURL, path, params.Encode())
+
+	req, err := http.NewRequest("GET", reqURL, nil)
+	if err != nil {
+		return fmt.Errorf("error making new request %v", err)
+	}
+
+	resp, err := c.HttpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending the request %v", err)
+	}
+	defer resp.Body.Close()
+
+	err = c.CheckStatus(resp)
+	if err != nil {
+		return err
+	}
+
+	if err = json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("error decoding the response %v", err)
+	}
+
+	return nil
+}
+
+func setIfNotEmpty(params url.Values, key, value string) {
+	if value != "" {
+		params.Set(key, value)
+	}
+}
@@ -0,0 +1,47 @@
+package binance
+
+import (
+	"sync"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// A step that couldn't be read is asked for again after this long
+const lotStepRetry = 10 * time.Minute
+
+type lotStep struct {
+	step     decimal.Decimal
+	failedAt time.Time
+}
+
+var (
+	lotStepsMu sync.Mutex
+	lotSteps   = make(map[string]lotStep)
+)
+
+// LotStep returns the LOT_SIZE step of symbol on Binance, zero when the
+// symbol has none or it can't be read. Steps practically never change, so
+// they are cached for the life of the process.
+func LotStep(symbol string) decimal.Decimal {
+	lotStepsMu.Lock()
+	cached, ok := lotSteps[symbol]
+	lotStepsMu.Unlock()
+	if ok && (cached.failedAt.IsZero() || time.Since(cached.failedAt) < lotStepRetry) {
+		return cached.step
+	}
+
+	cached = lotStep{step: decimal.Zero}
+	info, err := NewPublic("").GetSymbolInfo(symbol)
+	if err != nil {
+		cached.failedAt = time.Now()
+	} else if step, err := decimal.NewFromString(info.StepSize()); err == nil {
+		cached.step = step
+	}
+
+	lotStepsMu.Lock()
+	lotSteps[symbol] = cached
+	lotStepsMu.Unlock()
+
+	return cached.step
+}
@@ -0,0 +1,57 @@
+package binance
+
+import (
+	"errors"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// USDTPrice returns the price of asset in USDT from a GetPrices result.
+// Assets without a USDT market are priced through their BTC market.
+func USDTPrice(prices map[string]string, asset string) (decimal.Decimal, bool) {
+	if asset == "USDT" {
+		return decimal.NewFromInt(1), true
+	}
+
+	if price, err := decimal.NewFromString(prices[asset+"USDT"]); err == nil {
+		return price, true
+	}
+
+	inBtc, err := decimal.NewFromString(prices[asset+"BTC"])
+	if err != nil {
+		return decimal.Zero, false
+	}
+
+	btcPrice, err := decimal.NewFromString(prices["BTCUSDT"])
+	if err != nil {
+		return decimal.Zero, false
+	}
+
+	return inBtc.Mul(btcPrice), true
+}
+
+// USDTPriceAt is USDTPrice at a point in the past. It returns ErrInvalidSymbol
+// when the asset has neither a USDT nor a BTC market.
+func (c Client) USDTPriceAt(asset string, at time.Time) (decimal.Decimal, error) {
+	if asset == "USDT" {
+		return decimal.NewFromInt(1), nil
+	}
+
+	price, err := c.GetPriceAt(asset+"USDT", at)
+	if !errors.Is(err, ErrInvalidSymbol) {
+		return price, err
+	}
+
+	inBtc, err := c.GetPriceAt(asset+"BTC", at)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	btcPrice, err := c.GetPriceAt("BTCUSDT", at)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	return inBtc.Mul(btcPrice), nil
+}
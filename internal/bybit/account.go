@@ -0,0 +1,77 @@
+package bybit
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+)
+
+// Wallet is the unified trading account, one wallet for spot, margin and
+// derivatives. Equity includes the unrealized PnL of open positions.
+type Wallet struct {
+	AccountType        string `json:"accountType"`
+	TotalEquity        string `json:"totalEquity"`
+	TotalWalletBalance string `json:"totalWalletBalance"`
+	TotalMarginBalance string `json:"totalMarginBalance"`
+	AccountIMRate      string `json:"accountIMRate"`
+	AccountMMRate      string `json:"accountMMRate"`
+	Coins              []Coin `json:"coin"`
+}
+
+type Coin struct {
+	Coin            string `json:"coin"`
+	Equity          string `json:"equity"`
+	UsdValue        string `json:"usdValue"`
+	WalletBalance   string `json:"walletBalance"`
+	Locked          string `json:"locked"`
+	BorrowAmount    string `json:"borrowAmount"`
+	AccruedInterest string `json:"accruedInterest"`
+	UnrealisedPnl   string `json:"unrealisedPnl"`
+}
+
+// APIKeyInfo is the key the client signs with and what it may do
+type APIKeyInfo struct {
+	ReadOnly    int                 `json:"readOnly"` // 1 when the key can't trade
+	IPs         []string            `json:"ips"`      // "*" when not IP restricted
+	Permissions map[string][]string `json:"permissions"`
+	Unified     int                 `json:"uta"` // 1 for a unified trading account
+}
+
+// Can reports whether the key has permission in group, such as "Withdraw"
+// in "Wallet"
+func (k APIKeyInfo) Can(group, permission string) bool {
+	return slices.Contains(k.Permissions[group], permission)
+}
+
+// IPRestricted reports whether the key only works from listed IPs
+func (k APIKeyInfo) IPRestricted() bool {
+	return len(k.IPs) > 0 && !slices.Contains(k.IPs, "*")
+}
+
+// GetWallet returns the unified trading account
+func (c Client) GetWallet() (Wallet, error) {
+	params := url.Values{}
+	params.Set("accountType", "UNIFIED")
+
+	var res struct {
+		List []Wallet `json:"list"`
+	}
+	if err := c.get("/v5/account/wallet-balance", params, &res); err != nil {
+		return Wallet{}, err
+	}
+
+	if len(res.List) == 0 {
+		return Wallet{}, fmt.Errorf("no unified account, only unified trading accounts are supported")
+	}
+
+	return res.List[0], nil
+}
+
+func (c Client) GetAPIKeyInfo() (APIKeyInfo, error) {
+	var info APIKeyInfo
+	if err := c.get("/v5/user/query-api", url.Values{}, &info); err != nil {
+		return APIKeyInfo{}, err
+	}
+
+	return info, nil
+}
@@ -0,0 +1,135 @@
+// Package bybit is a client for the Bybit v5 REST API, covering what the
+// exchange adapter reads: the unified wallet, API key, tickers and linear
+// positions.
+package bybit
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"trade/internal/secrets"
+)
+
+// How long a signed request stays valid after its timestamp, in milliseconds
+const recvWindow = "5000"
+
+type Client struct {
+	ApiKey      string
+	ApiSecret   string
+	BaseURL     string
+	Environment Environment
+	HttpClient  *http.Client
+}
+
+// response is the envelope around every v5 result
+type response struct {
+	RetCode int             `json:"retCode"`
+	RetMsg  string          `json:"retMsg"`
+	Result  json.RawMessage `json:"result"`
+}
+
+// New creates a client for the APIs of env
+func New(key, secret string, env Environment) (*Client, error) {
+	if !env.IsValid() {
+		return nil, fmt.Errorf("unknown Bybit environment %q", env)
+	}
+
+	if key == "" || secret == "" {
+		return nil, errors.New("key and secret must be set")
+	}
+
+	return &Client{
+		ApiKey:      key,
+		ApiSecret:   secret,
+		BaseURL:     env.URL(),
+		Environment: env,
+		HttpClient:  &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// NewFromEncrypted creates a client for an account of env from credentials
+// as stored in exchange_accounts
+func NewFromEncrypted(encryptedKey, encryptedSecret string, env Environment) (*Client, error) {
+	key, err := secrets.Decrypt(encryptedKey)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting api key: %w", err)
+	}
+
+	secret, err := secrets.Decrypt(encryptedSecret)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting api secret: %w", err)
+	}
+
+	return New(key, secret, env)
+}
+
+// sign is the v5 signature of a request: the timestamp, key and receive
+// window followed by the query string of a GET or the body of a POST
+func (c Client) sign(timestamp, payload string) string {
+	mac := hmac.New(sha256.New, []byte(c.ApiSecret))
+	mac.Write([]byte(timestamp + c.ApiKey + recvWindow + payload))
+	return fmt.Sprintf("%x", mac.Sum(nil))
+}
+
+// get sends a signed GET request and decodes the result into out
+func (c Client) get(path string, params url.Values, out any) error {
+	queryString := params.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, fmt.Sprintf("%s%s?%s", c.BaseURL, path, queryString), nil)
+	if err != nil {
+		return fmt.Errorf("error making new request %v", err)
+	}
+
+	return c.do(req, queryString, out)
+}
+
+func (c Client) do(req *http.Request, payload string, out any) error {
+	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	req.Header.Set("X-BAPI-API-KEY", c.ApiKey)
+	req.Header.Set("X-BAPI-TIMESTAMP", timestamp)
+	req.Header.Set("X-BAPI-RECV-WINDOW", recvWindow)
+	req.Header.Set("X-BAPI-SIGN", c.sign(timestamp, payload))
+
+	resp, err := c.HttpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending the request %v", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("error reading the response %v", err)
+	}
+
+	var res response
+	if err := json.Unmarshal(body, &res); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return &APIError{StatusCode: resp.StatusCode, Message: string(body)}
+		}
+		return fmt.Errorf("error decoding the response %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK || res.RetCode != 0 {
+		return &APIError{StatusCode: resp.StatusCode, Code: res.RetCode, Message: res.RetMsg}
+	}
+
+	if err := json.Unmarshal(res.Result, out); err != nil {
+		return fmt.Errorf("error decoding the result %v", err)
+	}
+
+	return nil
+}
+
+func setIfNotEmpty(params url.Values, key, value string) {
+	if value != "" {
+		params.Set(key, value)
+	}
+}
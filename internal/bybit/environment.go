@@ -0,0 +1,35 @@
+package bybit
+
+// Environment is the Bybit deployment an account lives on. Like Binance's,
+// each resolves to a fixed API host.
+type Environment string
+
+const (
+	Mainnet Environment = "mainnet"
+	Testnet Environment = "testnet"
+)
+
+var environments = map[Environment]string{
+	Mainnet: "https://api.bybit.com",
+	Testnet: "https://api-testnet.bybit.com",
+}
+
+// Environments lists the environments an account can be on, mainnet first
+func Environments() []Environment {
+	return []Environment{Mainnet, Testnet}
+}
+
+func (e Environment) IsValid() bool {
+	_, ok := environments[e]
+	return ok
+}
+
+// IsTestnet reports whether the environment trades with test funds
+func (e Environment) IsTestnet() bool {
+	return e == Testnet
+}
+
+// URL is the base URL of the v5 API
+func (e Environment) URL() string {
+	return environments[e]
+}
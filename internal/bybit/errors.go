@@ -0,0 +1,47 @@
+package bybit
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var (
+	ErrUnauthorized     = errors.New("unauthorized - check your API key")
+	ErrForbidden        = errors.New("forbidden - check your API permissions")
+	ErrRateLimited      = errors.New("rate limit exceeded")
+	ErrInvalidSignature = errors.New("invalid request signature")
+	ErrTimestamp        = errors.New("request timestamp outside recv_window")
+	ErrInvalidSymbol    = errors.New("invalid symbol")
+)
+
+// APIError is an error response from the Bybit API. Bybit answers most errors
+// with HTTP 200 and a non-zero retCode. It matches the sentinel errors above
+// with errors.Is.
+type APIError struct {
+	StatusCode int    `json:"-"`
+	Code       int    `json:"retCode"`
+	Message    string `json:"retMsg"`
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error %d (code %d): %s", e.StatusCode, e.Code, e.Message)
+}
+
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.Code == 10003 || e.Code == 33004
+	case ErrForbidden:
+		return e.Code == 10005 || e.Code == 10010
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusForbidden || e.Code == 10006 || e.Code == 10018
+	case ErrInvalidSignature:
+		return e.Code == 10004
+	case ErrTimestamp:
+		return e.Code == 10002
+	case ErrInvalidSymbol:
+		return e.Code == 170121 || e.Code == 110023
+	}
+	return false
+}
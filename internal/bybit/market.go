@@ -0,0 +1,36 @@
+package bybit
+
+import (
+	"net/url"
+)
+
+// Category is the product line a request is about
+type Category string
+
+const (
+	CategorySpot   Category = "spot"
+	CategoryLinear Category = "linear" // USDT and USDC perpetuals and futures
+)
+
+type Ticker struct {
+	Symbol    string `json:"symbol"`
+	LastPrice string `json:"lastPrice"`
+	Bid1Price string `json:"bid1Price"`
+	Ask1Price string `json:"ask1Price"`
+}
+
+// GetTickers returns the tickers of category, or only symbol's if not empty
+func (c Client) GetTickers(category Category, symbol string) ([]Ticker, error) {
+	params := url.Values{}
+	params.Set("category", string(category))
+	setIfNotEmpty(params, "symbol", symbol)
+
+	var res struct {
+		List []Ticker `json:"list"`
+	}
+	if err := c.get("/v5/market/tickers", params, &res); err != nil {
+		return nil, err
+	}
+
+	return res.List, nil
+}
@@ -0,0 +1,44 @@
+package bybit
+
+import (
+	"net/url"
+)
+
+type Side string
+
+const (
+	SideBuy  Side = "Buy"
+	SideSell Side = "Sell"
+)
+
+// Position is a derivatives position. Side is empty when the position is flat.
+type Position struct {
+	Symbol        string `json:"symbol"`
+	Side          Side   `json:"side"`
+	Size          string `json:"size"`
+	AvgPrice      string `json:"avgPrice"`
+	MarkPrice     string `json:"markPrice"`
+	UnrealisedPnl string `json:"unrealisedPnl"`
+	Leverage      string `json:"leverage"`
+	LiqPrice      string `json:"liqPrice"`
+	PositionValue string `json:"positionValue"`
+	UpdatedTime   string `json:"updatedTime"`
+}
+
+// GetPositions returns the positions settled in settleCoin, such as USDT for
+// the USDT perpetuals. Bybit lists flat positions too.
+func (c Client) GetPositions(category Category, settleCoin string) ([]Position, error) {
+	params := url.Values{}
+	params.Set("category", string(category))
+	params.Set("settleCoin", settleCoin)
+	params.Set("limit", "200")
+
+	var res struct {
+		List []Position `json:"list"`
+	}
+	if err := c.get("/v5/position/list", params, &res); err != nil {
+		return nil, err
+	}
+
+	return res.List, nil
+}
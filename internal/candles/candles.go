@@ -0,0 +1,145 @@
+package candles
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"trade/internal/binance"
+	"trade/internal/database"
+	db "trade/internal/db/sqlc"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Store keeps closed klines downloaded from Binance in the candles table.
+// Writes are upserts, so syncing a range twice is harmless.
+type Store struct {
+	db *database.Database
+}
+
+func New(db *database.Database) *Store {
+	return &Store{db: db}
+}
+
+// Sync downloads the closed klines of symbol that are newer than the latest
+// stored one. A series that isn't stored yet is backfilled from from. It
+// returns how many candles were written.
+func (s *Store) Sync(ctx context.Context, client *binance.Client, symbol, interval string, from time.Time) (int, error) {
+	step, ok := binance.IntervalDuration(interval)
+	if !ok {
+		return 0, fmt.Errorf("unknown kline interval %q", interval)
+	}
+
+	latest, err := s.db.Queries.GetLatestCandleOpenTime(ctx, db.GetLatestCandleOpenTimeParams{
+		Symbol:    symbol,
+		Timeframe: interval,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("error getting latest %s %s candle: %w", symbol, interval, err)
+	}
+
+	start := from
+	if latest.Valid && !latest.Time.Before(from) {
+		start = latest.Time.Add(step)
+	}
+
+	now := time.Now()
+	written := 0
+	err = client.PageKlines(symbol, interval, start, time.Time{}, func(klines []binance.Kline) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		closed := klines[:0]
+		for _, k := range klines {
+			if k.CloseTime.Before(now) {
+				closed = append(closed, k)
+			}
+		}
+
+		if err := s.Upsert(ctx, symbol, interval, closed); err != nil {
+			return err
+		}
+		written += len(closed)
+		return nil
+	})
+	if err != nil {
+		return written, fmt.Errorf("error syncing %s %s: %w", symbol, interval, err)
+	}
+
+	return written, nil
+}
+
+// Upsert stores klines of symbol in one transaction, replacing stored ones
+// with the same open time
+func (s *Store) Upsert(ctx context.Context, symbol, interval string, klines []binance.Kline) error {
+	if len(klines) == 0 {
+		return nil
+	}
+
+	tx, err := s.db.DBPool.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := s.db.Queries.WithTx(tx)
+	for _, k := range klines {
+		err := qtx.UpsertCandle(ctx, db.UpsertCandleParams{
+			Symbol:    symbol,
+			Timeframe: interval,
+			OpenTime:  pgtype.Timestamptz{Time: k.OpenTime, Valid: true},
+			CloseTime: pgtype.Timestamptz{Time: k.CloseTime, Valid: true},
+			Open:      database.ParseNumeric(k.Open),
+			High:      database.ParseNumeric(k.High),
+			Low:       database.ParseNumeric(k.Low),
+			Close:     database.ParseNumeric(k.Close),
+			Volume:    database.ParseNumeric(k.Volume),
+			Trades:    k.Trades,
+		})
+		if err != nil {
+			return fmt.Errorf("error storing %s %s candle at %s: %w", symbol, interval, k.OpenTime.Format(time.RFC3339), err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return fmt.Errorf("error committing candles: %w", err)
+	}
+
+	return nil
+}
+
+// Load returns the stored klines of symbol with an open time from start until
+// end. A zero end loads up to the latest candle.
+func (s *Store) Load(ctx context.Context, symbol, interval string, start, end time.Time) ([]binance.Kline, error) {
+	if end.IsZero() {
+		end = time.Now()
+	}
+
+	rows, err := s.db.Queries.GetCandles(ctx, db.GetCandlesParams{
+		Symbol:    symbol,
+		Timeframe: interval,
+		StartTime: pgtype.Timestamptz{Time: start, Valid: true},
+		EndTime:   pgtype.Timestamptz{Time: end, Valid: true},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error getting %s %s candles: %w", symbol, interval, err)
+	}
+
+	klines := make([]binance.Kline, 0, len(rows))
+	for _, row := range rows {
+		klines = append(klines, binance.Kline{
+			OpenTime:  row.OpenTime.Time,
+			CloseTime: row.CloseTime.Time,
+			Open:      database.Decimal(row.Open).String(),
+			High:      database.Decimal(row.High).String(),
+			Low:       database.Decimal(row.Low).String(),
+			Close:     database.Decimal(row.Close).String(),
+			Volume:    database.Decimal(row.Volume).String(),
+			Trades:    row.Trades,
+		})
+	}
+
+	return klines, nil
+}
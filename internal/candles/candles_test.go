@@ -0,0 +1,174 @@
+package candles
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"trade/internal/binance"
+	"trade/internal/database"
+
+	"github.com/shopspring/decimal"
+)
+
+// The tests write to the candles table of DATABASE_URL under their own symbol
+const testSymbol = "TESTCANDLEUSDT"
+
+// klineServer serves hourly klines from first until last the way
+// /api/v3/klines does. last can be moved to let new klines appear.
+type klineServer struct {
+	mu          sync.Mutex
+	first, last time.Time
+	close       string
+}
+
+func (s *klineServer) setLast(last time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.last = last
+}
+
+func (s *klineServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := r.URL.Query()
+	limit, _ := strconv.Atoi(query.Get("limit"))
+	start := s.first
+	if v := query.Get("startTime"); v != "" {
+		ms, _ := strconv.ParseInt(v, 10, 64)
+		start = time.UnixMilli(ms)
+	}
+
+	klines := [][]any{}
+	for open := s.first; !open.After(s.last) && len(klines) < limit; open = open.Add(time.Hour) {
+		if open.Before(start) {
+			continue
+		}
+		closeTime := open.Add(time.Hour - time.Millisecond).UnixMilli()
+		klines = append(klines, []any{open.UnixMilli(), "1", "2", "0.5", s.close, "10", closeTime, "15", 3, "5", "7", "0"})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(klines)
+}
+
+func newStore(t *testing.T) *Store {
+	if os.Getenv("DATABASE_URL") == "" {
+		t.Skip("DATABASE_URL is not set")
+	}
+
+	d, err := database.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clean := func() {
+		if _, err := d.DBPool.Exec(context.Background(), "DELETE FROM candles WHERE symbol = $1", testSymbol); err != nil {
+			t.Fatal(err)
+		}
+	}
+	clean()
+	t.Cleanup(func() {
+		clean()
+		d.DBPool.Close()
+	})
+
+	return New(d)
+}
+
+func TestSyncIsIncremental(t *testing.T) {
+	store := newStore(t)
+	ctx := context.Background()
+
+	// The last kline is still open, so it mustn't be stored
+	current := time.Now().Truncate(time.Hour)
+	first := current.Add(-30 * time.Hour)
+	s := &klineServer{first: first, last: first.Add(19 * time.Hour), close: "1.5"}
+	server := httptest.NewServer(s)
+	defer server.Close()
+	client := binance.NewPublic(server.URL)
+
+	written, err := store.Sync(ctx, client, testSymbol, "1h", first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written != 20 {
+		t.Errorf("first sync wrote %d candles, want 20", written)
+	}
+
+	s.setLast(current)
+	written, err = store.Sync(ctx, client, testSymbol, "1h", first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written != 10 {
+		t.Errorf("second sync wrote %d candles, want only the 10 new ones", written)
+	}
+
+	written, err = store.Sync(ctx, client, testSymbol, "1h", first)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if written != 0 {
+		t.Errorf("sync without new candles wrote %d, want 0", written)
+	}
+
+	klines, err := store.Load(ctx, testSymbol, "1h", first, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(klines) != 30 {
+		t.Fatalf("loaded %d candles, want 30", len(klines))
+	}
+	for i, k := range klines {
+		if want := first.Add(time.Duration(i) * time.Hour); !k.OpenTime.Equal(want) {
+			t.Fatalf("candle %d opens at %s, want %s", i, k.OpenTime, want)
+		}
+	}
+}
+
+func TestUpsertReplacesCandles(t *testing.T) {
+	store := newStore(t)
+	ctx := context.Background()
+
+	first := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	s := &klineServer{first: first, last: first.Add(9 * time.Hour), close: "1.5"}
+	server := httptest.NewServer(s)
+	defer server.Close()
+	client := binance.NewPublic(server.URL)
+
+	klines, err := client.GetKlinesRange(testSymbol, "1h", first, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := store.Upsert(ctx, testSymbol, "1h", klines); err != nil {
+		t.Fatal(err)
+	}
+	for i := range klines {
+		klines[i].Close = "1.75"
+	}
+	if err := store.Upsert(ctx, testSymbol, "1h", klines); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := store.Load(ctx, testSymbol, "1h", first, time.Time{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 10 {
+		t.Fatalf("loaded %d candles after storing 10 twice, want 10", len(stored))
+	}
+	for _, k := range stored {
+		if !decimal.RequireFromString(k.Close).Equal(decimal.RequireFromString("1.75")) {
+			t.Errorf("candle at %s closes at %s, want the replaced 1.75", k.OpenTime, k.Close)
+		}
+	}
+}
@@ -0,0 +1,421 @@
+package cashflows
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"trade/internal/binance"
+	"trade/internal/database"
+	db "trade/internal/db/sqlc"
+	"trade/internal/exchange"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+)
+
+// Wallets a cash flow moves funds in or out of. Balance history records the
+// net value of the margin and futures wallets, see Tracked. ISOLATED holds
+// the wallets of all isolated pairs, moves between them aren't recorded.
+const (
+	WalletSpot     = "SPOT"
+	WalletMargin   = "MARGIN"
+	WalletIsolated = "ISOLATED"
+	WalletFutures  = "FUTURES"
+)
+
+// Tracked reports whether flows of wallet change the net value the balance
+// history records for a Binance account. That is the cross and isolated
+// margin wallets and, when the account's key may trade futures, the futures
+// wallet.
+func Tracked(wallet string, futuresEnabled bool) bool {
+	switch wallet {
+	case WalletMargin, WalletIsolated:
+		return true
+	case WalletFutures:
+		return futuresEnabled
+	}
+	return false
+}
+
+// transferWallets are the transfer types that move funds into or out of a
+// tracked wallet, with the wallets they move funds from and to
+var transferWallets = []struct {
+	transferType string
+	from, to     string
+}{
+	{binance.TransferMainToMargin, WalletSpot, WalletMargin},
+	{binance.TransferMarginToMain, WalletMargin, WalletSpot},
+	{binance.TransferMainToFutures, WalletSpot, WalletFutures},
+	{binance.TransferFuturesToMain, WalletFutures, WalletSpot},
+	{binance.TransferMarginToFutures, WalletMargin, WalletFutures},
+	{binance.TransferFuturesToMargin, WalletFutures, WalletMargin},
+}
+
+const (
+	SourceDeposit    = "DEPOSIT"
+	SourceWithdrawal = "WITHDRAWAL"
+	SourceTransfer   = "TRANSFER"
+)
+
+// Deposits can stay pending for a while, so every sync looks this far back
+// again. Inserts are idempotent.
+const pendingLookback = 3 * 24 * time.Hour
+
+// Syncer pulls deposits, withdrawals and the transfers between wallets of
+// every active account into cash_flows
+type Syncer struct {
+	db *database.Database
+}
+
+func New(db *database.Database) *Syncer {
+	return &Syncer{db: db}
+}
+
+// Run syncs right away and then every hour until ctx is cancelled
+func (s *Syncer) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Sync(ctx); err != nil {
+			fmt.Printf("failed to sync cash flows: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sync pulls the history of every active account since its last sync. A
+// failing account is reported in the returned error and retried next run.
+func (s *Syncer) Sync(ctx context.Context) error {
+	accounts, err := s.db.Queries.GetActiveBinanceAccounts(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting accounts: %w", err)
+	}
+
+	var errs []error
+	for _, acc := range accounts {
+		// Only Binance history is read so far, so returns leave other
+		// exchanges out
+		if acc.Exchange != string(exchange.Binance) {
+			continue
+		}
+
+		// Testnets have no deposits or withdrawals, and their funds aren't
+		// part of the user's returns
+		if binance.Environment(acc.Environment).IsTestnet() {
+			continue
+		}
+
+		if err := s.syncAccount(ctx, acc); err != nil {
+			errs = append(errs, fmt.Errorf("account %d: %w", acc.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// syncAccount walks from the last sync to now one history window at a time,
+// recording progress after each window so a failure doesn't start over
+func (s *Syncer) syncAccount(ctx context.Context, acc db.GetActiveBinanceAccountsRow) error {
+	client, err := binance.NewFromEncrypted(acc.ApiKey, acc.ApiSecret, binance.Environment(acc.Environment))
+	if err != nil {
+		return err
+	}
+
+	syncedUntil, err := s.db.Queries.GetCashFlowSyncedUntil(ctx, acc.ID)
+	if err != nil {
+		return fmt.Errorf("error getting last sync: %w", err)
+	}
+
+	// The transfers of isolated pairs can only be read pair by pair. Pairs
+	// that were deleted since are no longer listed.
+	var isolatedSymbols []string
+	isolated, err := client.GetIsolatedMarginAccount()
+	if err != nil && !errors.Is(err, binance.ErrNoMarginAccount) {
+		return fmt.Errorf("error getting isolated margin pairs: %w", err)
+	}
+	for _, pair := range isolated.Assets {
+		isolatedSymbols = append(isolatedSymbols, pair.Symbol)
+	}
+
+	now := time.Now()
+	start := syncedUntil.Time.Add(-pendingLookback)
+
+	for start.Before(now) {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		end := start.Add(binance.HistoryWindow)
+		if end.After(now) {
+			end = now
+		}
+
+		flows, err := fetch(client, isolatedSymbols, start, end)
+		if err != nil {
+			return err
+		}
+
+		for _, flow := range flows {
+			if err := s.create(ctx, client, acc.ID, flow); err != nil {
+				return err
+			}
+		}
+
+		err = s.db.Queries.UpsertCashFlowSync(ctx, db.UpsertCashFlowSyncParams{
+			BinanceAccountID: acc.ID,
+			SyncedUntil:      pgtype.Timestamptz{Time: end, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("error saving sync progress: %w", err)
+		}
+
+		start = end
+	}
+
+	return nil
+}
+
+// flow is a deposit, withdrawal or transfer before it is valued
+type flow struct {
+	source     string
+	wallet     string
+	externalID string
+	asset      string
+	amount     decimal.Decimal // signed, positive into the wallet
+	fee        decimal.Decimal
+	occurredAt time.Time
+}
+
+// fetch returns the completed flows between start and end. Transfers of
+// isolated pairs are read for isolatedSymbols.
+func fetch(client *binance.Client, isolatedSymbols []string, start, end time.Time) ([]flow, error) {
+	var flows []flow
+
+	deposits, err := client.GetDeposits(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("error getting deposits: %w", err)
+	}
+
+	for _, d := range deposits {
+		if d.Status != binance.DepositStatusSuccess && d.Status != binance.DepositStatusCredited {
+			continue
+		}
+
+		amount, err := decimal.NewFromString(d.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deposit amount %q: %w", d.Amount, err)
+		}
+
+		id := d.ID
+		if id == "" {
+			id = d.TxID
+		}
+
+		flows = append(flows, flow{
+			source:     SourceDeposit,
+			wallet:     WalletSpot,
+			externalID: id,
+			asset:      d.Coin,
+			amount:     amount,
+			occurredAt: time.UnixMilli(d.InsertTime),
+		})
+	}
+
+	withdrawals, err := client.GetWithdrawals(start, end)
+	if err != nil {
+		return nil, fmt.Errorf("error getting withdrawals: %w", err)
+	}
+
+	for _, w := range withdrawals {
+		if w.Status != binance.WithdrawalStatusCompleted {
+			continue
+		}
+
+		amount, err := decimal.NewFromString(w.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("invalid withdrawal amount %q: %w", w.Amount, err)
+		}
+
+		fee, _ := decimal.NewFromString(w.TransactionFee)
+
+		occurredAt, err := w.Time()
+		if err != nil {
+			return nil, fmt.Errorf("invalid withdrawal time %q: %w", w.ApplyTime, err)
+		}
+
+		flows = append(flows, flow{
+			source:     SourceWithdrawal,
+			wallet:     WalletSpot,
+			externalID: w.ID,
+			asset:      w.Coin,
+			amount:     amount.Neg(),
+			fee:        fee,
+			occurredAt: occurredAt,
+		})
+	}
+
+	// Transfers are recorded from the side of each wallet other than spot,
+	// so a transfer between two tracked wallets is a flow out of one and
+	// into the other
+	for _, tt := range transferWallets {
+		transfers, err := client.GetTransfers(tt.transferType, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("error getting %s transfers: %w", tt.transferType, err)
+		}
+
+		for _, t := range transfers {
+			if t.Status != "CONFIRMED" {
+				continue
+			}
+
+			amount, err := decimal.NewFromString(t.Amount)
+			if err != nil {
+				return nil, fmt.Errorf("invalid transfer amount %q: %w", t.Amount, err)
+			}
+
+			transfer := flow{
+				source:     SourceTransfer,
+				externalID: strconv.FormatInt(t.TranID, 10),
+				asset:      t.Asset,
+				occurredAt: time.UnixMilli(t.Timestamp),
+			}
+			if tt.from != WalletSpot {
+				transfer.wallet, transfer.amount = tt.from, amount.Neg()
+				flows = append(flows, transfer)
+			}
+			if tt.to != WalletSpot {
+				transfer.wallet, transfer.amount = tt.to, amount
+				flows = append(flows, transfer)
+			}
+		}
+	}
+
+	for _, symbol := range isolatedSymbols {
+		isolated, err := fetchIsolated(client, symbol, start, end)
+		if err != nil {
+			return nil, err
+		}
+		flows = append(flows, isolated...)
+	}
+
+	return flows, nil
+}
+
+// fetchIsolated returns the confirmed transfers between the wallet of the
+// isolated pair symbol and the spot or cross margin wallet
+func fetchIsolated(client *binance.Client, symbol string, start, end time.Time) ([]flow, error) {
+	var flows []flow
+
+	for _, transferType := range []string{binance.TransferIsolatedToMargin, binance.TransferMarginToIsolated} {
+		transfers, err := client.GetIsolatedTransfers(transferType, symbol, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("error getting %s %s transfers: %w", symbol, transferType, err)
+		}
+
+		for _, t := range transfers {
+			if t.Status != "CONFIRMED" {
+				continue
+			}
+
+			amount, err := decimal.NewFromString(t.Amount)
+			if err != nil {
+				return nil, fmt.Errorf("invalid transfer amount %q: %w", t.Amount, err)
+			}
+
+			transfer := flow{
+				source:     SourceTransfer,
+				externalID: strconv.FormatInt(t.TranID, 10),
+				asset:      t.Asset,
+				occurredAt: time.UnixMilli(t.Timestamp),
+			}
+			if transferType == binance.TransferIsolatedToMargin {
+				amount = amount.Neg()
+			}
+			transfer.wallet, transfer.amount = WalletIsolated, amount
+			flows = append(flows, transfer)
+			transfer.wallet, transfer.amount = WalletMargin, amount.Neg()
+			flows = append(flows, transfer)
+		}
+	}
+
+	// The margin transfer history has the moves between spot and the pair.
+	// Moves from and to cross margin are in the history above.
+	transfers, err := client.GetIsolatedMarginTransfers(symbol, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("error getting %s isolated margin transfers: %w", symbol, err)
+	}
+
+	for _, t := range transfers {
+		if t.Status != "CONFIRMED" {
+			continue
+		}
+
+		amount, err := decimal.NewFromString(t.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("invalid transfer amount %q: %w", t.Amount, err)
+		}
+
+		switch {
+		case t.TransFrom == binance.IsolatedTransferSpot && t.TransTo == binance.IsolatedTransferIsolated:
+		case t.TransFrom == binance.IsolatedTransferIsolated && t.TransTo == binance.IsolatedTransferSpot:
+			amount = amount.Neg()
+		default:
+			continue
+		}
+
+		flows = append(flows, flow{
+			source:     SourceTransfer,
+			wallet:     WalletIsolated,
+			externalID: strconv.FormatInt(t.TxID, 10),
+			asset:      t.Asset,
+			amount:     amount,
+			occurredAt: time.UnixMilli(t.Timestamp),
+		})
+	}
+
+	return flows, nil
+}
+
+// create values the flow at the time it happened and stores it. Assets
+// without a market are stored without a value.
+func (s *Syncer) create(ctx context.Context, client *binance.Client, accountID int32, f flow) error {
+	params := db.CreateCashFlowParams{
+		BinanceAccountID: accountID,
+		Source:           f.source,
+		Wallet:           f.wallet,
+		ExternalID:       f.externalID,
+		Asset:            f.asset,
+		Amount:           database.Numeric(f.amount),
+		Fee:              database.Numeric(f.fee),
+		OccurredAt:       pgtype.Timestamptz{Time: f.occurredAt, Valid: true},
+	}
+
+	price, err := client.USDTPriceAt(f.asset, f.occurredAt)
+	switch {
+	case err == nil:
+		// The fee leaves the wallet as well
+		total := f.amount
+		if f.amount.IsNegative() {
+			total = total.Sub(f.fee)
+		}
+		params.PriceUsdt = database.Numeric(price)
+		params.AmountUsdt = database.Numeric(total.Mul(price))
+	case !errors.Is(err, binance.ErrInvalidSymbol):
+		return fmt.Errorf("error pricing %s: %w", f.asset, err)
+	}
+
+	if err := s.db.Queries.CreateCashFlow(ctx, params); err != nil {
+		return fmt.Errorf("error creating %s %s: %w", f.source, f.externalID, err)
+	}
+
+	return nil
+}
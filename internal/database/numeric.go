@@ -0,0 +1,31 @@
+package database
+
+import (
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+)
+
+// Decimal converts a NUMERIC column, NULL reads as zero
+func Decimal(n pgtype.Numeric) decimal.Decimal {
+	if !n.Valid || n.Int == nil {
+		return decimal.Zero
+	}
+	return decimal.NewFromBigInt(n.Int, n.Exp)
+}
+
+// Numeric converts d for a NUMERIC column
+func Numeric(d decimal.Decimal) pgtype.Numeric {
+	var n pgtype.Numeric
+	n.Scan(d.String())
+	return n
+}
+
+// ParseNumeric converts a number as the exchanges send it for a NUMERIC
+// column, where an empty or malformed string is stored as zero
+func ParseNumeric(s string) pgtype.Numeric {
+	var n pgtype.Numeric
+	if s == "" || n.Scan(s) != nil {
+		n.Scan("0")
+	}
+	return n
+}
@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Existing bots get no secret and reject webhooks until the secret is rotated
+ALTER TABLE bots ADD COLUMN webhook_secret VARCHAR(64);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE bots DROP COLUMN webhook_secret;
+-- +goose StatementEnd
@@ -0,0 +1,86 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE orders (
+    id SERIAL PRIMARY KEY,
+    bot_id INTEGER REFERENCES bots(id) ON DELETE SET NULL,
+    binance_account_id INTEGER NOT NULL REFERENCES binance_accounts(id) ON DELETE CASCADE,
+    exchange_order_id BIGINT,
+    client_order_id VARCHAR(64) NOT NULL,
+    symbol VARCHAR(32) NOT NULL,
+    market VARCHAR(16) NOT NULL DEFAULT 'SPOT',
+    side VARCHAR(4) NOT NULL,
+    order_type VARCHAR(32) NOT NULL,
+    status VARCHAR(32) NOT NULL DEFAULT 'NEW',
+    price DECIMAL(28,10) NOT NULL DEFAULT 0,
+    stop_price DECIMAL(28,10) NOT NULL DEFAULT 0,
+    orig_qty DECIMAL(28,10) NOT NULL DEFAULT 0,
+    executed_qty DECIMAL(28,10) NOT NULL DEFAULT 0,
+    cumulative_quote_qty DECIMAL(28,10) NOT NULL DEFAULT 0,
+    error_message TEXT,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    CONSTRAINT check_order_side CHECK (side IN ('BUY', 'SELL')),
+    CONSTRAINT check_order_market CHECK (market IN ('SPOT', 'MARGIN')),
+    CONSTRAINT unique_account_client_order UNIQUE(binance_account_id, client_order_id)
+);
+
+CREATE INDEX idx_orders_bot_id ON orders(bot_id, created_at DESC);
+CREATE INDEX idx_orders_open ON orders(binance_account_id) WHERE status IN ('NEW', 'PARTIALLY_FILLED', 'PENDING_CANCEL');
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE order_status_history (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    status VARCHAR(32) NOT NULL,
+    executed_qty DECIMAL(28,10) NOT NULL DEFAULT 0,
+    recorded_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+
+CREATE INDEX idx_order_status_history_order_id ON order_status_history(order_id, recorded_at);
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE FUNCTION record_order_status() RETURNS TRIGGER AS $$
+BEGIN
+    IF TG_OP = 'INSERT' OR NEW.status IS DISTINCT FROM OLD.status THEN
+        INSERT INTO order_status_history (order_id, status, executed_qty)
+        VALUES (NEW.id, NEW.status, NEW.executed_qty);
+    END IF;
+    RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TRIGGER orders_status_history
+AFTER INSERT OR UPDATE OF status ON orders
+FOR EACH ROW EXECUTE FUNCTION record_order_status();
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE fills (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    trade_id BIGINT NOT NULL,
+    price DECIMAL(28,10) NOT NULL,
+    quantity DECIMAL(28,10) NOT NULL,
+    commission DECIMAL(28,10) NOT NULL DEFAULT 0,
+    commission_asset VARCHAR(16) NOT NULL DEFAULT '',
+    filled_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    CONSTRAINT unique_order_trade UNIQUE(order_id, trade_id)
+);
+
+CREATE INDEX idx_fills_order_id ON fills(order_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS fills;
+DROP TRIGGER IF EXISTS orders_status_history ON orders;
+DROP FUNCTION IF EXISTS record_order_status();
+DROP TABLE IF EXISTS order_status_history;
+DROP TABLE IF EXISTS orders;
+-- +goose StatementEnd
@@ -0,0 +1,18 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Encrypted credentials don't fit in VARCHAR(255). Existing rows stay
+-- plaintext until cmd/rotate-secrets encrypts them.
+ALTER TABLE binance_accounts
+    ALTER COLUMN api_key TYPE TEXT,
+    ALTER COLUMN api_secret TYPE TEXT,
+    ADD COLUMN api_key_masked VARCHAR(32) NOT NULL DEFAULT '****';
+
+UPDATE binance_accounts
+SET api_key_masked = LEFT(api_key, 4) || '****' || RIGHT(api_key, 4)
+WHERE LENGTH(api_key) > 8;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE binance_accounts DROP COLUMN api_key_masked;
+-- +goose StatementEnd
@@ -0,0 +1,27 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE balance_asset_snapshots (
+    id BIGSERIAL PRIMARY KEY,
+    balance_history_id INTEGER NOT NULL REFERENCES balance_history(id) ON DELETE CASCADE,
+    binance_account_id INTEGER NOT NULL REFERENCES binance_accounts(id) ON DELETE CASCADE,
+    market VARCHAR(10) NOT NULL CHECK (market IN ('SPOT', 'MARGIN')),
+    asset VARCHAR(20) NOT NULL,
+    free DECIMAL(28,10) NOT NULL DEFAULT 0,
+    locked DECIMAL(28,10) NOT NULL DEFAULT 0,
+    borrowed DECIMAL(28,10) NOT NULL DEFAULT 0,
+    interest DECIMAL(28,10) NOT NULL DEFAULT 0,
+    net_asset DECIMAL(28,10) NOT NULL DEFAULT 0,
+    price_usdt DECIMAL(28,10), -- price used for valuation, NULL when the asset has no market to price it
+    value_usdt DECIMAL(28,10),
+    recorded_at TIMESTAMP WITH TIME ZONE NOT NULL,
+
+    UNIQUE(balance_history_id, market, asset)
+);
+
+CREATE INDEX idx_balance_asset_snapshots_account_time ON balance_asset_snapshots(binance_account_id, recorded_at DESC);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE balance_asset_snapshots;
+-- +goose StatementEnd
@@ -0,0 +1,65 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Minute snapshots rolled up per hour and per day, so the raw rows can be
+-- pruned. Each bucket keeps the first, highest, lowest and last balance.
+CREATE TABLE balance_history_hourly (
+    binance_account_id INTEGER NOT NULL REFERENCES binance_accounts(id) ON DELETE CASCADE,
+    bucket TIMESTAMP WITH TIME ZONE NOT NULL,
+    open_usd DECIMAL(15,2) NOT NULL,
+    high_usd DECIMAL(15,2) NOT NULL,
+    low_usd DECIMAL(15,2) NOT NULL,
+    close_usd DECIMAL(15,2) NOT NULL,
+    samples INTEGER NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    PRIMARY KEY (binance_account_id, bucket)
+);
+
+CREATE TABLE balance_history_daily (
+    binance_account_id INTEGER NOT NULL REFERENCES binance_accounts(id) ON DELETE CASCADE,
+    bucket TIMESTAMP WITH TIME ZONE NOT NULL,
+    open_usd DECIMAL(15,2) NOT NULL,
+    high_usd DECIMAL(15,2) NOT NULL,
+    low_usd DECIMAL(15,2) NOT NULL,
+    close_usd DECIMAL(15,2) NOT NULL,
+    samples INTEGER NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    PRIMARY KEY (binance_account_id, bucket)
+);
+
+CREATE INDEX idx_balance_history_hourly_bucket ON balance_history_hourly(bucket);
+CREATE INDEX idx_balance_history_daily_bucket ON balance_history_daily(bucket);
+
+-- Backfill from the existing snapshots. Buckets are UTC hours and days
+-- whatever the server's time zone.
+INSERT INTO balance_history_hourly (binance_account_id, bucket, open_usd, high_usd, low_usd, close_usd, samples)
+SELECT
+    binance_account_id,
+    DATE_TRUNC('hour', recorded_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC',
+    (ARRAY_AGG(total_balance_usd ORDER BY recorded_at ASC))[1],
+    MAX(total_balance_usd),
+    MIN(total_balance_usd),
+    (ARRAY_AGG(total_balance_usd ORDER BY recorded_at DESC))[1],
+    COUNT(*)
+FROM balance_history
+GROUP BY binance_account_id, DATE_TRUNC('hour', recorded_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC';
+
+INSERT INTO balance_history_daily (binance_account_id, bucket, open_usd, high_usd, low_usd, close_usd, samples)
+SELECT
+    binance_account_id,
+    DATE_TRUNC('day', bucket AT TIME ZONE 'UTC') AT TIME ZONE 'UTC',
+    (ARRAY_AGG(open_usd ORDER BY bucket ASC))[1],
+    MAX(high_usd),
+    MIN(low_usd),
+    (ARRAY_AGG(close_usd ORDER BY bucket DESC))[1],
+    SUM(samples)
+FROM balance_history_hourly
+GROUP BY binance_account_id, DATE_TRUNC('day', bucket AT TIME ZONE 'UTC') AT TIME ZONE 'UTC';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE balance_history_daily;
+DROP TABLE balance_history_hourly;
+-- +goose StatementEnd
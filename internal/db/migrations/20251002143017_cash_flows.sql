@@ -0,0 +1,34 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE TABLE cash_flows (
+    id BIGSERIAL PRIMARY KEY,
+    binance_account_id INTEGER NOT NULL REFERENCES binance_accounts(id) ON DELETE CASCADE,
+    source VARCHAR(20) NOT NULL CHECK (source IN ('DEPOSIT', 'WITHDRAWAL', 'TRANSFER')),
+    wallet VARCHAR(10) NOT NULL CHECK (wallet IN ('SPOT', 'MARGIN')), -- wallet the flow moves funds in or out of
+    external_id VARCHAR(100) NOT NULL, -- deposit, withdrawal or transfer id at Binance
+    asset VARCHAR(20) NOT NULL,
+    amount DECIMAL(28,10) NOT NULL, -- positive into the wallet, negative out of it
+    fee DECIMAL(28,10) NOT NULL DEFAULT 0,
+    price_usdt DECIMAL(28,10), -- price at occurred_at, NULL when the asset has no market to price it
+    amount_usdt DECIMAL(28,10), -- amount including fee, in USDT
+    occurred_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    UNIQUE(binance_account_id, source, external_id)
+);
+
+CREATE INDEX idx_cash_flows_account_time ON cash_flows(binance_account_id, occurred_at);
+
+-- How far the history of each account has been pulled from Binance
+CREATE TABLE cash_flow_syncs (
+    binance_account_id INTEGER PRIMARY KEY REFERENCES binance_accounts(id) ON DELETE CASCADE,
+    synced_until TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW()
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE cash_flow_syncs;
+DROP TABLE cash_flows;
+-- +goose StatementEnd
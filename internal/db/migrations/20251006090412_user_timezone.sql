@@ -0,0 +1,10 @@
+-- +goose Up
+-- +goose StatementBegin
+-- IANA time zone the user's day, month and year boundaries follow
+ALTER TABLE users ADD COLUMN timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE users DROP COLUMN timezone;
+-- +goose StatementEnd
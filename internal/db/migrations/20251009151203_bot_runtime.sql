@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Why the bot runner put a bot into ERROR, cleared on the next status change
+ALTER TABLE bots ADD COLUMN status_reason TEXT;
+ALTER TABLE bots ADD COLUMN status_changed_at TIMESTAMP WITH TIME ZONE DEFAULT NOW();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE bots DROP COLUMN status_changed_at;
+ALTER TABLE bots DROP COLUMN status_reason;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Parameters for the bot's strategy, validated by the strategy registry
+ALTER TABLE bots ADD COLUMN strategy_params JSONB NOT NULL DEFAULT '{}';
+
+-- Bots so far only placed the orders posted to their webhook, whatever the
+-- strategy name said. Their strategy can't be run, so they have to be moved
+-- to a registered one by hand, e.g. 'webhook', before migrating.
+DO $$
+DECLARE
+    unknown TEXT;
+BEGIN
+    SELECT string_agg(id || ' (' || strategy || ')', ', ' ORDER BY id) INTO unknown
+    FROM bots
+    WHERE strategy NOT IN ('webhook', 'sma_crossover');
+
+    IF unknown IS NOT NULL THEN
+        RAISE EXCEPTION 'bots with unknown strategies: %. Set their strategy to webhook or sma_crossover and migrate again', unknown;
+    END IF;
+END $$;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE bots DROP COLUMN strategy_params;
+-- +goose StatementEnd
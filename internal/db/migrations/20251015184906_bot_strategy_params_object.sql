@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Params are validated against the strategy's schema by the server, the
+-- database only makes sure they stay a JSON object
+ALTER TABLE bots ADD CONSTRAINT check_strategy_params CHECK (jsonb_typeof(strategy_params) = 'object');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE bots DROP CONSTRAINT check_strategy_params;
+-- +goose StatementEnd
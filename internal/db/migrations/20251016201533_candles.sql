@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Closed klines downloaded from Binance, for strategies and backtests
+CREATE TABLE candles (
+    symbol VARCHAR(20) NOT NULL,
+    timeframe VARCHAR(5) NOT NULL, -- kline interval, e.g. 1h
+    open_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    close_time TIMESTAMP WITH TIME ZONE NOT NULL,
+    open DECIMAL(28,10) NOT NULL,
+    high DECIMAL(28,10) NOT NULL,
+    low DECIMAL(28,10) NOT NULL,
+    close DECIMAL(28,10) NOT NULL,
+    volume DECIMAL(38,10) NOT NULL,
+    trades BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    PRIMARY KEY (symbol, timeframe, open_time)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE candles;
+-- +goose StatementEnd
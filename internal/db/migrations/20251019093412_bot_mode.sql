@@ -0,0 +1,38 @@
+-- +goose Up
+-- +goose StatementBegin
+-- live trades on the bot's account, testnet on a testnet account and paper
+-- fills against live prices with the virtual balances below
+ALTER TABLE bots ADD COLUMN mode VARCHAR(10) NOT NULL DEFAULT 'live';
+ALTER TABLE bots ADD CONSTRAINT check_bot_mode CHECK (mode IN ('live', 'paper', 'testnet'));
+
+-- The mode of the bot when the order was placed, so paper and testnet fills
+-- stay out of the account's positions and the user's totals
+ALTER TABLE orders ADD COLUMN mode VARCHAR(10) NOT NULL DEFAULT 'live';
+ALTER TABLE orders ADD CONSTRAINT check_order_mode CHECK (mode IN ('live', 'paper', 'testnet'));
+
+UPDATE bots b SET mode = 'testnet'
+FROM binance_accounts ba
+WHERE b.binance_account_id = ba.id AND ba.base_url LIKE '%testnet%';
+
+UPDATE orders o SET mode = 'testnet'
+FROM binance_accounts ba
+WHERE o.binance_account_id = ba.id AND ba.base_url LIKE '%testnet%';
+-- +goose StatementEnd
+
+-- +goose StatementBegin
+CREATE TABLE paper_balances (
+    bot_id INTEGER NOT NULL REFERENCES bots(id) ON DELETE CASCADE,
+    asset VARCHAR(16) NOT NULL,
+    free DECIMAL(28,10) NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP WITH TIME ZONE DEFAULT NOW(),
+
+    PRIMARY KEY (bot_id, asset)
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP TABLE IF EXISTS paper_balances;
+ALTER TABLE orders DROP COLUMN mode;
+ALTER TABLE bots DROP COLUMN mode;
+-- +goose StatementEnd
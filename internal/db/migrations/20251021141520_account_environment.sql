@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Accounts pick a Binance environment instead of a free-form base URL, the
+-- API hosts of each environment are fixed in the binance package
+ALTER TABLE binance_accounts ADD COLUMN environment VARCHAR(20) NOT NULL DEFAULT 'mainnet';
+ALTER TABLE binance_accounts ADD CONSTRAINT check_account_environment
+    CHECK (environment IN ('mainnet', 'spot-testnet', 'futures-testnet'));
+
+UPDATE binance_accounts SET environment = CASE
+    WHEN base_url LIKE '%testnet.binance.vision%' THEN 'spot-testnet'
+    WHEN base_url LIKE '%testnet.binancefuture.com%' THEN 'futures-testnet'
+    ELSE 'mainnet'
+END;
+
+ALTER TABLE binance_accounts DROP COLUMN base_url;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE binance_accounts ADD COLUMN base_url VARCHAR(255) DEFAULT 'https://api.binance.com';
+
+UPDATE binance_accounts SET base_url = CASE environment
+    WHEN 'spot-testnet' THEN 'https://testnet.binance.vision'
+    WHEN 'futures-testnet' THEN 'https://testnet.binancefuture.com'
+    ELSE 'https://api.binance.com'
+END;
+
+ALTER TABLE binance_accounts DROP COLUMN environment;
+-- +goose StatementEnd
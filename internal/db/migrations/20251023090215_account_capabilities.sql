@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+-- What the API key can do, as found when it was last validated against
+-- Binance. validated_at is NULL for accounts added before keys were validated.
+ALTER TABLE binance_accounts ADD COLUMN spot_enabled BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE binance_accounts ADD COLUMN futures_enabled BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE binance_accounts ADD COLUMN ip_restricted BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE binance_accounts ADD COLUMN withdrawals_enabled BOOLEAN NOT NULL DEFAULT false;
+ALTER TABLE binance_accounts ADD COLUMN validated_at TIMESTAMP WITH TIME ZONE;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE binance_accounts DROP COLUMN validated_at;
+ALTER TABLE binance_accounts DROP COLUMN withdrawals_enabled;
+ALTER TABLE binance_accounts DROP COLUMN ip_restricted;
+ALTER TABLE binance_accounts DROP COLUMN futures_enabled;
+ALTER TABLE binance_accounts DROP COLUMN spot_enabled;
+-- +goose StatementEnd
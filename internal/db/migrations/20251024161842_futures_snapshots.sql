@@ -0,0 +1,16 @@
+-- +goose Up
+-- +goose StatementBegin
+-- USDⓈ-M futures wallet assets, net_asset is the margin balance including
+-- the unrealized PnL of open positions
+ALTER TABLE balance_asset_snapshots DROP CONSTRAINT balance_asset_snapshots_market_check;
+ALTER TABLE balance_asset_snapshots ADD CONSTRAINT balance_asset_snapshots_market_check
+    CHECK (market IN ('SPOT', 'MARGIN', 'FUTURES'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM balance_asset_snapshots WHERE market = 'FUTURES';
+ALTER TABLE balance_asset_snapshots DROP CONSTRAINT balance_asset_snapshots_market_check;
+ALTER TABLE balance_asset_snapshots ADD CONSTRAINT balance_asset_snapshots_market_check
+    CHECK (market IN ('SPOT', 'MARGIN'));
+-- +goose StatementEnd
@@ -0,0 +1,29 @@
+-- +goose Up
+-- +goose StatementBegin
+-- The market a bot trades on: spot, cross margin or the isolated margin
+-- account of the pair it trades
+ALTER TABLE bots ADD COLUMN market VARCHAR(10) NOT NULL DEFAULT 'SPOT';
+ALTER TABLE bots ADD CONSTRAINT check_bot_market CHECK (market IN ('SPOT', 'MARGIN', 'ISOLATED'));
+
+ALTER TABLE orders DROP CONSTRAINT check_order_market;
+ALTER TABLE orders ADD CONSTRAINT check_order_market CHECK (market IN ('SPOT', 'MARGIN', 'ISOLATED'));
+
+-- Isolated assets are summed over the pairs that hold them
+ALTER TABLE balance_asset_snapshots DROP CONSTRAINT balance_asset_snapshots_market_check;
+ALTER TABLE balance_asset_snapshots ADD CONSTRAINT balance_asset_snapshots_market_check
+    CHECK (market IN ('SPOT', 'MARGIN', 'FUTURES', 'ISOLATED'));
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM balance_asset_snapshots WHERE market = 'ISOLATED';
+ALTER TABLE balance_asset_snapshots DROP CONSTRAINT balance_asset_snapshots_market_check;
+ALTER TABLE balance_asset_snapshots ADD CONSTRAINT balance_asset_snapshots_market_check
+    CHECK (market IN ('SPOT', 'MARGIN', 'FUTURES'));
+
+DELETE FROM orders WHERE market = 'ISOLATED';
+ALTER TABLE orders DROP CONSTRAINT check_order_market;
+ALTER TABLE orders ADD CONSTRAINT check_order_market CHECK (market IN ('SPOT', 'MARGIN'));
+
+ALTER TABLE bots DROP COLUMN market;
+-- +goose StatementEnd
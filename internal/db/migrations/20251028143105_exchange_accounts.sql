@@ -0,0 +1,25 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Accounts can be on other exchanges than Binance, the environments to pick
+-- from depend on the exchange
+ALTER TABLE binance_accounts RENAME TO exchange_accounts;
+ALTER TABLE exchange_accounts ADD COLUMN exchange VARCHAR(20) NOT NULL DEFAULT 'binance';
+
+ALTER TABLE exchange_accounts DROP CONSTRAINT check_account_environment;
+ALTER TABLE exchange_accounts ADD CONSTRAINT check_account_environment CHECK (
+    (exchange = 'binance' AND environment IN ('mainnet', 'spot-testnet', 'futures-testnet'))
+    OR (exchange = 'bybit' AND environment IN ('mainnet', 'testnet'))
+);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM exchange_accounts WHERE exchange <> 'binance';
+
+ALTER TABLE exchange_accounts DROP CONSTRAINT check_account_environment;
+ALTER TABLE exchange_accounts ADD CONSTRAINT check_account_environment
+    CHECK (environment IN ('mainnet', 'spot-testnet', 'futures-testnet'));
+
+ALTER TABLE exchange_accounts DROP COLUMN exchange;
+ALTER TABLE exchange_accounts RENAME TO binance_accounts;
+-- +goose StatementEnd
@@ -0,0 +1,12 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Orders whose placement got no answer are UNKNOWN until reconciled
+DROP INDEX IF EXISTS idx_orders_open;
+CREATE INDEX idx_orders_open ON orders(binance_account_id) WHERE status IN ('NEW', 'PARTIALLY_FILLED', 'PENDING_CANCEL', 'UNKNOWN');
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_orders_open;
+CREATE INDEX idx_orders_open ON orders(binance_account_id) WHERE status IN ('NEW', 'PARTIALLY_FILLED', 'PENDING_CANCEL');
+-- +goose StatementEnd
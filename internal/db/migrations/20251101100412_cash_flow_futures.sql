@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Transfers into and out of the futures wallet are cash flows too. A transfer
+-- between two tracked wallets is stored once for each, under the same id.
+ALTER TABLE cash_flows DROP CONSTRAINT cash_flows_wallet_check;
+ALTER TABLE cash_flows ADD CONSTRAINT cash_flows_wallet_check CHECK (wallet IN ('SPOT', 'MARGIN', 'FUTURES'));
+ALTER TABLE cash_flows DROP CONSTRAINT cash_flows_binance_account_id_source_external_id_key;
+ALTER TABLE cash_flows ADD CONSTRAINT cash_flows_binance_account_id_source_wallet_external_id_key UNIQUE (binance_account_id, source, wallet, external_id);
+
+-- Futures transfers were never pulled, so every account syncs from the
+-- start again. Flows already stored are skipped.
+DELETE FROM cash_flow_syncs;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DELETE FROM cash_flows WHERE wallet = 'FUTURES';
+ALTER TABLE cash_flows DROP CONSTRAINT cash_flows_binance_account_id_source_wallet_external_id_key;
+ALTER TABLE cash_flows ADD CONSTRAINT cash_flows_binance_account_id_source_external_id_key UNIQUE (binance_account_id, source, external_id);
+ALTER TABLE cash_flows DROP CONSTRAINT cash_flows_wallet_check;
+ALTER TABLE cash_flows ADD CONSTRAINT cash_flows_wallet_check CHECK (wallet IN ('SPOT', 'MARGIN'));
+-- +goose StatementEnd
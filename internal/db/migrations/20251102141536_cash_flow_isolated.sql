@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Transfers into and out of the isolated margin pairs are cash flows too
+ALTER TABLE cash_flows DROP CONSTRAINT cash_flows_wallet_check;
+ALTER TABLE cash_flows ADD CONSTRAINT cash_flows_wallet_check CHECK (wallet IN ('SPOT', 'MARGIN', 'ISOLATED', 'FUTURES'));
+
+-- Isolated transfers were never pulled, so every account syncs from the
+-- start again. Flows already stored are skipped.
+DELETE FROM cash_flow_syncs;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- The cross margin side of transfers to isolated pairs goes with them
+DELETE FROM cash_flows cf
+WHERE cf.wallet = 'ISOLATED'
+   OR (cf.wallet = 'MARGIN' AND EXISTS (
+       SELECT 1 FROM cash_flows i
+       WHERE i.binance_account_id = cf.binance_account_id
+         AND i.source = cf.source
+         AND i.external_id = cf.external_id
+         AND i.wallet = 'ISOLATED'
+   ));
+ALTER TABLE cash_flows DROP CONSTRAINT cash_flows_wallet_check;
+ALTER TABLE cash_flows ADD CONSTRAINT cash_flows_wallet_check CHECK (wallet IN ('SPOT', 'MARGIN', 'FUTURES'));
+-- +goose StatementEnd
@@ -0,0 +1,41 @@
+-- +goose Up
+-- +goose StatementBegin
+-- Daily buckets used to be days in the server's time zone. All of them are
+-- off by the same offset, so each moves to the nearest UTC midnight without
+-- colliding with another.
+UPDATE balance_history_daily
+SET bucket = DATE_TRUNC('day', (bucket + INTERVAL '12 hours') AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'
+WHERE bucket <> DATE_TRUNC('day', bucket AT TIME ZONE 'UTC') AT TIME ZONE 'UTC';
+
+-- The days the hourly buckets still cover in full are rolled again exactly
+INSERT INTO balance_history_daily (binance_account_id, bucket, open_usd, high_usd, low_usd, close_usd, samples)
+SELECT
+    h.binance_account_id,
+    DATE_TRUNC('day', h.bucket AT TIME ZONE 'UTC') AT TIME ZONE 'UTC',
+    (ARRAY_AGG(h.open_usd ORDER BY h.bucket ASC))[1],
+    MAX(h.high_usd),
+    MIN(h.low_usd),
+    (ARRAY_AGG(h.close_usd ORDER BY h.bucket DESC))[1],
+    SUM(h.samples)
+FROM balance_history_hourly h
+WHERE h.bucket >= (
+    SELECT DATE_TRUNC('day', MIN(f.bucket) AT TIME ZONE 'UTC') AT TIME ZONE 'UTC' + INTERVAL '1 day'
+    FROM balance_history_hourly f
+    WHERE f.binance_account_id = h.binance_account_id
+)
+GROUP BY h.binance_account_id, DATE_TRUNC('day', h.bucket AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'
+ON CONFLICT (binance_account_id, bucket) DO UPDATE SET
+    open_usd = EXCLUDED.open_usd,
+    high_usd = EXCLUDED.high_usd,
+    low_usd = EXCLUDED.low_usd,
+    close_usd = EXCLUDED.close_usd,
+    samples = EXCLUDED.samples,
+    updated_at = NOW();
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+-- Days in the server's time zone can't be rebuilt once the hourly buckets
+-- are pruned, so the daily buckets stay on UTC days
+SELECT 1;
+-- +goose StatementEnd
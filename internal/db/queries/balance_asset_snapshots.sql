@@ -0,0 +1,20 @@
+-- name: CreateBalanceAssetSnapshot :exec
+INSERT INTO balance_asset_snapshots (
+    balance_history_id, binance_account_id, market, asset,
+    free, locked, borrowed, interest, net_asset, price_usdt, value_usdt, recorded_at
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12);
+
+-- name: GetBalanceAssetSnapshots :many
+SELECT id, balance_history_id, binance_account_id, market, asset, free, locked, borrowed, interest, net_asset, price_usdt, value_usdt, recorded_at
+FROM balance_asset_snapshots
+WHERE balance_history_id = $1
+ORDER BY market, value_usdt DESC NULLS LAST;
+
+-- name: GetAccountAllocationHistory :many
+SELECT recorded_at, asset, SUM(net_asset)::DECIMAL as net_asset, SUM(value_usdt)::DECIMAL as value_usdt
+FROM balance_asset_snapshots
+WHERE binance_account_id = $1
+  AND recorded_at >= $2
+GROUP BY recorded_at, asset
+ORDER BY recorded_at, asset;
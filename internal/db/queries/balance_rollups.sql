@@ -0,0 +1,93 @@
+-- name: RollupHourlyBalances :exec
+INSERT INTO balance_history_hourly (binance_account_id, bucket, open_usd, high_usd, low_usd, close_usd, samples)
+SELECT
+    binance_account_id,
+    DATE_TRUNC('hour', recorded_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC',
+    (ARRAY_AGG(total_balance_usd ORDER BY recorded_at ASC))[1],
+    MAX(total_balance_usd),
+    MIN(total_balance_usd),
+    (ARRAY_AGG(total_balance_usd ORDER BY recorded_at DESC))[1],
+    COUNT(*)
+FROM balance_history
+WHERE recorded_at >= DATE_TRUNC('hour', sqlc.arg(since)::TIMESTAMPTZ AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'
+GROUP BY binance_account_id, DATE_TRUNC('hour', recorded_at AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'
+ON CONFLICT (binance_account_id, bucket) DO UPDATE SET
+    open_usd = EXCLUDED.open_usd,
+    high_usd = EXCLUDED.high_usd,
+    low_usd = EXCLUDED.low_usd,
+    close_usd = EXCLUDED.close_usd,
+    samples = EXCLUDED.samples,
+    updated_at = NOW();
+
+-- name: RollupDailyBalances :exec
+INSERT INTO balance_history_daily (binance_account_id, bucket, open_usd, high_usd, low_usd, close_usd, samples)
+SELECT
+    binance_account_id,
+    DATE_TRUNC('day', bucket AT TIME ZONE 'UTC') AT TIME ZONE 'UTC',
+    (ARRAY_AGG(open_usd ORDER BY bucket ASC))[1],
+    MAX(high_usd),
+    MIN(low_usd),
+    (ARRAY_AGG(close_usd ORDER BY bucket DESC))[1],
+    SUM(samples)
+FROM balance_history_hourly
+WHERE bucket >= DATE_TRUNC('day', sqlc.arg(since)::TIMESTAMPTZ AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'
+GROUP BY binance_account_id, DATE_TRUNC('day', bucket AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'
+ON CONFLICT (binance_account_id, bucket) DO UPDATE SET
+    open_usd = EXCLUDED.open_usd,
+    high_usd = EXCLUDED.high_usd,
+    low_usd = EXCLUDED.low_usd,
+    close_usd = EXCLUDED.close_usd,
+    samples = EXCLUDED.samples,
+    updated_at = NOW();
+
+-- name: GetLatestHourlyBucket :one
+SELECT COALESCE(MAX(bucket), '1970-01-01')::TIMESTAMPTZ as latest
+FROM balance_history_hourly;
+
+-- name: GetLatestDailyBucket :one
+SELECT COALESCE(MAX(bucket), '1970-01-01')::TIMESTAMPTZ as latest
+FROM balance_history_daily;
+
+-- name: DeleteOldHourlyBalances :exec
+DELETE FROM balance_history_hourly h
+WHERE h.bucket < $1
+  -- The bucket closing at the owner's local midnight is kept, it is where
+  -- their days, months and years start and end
+  AND NOT EXISTS (
+      SELECT 1
+      FROM exchange_accounts ba
+      JOIN users u ON u.id = ba.user_id
+      JOIN pg_timezone_names tz ON tz.name = u.timezone
+      WHERE ba.id = h.binance_account_id
+        AND ((h.bucket + INTERVAL '1 hour') AT TIME ZONE u.timezone)::TIME = '00:00'
+  );
+
+-- name: DeleteOldDailyBalances :exec
+DELETE FROM balance_history_daily
+WHERE bucket < $1;
+
+-- name: GetAccountHourlyBalanceHistory :many
+SELECT binance_account_id, bucket, open_usd, high_usd, low_usd, close_usd, samples, updated_at
+FROM balance_history_hourly
+WHERE binance_account_id = $1
+AND bucket >= COALESCE(
+    (SELECT MAX(bucket) FROM balance_history_hourly
+     WHERE binance_account_id = $1 AND bucket <= sqlc.arg(from_time)),
+    sqlc.arg(from_time)
+)
+AND bucket <= sqlc.arg(to_time)
+ORDER BY bucket ASC
+LIMIT sqlc.arg(row_limit);
+
+-- name: GetAccountDailyBalanceHistory :many
+SELECT binance_account_id, bucket, open_usd, high_usd, low_usd, close_usd, samples, updated_at
+FROM balance_history_daily
+WHERE binance_account_id = $1
+AND bucket >= COALESCE(
+    (SELECT MAX(bucket) FROM balance_history_daily
+     WHERE binance_account_id = $1 AND bucket <= sqlc.arg(from_time)),
+    sqlc.arg(from_time)
+)
+AND bucket <= sqlc.arg(to_time)
+ORDER BY bucket ASC
+LIMIT sqlc.arg(row_limit);
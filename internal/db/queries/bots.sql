@@ -1,11 +1,11 @@
 -- name: CreateBot :one
-INSERT INTO bots (user_id, name, strategy, initial_holding, binance_account_id)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, user_id, name, strategy, status, win_rate, profit_factor, trades, initial_holding, holding, binance_account_id, created_at, updated_at;
+INSERT INTO bots (user_id, name, strategy, initial_holding, binance_account_id, webhook_secret, strategy_params, mode, market)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, user_id, name, strategy, strategy_params, mode, market, status, win_rate, profit_factor, trades, initial_holding, holding, binance_account_id, webhook_secret, created_at, updated_at;
 
 
 -- name: GetUserBots :many
-SELECT id, user_id, name, strategy, status, win_rate, profit_factor, trades, initial_holding, holding, binance_account_id, created_at, updated_at
+SELECT id, user_id, name, strategy, strategy_params, mode, market, status, win_rate, profit_factor, trades, initial_holding, holding, binance_account_id, created_at, updated_at
 FROM bots
 WHERE user_id = $1;
 
@@ -18,9 +18,11 @@ WHERE id = $1 AND user_id = $2;
 UPDATE bots
 SET 
     status = $3,
+    status_reason = NULL,
+    status_changed_at = NOW(),
     updated_at = NOW()
 WHERE id = $1 AND user_id = $2
-RETURNING id, user_id, name, strategy, status, win_rate, profit_factor, trades, initial_holding, holding, created_at, updated_at;
+RETURNING id, user_id, name, strategy, status, win_rate, profit_factor, trades, initial_holding, holding, created_at, updated_at, status_reason;
 
 -- name: DeleteBot :exec
 DELETE FROM bots
@@ -33,19 +35,70 @@ SET
     strategy = $4,
     initial_holding = $5,
     binance_account_id = $6,
+    strategy_params = $7,
+    mode = $8,
+    market = $9,
     updated_at = NOW()
 WHERE id = $1 AND user_id = $2
-RETURNING id, user_id, name, strategy, status, win_rate, profit_factor, trades, initial_holding, holding, binance_account_id, created_at, updated_at;
+RETURNING id, user_id, name, strategy, strategy_params, mode, market, status, win_rate, profit_factor, trades, initial_holding, holding, binance_account_id, created_at, updated_at;
+
+-- name: UpdateBotStats :exec
+UPDATE bots
+SET
+    win_rate = $2,
+    profit_factor = $3,
+    trades = $4,
+    holding = COALESCE(initial_holding, 0) + sqlc.arg(realized_pnl)::DECIMAL,
+    updated_at = NOW()
+WHERE id = $1;
 
 -- name: GetUserBotsWithAccounts :many
 SELECT 
-    b.id, b.user_id, b.name, b.strategy, b.status, b.win_rate, b.profit_factor, b.trades, 
-    b.initial_holding, b.holding, b.binance_account_id, b.created_at, b.updated_at,
+    b.id, b.user_id, b.name, b.strategy, b.strategy_params, b.mode, b.market, b.status, b.win_rate, b.profit_factor, b.trades, 
+    b.initial_holding, b.holding, b.binance_account_id, b.created_at, b.updated_at, b.status_reason,
     ba.name as account_name
 FROM bots b
-LEFT JOIN binance_accounts ba ON b.binance_account_id = ba.id
+LEFT JOIN exchange_accounts ba ON b.binance_account_id = ba.id
 WHERE b.user_id = $1;
 
 -- name: LogWebhook :one
 
 
+
+-- name: GetBotWebhookSecret :one
+SELECT webhook_secret
+FROM bots
+WHERE id = $1;
+
+-- name: RotateBotWebhookSecret :one
+UPDATE bots
+SET
+    webhook_secret = $3,
+    updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING id, name, webhook_secret;
+
+-- name: GetBotWithAccount :one
+SELECT
+    b.id, b.user_id, b.name, b.strategy, b.strategy_params, b.mode, b.market, b.status, b.binance_account_id,
+    ba.name as account_name, ba.api_key, ba.api_secret, ba.environment
+FROM bots b
+JOIN exchange_accounts ba ON b.binance_account_id = ba.id
+WHERE b.id = $1 AND ba.is_active = true;
+
+-- name: GetBotsForRunner :many
+SELECT
+    b.id, b.user_id, b.name, b.strategy, b.strategy_params, b.mode, b.market, b.status, b.binance_account_id,
+    ba.api_key, ba.api_secret, ba.environment
+FROM bots b
+LEFT JOIN exchange_accounts ba ON b.binance_account_id = ba.id AND ba.is_active = true
+ORDER BY b.id;
+
+-- name: SetBotError :exec
+UPDATE bots
+SET
+    status = 'ERROR',
+    status_reason = $2,
+    status_changed_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND status = 'RUNNING';
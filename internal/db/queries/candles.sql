@@ -0,0 +1,27 @@
+-- name: UpsertCandle :exec
+INSERT INTO candles (symbol, timeframe, open_time, close_time, open, high, low, close, volume, trades)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (symbol, timeframe, open_time) DO UPDATE
+SET
+    close_time = EXCLUDED.close_time,
+    open = EXCLUDED.open,
+    high = EXCLUDED.high,
+    low = EXCLUDED.low,
+    close = EXCLUDED.close,
+    volume = EXCLUDED.volume,
+    trades = EXCLUDED.trades,
+    updated_at = NOW();
+
+-- name: GetLatestCandleOpenTime :one
+SELECT MAX(open_time)::TIMESTAMPTZ AS latest_open_time
+FROM candles
+WHERE symbol = $1 AND timeframe = $2;
+
+-- name: GetCandles :many
+SELECT symbol, timeframe, open_time, close_time, open, high, low, close, volume, trades, updated_at
+FROM candles
+WHERE symbol = $1
+  AND timeframe = $2
+  AND open_time >= sqlc.arg(start_time)
+  AND open_time < sqlc.arg(end_time)
+ORDER BY open_time;
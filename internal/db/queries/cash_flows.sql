@@ -0,0 +1,30 @@
+-- name: CreateCashFlow :exec
+INSERT INTO cash_flows (
+    binance_account_id, source, wallet, external_id, asset,
+    amount, fee, price_usdt, amount_usdt, occurred_at
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+ON CONFLICT (binance_account_id, source, wallet, external_id) DO NOTHING;
+
+-- name: GetUserCashFlows :many
+SELECT cf.id, cf.binance_account_id, cf.source, cf.wallet, cf.external_id, cf.asset,
+       cf.amount, cf.fee, cf.price_usdt, cf.amount_usdt, cf.occurred_at, cf.created_at,
+       ba.futures_enabled
+FROM cash_flows cf
+JOIN exchange_accounts ba ON cf.binance_account_id = ba.id
+WHERE ba.user_id = $1
+  AND ba.is_active = true
+  AND cf.occurred_at > $2
+ORDER BY cf.occurred_at;
+
+-- name: GetCashFlowSyncedUntil :one
+SELECT COALESCE(s.synced_until, ba.created_at, NOW())::TIMESTAMPTZ as synced_until
+FROM exchange_accounts ba
+LEFT JOIN cash_flow_syncs s ON s.binance_account_id = ba.id
+WHERE ba.id = $1;
+
+-- name: UpsertCashFlowSync :exec
+INSERT INTO cash_flow_syncs (binance_account_id, synced_until)
+VALUES ($1, $2)
+ON CONFLICT (binance_account_id) DO UPDATE
+SET synced_until = EXCLUDED.synced_until, updated_at = NOW();
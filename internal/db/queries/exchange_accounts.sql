@@ -0,0 +1,100 @@
+-- name: CreateBinanceAccount :one
+INSERT INTO exchange_accounts (
+    user_id, name, api_key, api_secret, api_key_masked, environment,
+    spot_enabled, margin_enabled, futures_enabled, ip_restricted, withdrawals_enabled, exchange, validated_at
+)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, NOW())
+RETURNING id, user_id, name, api_key_masked, exchange, environment, is_active, created_at, updated_at;
+
+-- name: GetUserBinanceAccounts :many  
+SELECT id, user_id, name, api_key, api_secret, exchange, environment, futures_enabled, is_active, created_at, updated_at
+FROM exchange_accounts 
+WHERE user_id = $1 AND is_active = true;
+
+-- name: GetBinanceAccount :one
+SELECT id, user_id, name, api_key, api_secret, exchange, environment, is_active
+FROM exchange_accounts
+WHERE id = $1 AND user_id = $2 AND is_active = true;
+
+-- name: UpdateBinanceAccount :one
+UPDATE exchange_accounts
+SET name = $3, api_key = $4, api_secret = $5, api_key_masked = $6, environment = $7, updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING id, user_id, name, api_key_masked, exchange, environment, is_active, created_at, updated_at;
+
+-- name: DeleteBinanceAccount :exec
+UPDATE exchange_accounts 
+SET is_active = false, updated_at = NOW()
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetInactiveBinanceAccount :one
+SELECT id, user_id, name, api_key, api_secret, environment, is_active, created_at, updated_at
+FROM exchange_accounts
+WHERE user_id = $1 AND name = $2 AND is_active = false;
+
+-- name: ReactivateBinanceAccount :one
+UPDATE exchange_accounts
+SET 
+    api_key = $3,
+    api_secret = $4,
+    api_key_masked = $5,
+    environment = $6,
+    spot_enabled = $7,
+    margin_enabled = $8,
+    futures_enabled = $9,
+    ip_restricted = $10,
+    withdrawals_enabled = $11,
+    exchange = $12,
+    validated_at = NOW(),
+    is_active = true,
+    updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING id, user_id, name, api_key_masked, exchange, environment, is_active, created_at, updated_at;
+
+-- name: UpdateBinanceAccountInfo :one
+UPDATE exchange_accounts
+SET 
+    name = $3,
+    environment = $4,
+    updated_at = NOW()
+WHERE id = $1 AND user_id = $2 AND is_active = true
+RETURNING id, user_id, name, api_key_masked, exchange, environment, is_active, created_at, updated_at;
+
+-- name: SetBinanceAccountCapabilities :exec
+UPDATE exchange_accounts
+SET 
+    spot_enabled = $2,
+    margin_enabled = $3,
+    futures_enabled = $4,
+    ip_restricted = $5,
+    withdrawals_enabled = $6,
+    validated_at = NOW()
+WHERE id = $1;
+
+-- name: GetUserBinanceAccountsWithStatus :many
+SELECT 
+    ba.id, ba.user_id, ba.name, ba.api_key_masked, ba.exchange, ba.environment, ba.margin_enabled, ba.is_active, ba.created_at, ba.updated_at,
+    ba.spot_enabled, ba.futures_enabled, ba.ip_restricted, ba.withdrawals_enabled, ba.validated_at,
+    CASE 
+        WHEN b.id IS NOT NULL THEN true 
+        ELSE false 
+    END as account_active
+FROM exchange_accounts ba
+LEFT JOIN bots b ON ba.id = b.binance_account_id
+WHERE ba.user_id = $1 AND ba.is_active = true;
+
+-- name: GetAllBinanceAccountCredentials :many
+SELECT id, api_key, api_secret
+FROM exchange_accounts
+ORDER BY id;
+
+-- name: UpdateBinanceAccountCredentials :exec
+UPDATE exchange_accounts
+SET api_key = $2, api_secret = $3
+WHERE id = $1;
+
+-- name: GetActiveBinanceAccounts :many
+SELECT id, user_id, name, api_key, api_secret, exchange, environment, futures_enabled
+FROM exchange_accounts
+WHERE is_active = true
+ORDER BY id;
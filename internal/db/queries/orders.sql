@@ -0,0 +1,114 @@
+-- name: CreateOrder :one
+INSERT INTO orders (
+    bot_id, binance_account_id, client_order_id, symbol, market, side, order_type,
+    price, stop_price, orig_qty, mode
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+) RETURNING id, bot_id, binance_account_id, exchange_order_id, client_order_id, symbol, market, side, order_type, status, price, stop_price, orig_qty, executed_qty, cumulative_quote_qty, error_message, created_at, updated_at, mode;
+
+-- name: UpdateOrderFromExchange :one
+UPDATE orders
+SET
+    exchange_order_id = $2,
+    status = $3,
+    executed_qty = $4,
+    cumulative_quote_qty = $5,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, bot_id, binance_account_id, exchange_order_id, client_order_id, symbol, market, side, order_type, status, price, stop_price, orig_qty, executed_qty, cumulative_quote_qty, error_message, created_at, updated_at, mode;
+
+-- name: RejectOrder :exec
+UPDATE orders
+SET
+    status = 'REJECTED',
+    error_message = $2,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: MarkOrderUnknown :exec
+UPDATE orders
+SET
+    status = 'UNKNOWN',
+    error_message = $2,
+    updated_at = NOW()
+WHERE id = $1;
+
+-- name: GetOpenOrdersWithAccounts :many
+SELECT
+    o.id, o.bot_id, o.binance_account_id, o.exchange_order_id, o.client_order_id, o.symbol, o.market, o.status, o.created_at,
+    ba.api_key, ba.api_secret, ba.environment
+FROM orders o
+JOIN exchange_accounts ba ON o.binance_account_id = ba.id
+WHERE o.status IN ('NEW', 'PARTIALLY_FILLED', 'PENDING_CANCEL', 'UNKNOWN')
+  AND (o.exchange_order_id IS NOT NULL OR o.status = 'UNKNOWN')
+  AND o.mode <> 'paper'
+  AND ba.is_active = true
+ORDER BY o.binance_account_id, o.id;
+
+-- name: GetBotOrders :many
+SELECT o.id, o.bot_id, o.binance_account_id, o.exchange_order_id, o.client_order_id, o.symbol, o.market, o.side, o.order_type, o.status, o.price, o.stop_price, o.orig_qty, o.executed_qty, o.cumulative_quote_qty, o.error_message, o.created_at, o.updated_at, o.mode
+FROM orders o
+JOIN bots b ON o.bot_id = b.id
+WHERE o.bot_id = $1 AND b.user_id = $2
+ORDER BY o.created_at DESC
+LIMIT $3 OFFSET $4;
+
+-- name: GetOrderStatusHistory :many
+SELECT id, order_id, status, executed_qty, recorded_at
+FROM order_status_history
+WHERE order_id = $1
+ORDER BY recorded_at ASC;
+
+-- name: CreateFill :exec
+INSERT INTO fills (order_id, trade_id, price, quantity, commission, commission_asset, filled_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (order_id, trade_id) DO NOTHING;
+
+-- name: GetOrderFills :many
+SELECT id, order_id, trade_id, price, quantity, commission, commission_asset, filled_at
+FROM fills
+WHERE order_id = $1
+ORDER BY filled_at ASC, id ASC;
+
+-- name: GetBotFills :many
+SELECT
+    f.id, f.order_id, f.trade_id, f.price, f.quantity, f.commission, f.commission_asset, f.filled_at,
+    o.symbol, o.side
+FROM fills f
+JOIN orders o ON f.order_id = o.id
+JOIN bots b ON o.bot_id = b.id
+WHERE o.bot_id = $1 AND o.mode = b.mode
+ORDER BY f.filled_at ASC, f.id ASC;
+
+-- name: GetUserBotFills :many
+SELECT
+    o.bot_id, f.price, f.quantity, f.commission, f.commission_asset, f.filled_at,
+    o.symbol, o.side
+FROM fills f
+JOIN orders o ON f.order_id = o.id
+JOIN bots b ON o.bot_id = b.id
+WHERE b.user_id = $1 AND o.mode = 'live'
+ORDER BY o.bot_id, f.filled_at ASC, f.id ASC;
+
+-- name: GetUserAccountFills :many
+SELECT
+    o.binance_account_id, o.bot_id, b.name as bot_name, o.symbol, o.market, o.side,
+    f.price, f.quantity, f.commission, f.commission_asset, f.filled_at
+FROM fills f
+JOIN orders o ON f.order_id = o.id
+JOIN bots b ON o.bot_id = b.id
+WHERE b.user_id = $1 AND o.mode <> 'paper'
+ORDER BY o.binance_account_id, o.bot_id, f.filled_at ASC, f.id ASC;
+
+-- name: GetBotOpenOrdersWithAccounts :many
+SELECT
+    o.id, o.bot_id, o.binance_account_id, o.exchange_order_id, o.client_order_id, o.symbol, o.market, o.status, o.created_at,
+    ba.api_key, ba.api_secret, ba.environment
+FROM orders o
+JOIN exchange_accounts ba ON o.binance_account_id = ba.id
+WHERE o.bot_id = $1
+  AND o.status IN ('NEW', 'PARTIALLY_FILLED')
+  AND o.exchange_order_id IS NOT NULL
+  AND o.mode <> 'paper'
+  AND ba.is_active = true
+ORDER BY o.id;
@@ -0,0 +1,22 @@
+-- name: GetPaperBotForUpdate :one
+SELECT initial_holding
+FROM bots
+WHERE id = $1
+FOR UPDATE;
+
+-- name: GetPaperBalances :many
+SELECT bot_id, asset, free, updated_at
+FROM paper_balances
+WHERE bot_id = $1
+ORDER BY asset;
+
+-- name: SetPaperBalance :exec
+INSERT INTO paper_balances (bot_id, asset, free)
+VALUES ($1, $2, $3)
+ON CONFLICT (bot_id, asset) DO UPDATE SET
+    free = EXCLUDED.free,
+    updated_at = NOW();
+
+-- name: DeletePaperBalances :exec
+DELETE FROM paper_balances
+WHERE bot_id = $1;
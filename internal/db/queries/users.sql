@@ -4,7 +4,7 @@ VALUES ($1, $2, $3, NOW(), NOW())
 RETURNING id, name, email, created_at, updated_at;
 
 -- name: GetUser :one
-SELECT id, name, email, created_at, updated_at
+SELECT id, name, email, created_at, updated_at, timezone
 FROM users
 WHERE id = $1;
 
@@ -35,6 +35,11 @@ SET
 WHERE id = $1
 RETURNING id, name, email, created_at, updated_at;
 
+-- name: UpdateUserTimezone :exec
+UPDATE users
+SET timezone = $2, updated_at = NOW()
+WHERE id = $1;
+
 -- name: DeleteUser :exec
 DELETE FROM users
 WHERE id = $1;
@@ -48,20 +48,31 @@ func (q *Queries) DeleteOldBalanceRecords(ctx context.Context, recordedAt pgtype
 const getAccountBalanceHistory = `-- name: GetAccountBalanceHistory :many
 SELECT id, binance_account_id, total_balance_usd, recorded_at
 FROM balance_history
-WHERE binance_account_id = $1 
-AND recorded_at >= $2
-ORDER BY recorded_at DESC
-LIMIT $3
+WHERE binance_account_id = $1
+AND recorded_at >= COALESCE(
+    (SELECT MAX(recorded_at) FROM balance_history
+     WHERE binance_account_id = $1 AND recorded_at <= $2),
+    $2
+)
+AND recorded_at <= $3
+ORDER BY recorded_at ASC
+LIMIT $4
 `
 
 type GetAccountBalanceHistoryParams struct {
 	BinanceAccountID int32              `json:"binance_account_id"`
-	RecordedAt       pgtype.Timestamptz `json:"recorded_at"`
-	Limit            int32              `json:"limit"`
+	FromTime         pgtype.Timestamptz `json:"from_time"`
+	ToTime           pgtype.Timestamptz `json:"to_time"`
+	RowLimit         int32              `json:"row_limit"`
 }
 
 func (q *Queries) GetAccountBalanceHistory(ctx context.Context, arg GetAccountBalanceHistoryParams) ([]BalanceHistory, error) {
-	rows, err := q.db.Query(ctx, getAccountBalanceHistory, arg.BinanceAccountID, arg.RecordedAt, arg.Limit)
+	rows, err := q.db.Query(ctx, getAccountBalanceHistory,
+		arg.BinanceAccountID,
+		arg.FromTime,
+		arg.ToTime,
+		arg.RowLimit,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -105,61 +116,59 @@ func (q *Queries) GetLatestBalanceByAccount(ctx context.Context, binanceAccountI
 	return i, err
 }
 
-const getLatestCompleteDayBalance = `-- name: GetLatestCompleteDayBalance :many
-WITH latest_complete_day AS (
-    SELECT DATE_TRUNC('day', MAX(recorded_at)) as complete_day
-    FROM balance_history 
-    WHERE DATE_TRUNC('day', recorded_at) < DATE_TRUNC('day', NOW())
-),
-day_balances AS (
-    SELECT 
-        bh.binance_account_id,
-        bh.total_balance_usd,
-        bh.recorded_at,
-        ba.name as account_name,
-        ba.user_id,
-        ROW_NUMBER() OVER (
-            PARTITION BY bh.binance_account_id 
-            ORDER BY bh.recorded_at DESC
-        ) as rn
-    FROM balance_history bh
-    JOIN binance_accounts ba ON bh.binance_account_id = ba.id
-    CROSS JOIN latest_complete_day lcd
-    WHERE DATE_TRUNC('day', bh.recorded_at) = lcd.complete_day
-      AND ba.is_active = true
-)
-SELECT 
-    binance_account_id,
-    account_name,
-    user_id,
-    total_balance_usd,
-    recorded_at
-FROM day_balances 
-WHERE rn = 1
-ORDER BY user_id, account_name
+const getUserBalancesAt = `-- name: GetUserBalancesAt :many
+SELECT ba.id as binance_account_id, latest.total_balance_usd::DECIMAL as total_balance_usd, latest.recorded_at::TIMESTAMPTZ as recorded_at
+FROM exchange_accounts ba
+CROSS JOIN LATERAL (
+    SELECT candidates.total_balance_usd, candidates.recorded_at
+    FROM (
+        (SELECT bh.total_balance_usd, bh.recorded_at
+         FROM balance_history bh
+         WHERE bh.binance_account_id = ba.id AND bh.recorded_at <= $1
+         ORDER BY bh.recorded_at DESC LIMIT 1)
+        UNION ALL
+        (SELECT h.close_usd, h.bucket + INTERVAL '1 hour'
+         FROM balance_history_hourly h
+         WHERE h.binance_account_id = ba.id AND h.bucket + INTERVAL '1 hour' <= $1
+         ORDER BY h.bucket DESC LIMIT 1)
+        UNION ALL
+        (SELECT d.close_usd, d.bucket + INTERVAL '1 day'
+         FROM balance_history_daily d
+         WHERE d.binance_account_id = ba.id AND d.bucket + INTERVAL '1 day' <= $1
+         ORDER BY d.bucket DESC LIMIT 1)
+    ) candidates
+    ORDER BY candidates.recorded_at DESC
+    LIMIT 1
+) latest
+WHERE ba.user_id = $2
+  AND ba.is_active = true
+  AND ba.environment = 'mainnet'
+  AND ba.exchange = 'binance'
+ORDER BY ba.id
 `
 
-type GetLatestCompleteDayBalanceRow struct {
+type GetUserBalancesAtParams struct {
+	At     pgtype.Timestamptz `json:"at"`
+	UserID int32              `json:"user_id"`
+}
+
+type GetUserBalancesAtRow struct {
 	BinanceAccountID int32              `json:"binance_account_id"`
-	AccountName      string             `json:"account_name"`
-	UserID           int32              `json:"user_id"`
 	TotalBalanceUsd  pgtype.Numeric     `json:"total_balance_usd"`
 	RecordedAt       pgtype.Timestamptz `json:"recorded_at"`
 }
 
-func (q *Queries) GetLatestCompleteDayBalance(ctx context.Context) ([]GetLatestCompleteDayBalanceRow, error) {
-	rows, err := q.db.Query(ctx, getLatestCompleteDayBalance)
+func (q *Queries) GetUserBalancesAt(ctx context.Context, arg GetUserBalancesAtParams) ([]GetUserBalancesAtRow, error) {
+	rows, err := q.db.Query(ctx, getUserBalancesAt, arg.At, arg.UserID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetLatestCompleteDayBalanceRow
+	var items []GetUserBalancesAtRow
 	for rows.Next() {
-		var i GetLatestCompleteDayBalanceRow
+		var i GetUserBalancesAtRow
 		if err := rows.Scan(
 			&i.BinanceAccountID,
-			&i.AccountName,
-			&i.UserID,
 			&i.TotalBalanceUsd,
 			&i.RecordedAt,
 		); err != nil {
@@ -173,64 +182,57 @@ func (q *Queries) GetLatestCompleteDayBalance(ctx context.Context) ([]GetLatestC
 	return items, nil
 }
 
-const getLatestCompleteDayBalanceByUser = `-- name: GetLatestCompleteDayBalanceByUser :many
-WITH latest_complete_day AS (
-    SELECT DATE_TRUNC('day', MAX(recorded_at)) as complete_day
-    FROM balance_history 
-    WHERE DATE_TRUNC('day', recorded_at) < DATE_TRUNC('day', NOW())
-),
-day_balances AS (
-    SELECT 
-        bh.binance_account_id,
-        bh.total_balance_usd,
-        bh.recorded_at,
-        ba.name as account_name,
-        ba.user_id,
-        ROW_NUMBER() OVER (
-            PARTITION BY bh.binance_account_id 
-            ORDER BY bh.recorded_at DESC
-        ) as rn
-    FROM balance_history bh
-    JOIN binance_accounts ba ON bh.binance_account_id = ba.id
-    CROSS JOIN latest_complete_day lcd
-    WHERE DATE_TRUNC('day', bh.recorded_at) = lcd.complete_day
-      AND ba.is_active = true
-      AND ba.user_id = $1
-)
-SELECT 
-    binance_account_id,
-    account_name,
-    user_id,
-    total_balance_usd,
-    recorded_at
-FROM day_balances 
-WHERE rn = 1
-ORDER BY account_name
+const getUserHourlyBalances = `-- name: GetUserHourlyBalances :many
+SELECT h.binance_account_id, h.bucket, h.close_usd as total_balance_usd, (h.bucket + INTERVAL '1 hour')::TIMESTAMPTZ as closed_at
+FROM balance_history_hourly h
+JOIN exchange_accounts ba ON h.binance_account_id = ba.id
+WHERE ba.user_id = $1
+  AND ba.is_active = true
+  AND ba.environment = 'mainnet'
+  AND ba.exchange = 'binance'
+  AND h.bucket >= $2
+UNION ALL
+SELECT d.binance_account_id, d.bucket, d.close_usd as total_balance_usd, (d.bucket + INTERVAL '1 day')::TIMESTAMPTZ as closed_at
+FROM balance_history_daily d
+JOIN exchange_accounts ba ON d.binance_account_id = ba.id
+WHERE ba.user_id = $1
+  AND ba.is_active = true
+  AND ba.environment = 'mainnet'
+  AND ba.exchange = 'binance'
+  AND d.bucket >= $2
+  AND d.bucket < COALESCE(
+      (SELECT MIN(h.bucket) FROM balance_history_hourly h WHERE h.binance_account_id = d.binance_account_id),
+      'infinity'
+  )
+ORDER BY binance_account_id, bucket
 `
 
-type GetLatestCompleteDayBalanceByUserRow struct {
+type GetUserHourlyBalancesParams struct {
+	UserID int32              `json:"user_id"`
+	Since  pgtype.Timestamptz `json:"since"`
+}
+
+type GetUserHourlyBalancesRow struct {
 	BinanceAccountID int32              `json:"binance_account_id"`
-	AccountName      string             `json:"account_name"`
-	UserID           int32              `json:"user_id"`
+	Bucket           pgtype.Timestamptz `json:"bucket"`
 	TotalBalanceUsd  pgtype.Numeric     `json:"total_balance_usd"`
-	RecordedAt       pgtype.Timestamptz `json:"recorded_at"`
+	ClosedAt         pgtype.Timestamptz `json:"closed_at"`
 }
 
-func (q *Queries) GetLatestCompleteDayBalanceByUser(ctx context.Context, userID int32) ([]GetLatestCompleteDayBalanceByUserRow, error) {
-	rows, err := q.db.Query(ctx, getLatestCompleteDayBalanceByUser, userID)
+func (q *Queries) GetUserHourlyBalances(ctx context.Context, arg GetUserHourlyBalancesParams) ([]GetUserHourlyBalancesRow, error) {
+	rows, err := q.db.Query(ctx, getUserHourlyBalances, arg.UserID, arg.Since)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetLatestCompleteDayBalanceByUserRow
+	var items []GetUserHourlyBalancesRow
 	for rows.Next() {
-		var i GetLatestCompleteDayBalanceByUserRow
+		var i GetUserHourlyBalancesRow
 		if err := rows.Scan(
 			&i.BinanceAccountID,
-			&i.AccountName,
-			&i.UserID,
+			&i.Bucket,
 			&i.TotalBalanceUsd,
-			&i.RecordedAt,
+			&i.ClosedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -241,131 +243,3 @@ func (q *Queries) GetLatestCompleteDayBalanceByUser(ctx context.Context, userID
 	}
 	return items, nil
 }
-
-const getUserTotalBalance = `-- name: GetUserTotalBalance :one
-SELECT COALESCE(SUM(bh.total_balance_usd), 0) as total_balance_usd
-FROM (
-    SELECT DISTINCT ON (binance_account_id) total_balance_usd
-    FROM balance_history bh
-    JOIN binance_accounts ba ON bh.binance_account_id = ba.id
-    WHERE ba.user_id = $1 AND ba.is_active = true
-    ORDER BY binance_account_id, recorded_at DESC
-) latest_balances
-`
-
-func (q *Queries) GetUserTotalBalance(ctx context.Context, userID int32) (interface{}, error) {
-	row := q.db.QueryRow(ctx, getUserTotalBalance, userID)
-	var total_balance_usd interface{}
-	err := row.Scan(&total_balance_usd)
-	return total_balance_usd, err
-}
-
-const getUserTotalBalanceEarliestInYear = `-- name: GetUserTotalBalanceEarliestInYear :one
-WITH target_year AS (
-    SELECT 
-        COALESCE(
-            (SELECT MAX(DATE_TRUNC('year', recorded_at)) 
-             FROM balance_history 
-             WHERE DATE_TRUNC('year', recorded_at) < DATE_TRUNC('year', NOW())),
-            DATE_TRUNC('year', NOW())
-        ) as year_start
-),
-earliest_balances AS (
-    SELECT DISTINCT ON (bh.binance_account_id) 
-        bh.binance_account_id,
-        bh.total_balance_usd
-    FROM balance_history bh
-    JOIN binance_accounts ba ON bh.binance_account_id = ba.id
-    CROSS JOIN target_year ty
-    WHERE DATE_TRUNC('year', bh.recorded_at) = ty.year_start
-        AND ba.is_active = true
-        AND ba.user_id = $1
-    ORDER BY bh.binance_account_id, bh.recorded_at ASC
-)
-SELECT
-    COALESCE(SUM(total_balance_usd), 0) as total_balance_usd
-FROM earliest_balances
-`
-
-func (q *Queries) GetUserTotalBalanceEarliestInYear(ctx context.Context, userID int32) (interface{}, error) {
-	row := q.db.QueryRow(ctx, getUserTotalBalanceEarliestInYear, userID)
-	var total_balance_usd interface{}
-	err := row.Scan(&total_balance_usd)
-	return total_balance_usd, err
-}
-
-const getUserTotalBalanceLatestCompleteDay = `-- name: GetUserTotalBalanceLatestCompleteDay :one
-WITH latest_complete_day AS (
-    SELECT DATE_TRUNC('day', MAX(recorded_at)) as complete_day
-    FROM balance_history 
-    WHERE DATE_TRUNC('day', recorded_at) < DATE_TRUNC('day', NOW())
-),
-day_balances AS (
-    SELECT 
-        bh.binance_account_id,
-        bh.total_balance_usd,
-        ba.user_id,
-        ROW_NUMBER() OVER (
-            PARTITION BY bh.binance_account_id 
-            ORDER BY bh.recorded_at DESC
-        ) as rn
-    FROM balance_history bh
-    JOIN binance_accounts ba ON bh.binance_account_id = ba.id
-    CROSS JOIN latest_complete_day lcd
-    WHERE DATE_TRUNC('day', bh.recorded_at) = lcd.complete_day
-      AND ba.is_active = true
-      AND ba.user_id = $1
-)
-SELECT 
-    COALESCE(SUM(total_balance_usd), 0) as total_balance_usd
-FROM day_balances 
-WHERE rn = 1
-`
-
-func (q *Queries) GetUserTotalBalanceLatestCompleteDay(ctx context.Context, userID int32) (interface{}, error) {
-	row := q.db.QueryRow(ctx, getUserTotalBalanceLatestCompleteDay, userID)
-	var total_balance_usd interface{}
-	err := row.Scan(&total_balance_usd)
-	return total_balance_usd, err
-}
-
-const getUserTotalBalanceLatestCompleteMonth = `-- name: GetUserTotalBalanceLatestCompleteMonth :one
-WITH target_month AS (
-    SELECT 
-        CASE 
-            WHEN EXISTS (
-                SELECT 1 FROM balance_history 
-                WHERE DATE_TRUNC('month', recorded_at) < DATE_TRUNC('month', NOW())
-            )
-            THEN DATE_TRUNC('month', NOW()) - INTERVAL '1 month'  -- Last complete month
-            ELSE DATE_TRUNC('month', NOW())                       -- Current month as fallback
-        END as month_start
-),
-month_balances AS (
-    SELECT 
-        bh.binance_account_id, 
-        bh.total_balance_usd,
-        ba.user_id,
-        ROW_NUMBER() OVER (
-            PARTITION BY bh.binance_account_id
-            ORDER BY bh.recorded_at ASC  -- EARLIEST instance from the target month
-        ) as rn
-    FROM balance_history bh
-    JOIN binance_accounts ba ON bh.binance_account_id = ba.id
-    CROSS JOIN target_month tm
-    WHERE DATE_TRUNC('month', bh.recorded_at) = tm.month_start
-        AND ba.is_active = true
-        AND ba.user_id = $1
-)
-SELECT
-    COALESCE(SUM(total_balance_usd), 0) as total_balance_usd
-FROM month_balances
-WHERE rn = 1
-`
-
-func (q *Queries) GetUserTotalBalanceLatestCompleteMonth(ctx context.Context, userID int32) (interface{}, error) {
-	row := q.db.QueryRow(ctx, getUserTotalBalanceLatestCompleteMonth, userID)
-	var total_balance_usd interface{}
-	err := row.Scan(&total_balance_usd)
-	return total_balance_usd, err
-}
@@ -7,22 +7,27 @@ package db
 
 import (
 	"context"
+	"encoding/json"
 
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 const createBot = `-- name: CreateBot :one
-INSERT INTO bots (user_id, name, strategy, initial_holding, binance_account_id)
-VALUES ($1, $2, $3, $4, $5)
-RETURNING id, user_id, name, strategy, status, win_rate, profit_factor, trades, initial_holding, holding, binance_account_id, created_at, updated_at
+INSERT INTO bots (user_id, name, strategy, initial_holding, binance_account_id, webhook_secret, strategy_params, mode, market)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+RETURNING id, user_id, name, strategy, strategy_params, mode, market, status, win_rate, profit_factor, trades, initial_holding, holding, binance_account_id, webhook_secret, created_at, updated_at
 `
 
 type CreateBotParams struct {
-	UserID           int32          `json:"user_id"`
-	Name             string         `json:"name"`
-	Strategy         string         `json:"strategy"`
-	InitialHolding   pgtype.Numeric `json:"initial_holding"`
-	BinanceAccountID pgtype.Int4    `json:"binance_account_id"`
+	UserID           int32           `json:"user_id"`
+	Name             string          `json:"name"`
+	Strategy         string          `json:"strategy"`
+	InitialHolding   pgtype.Numeric  `json:"initial_holding"`
+	BinanceAccountID pgtype.Int4     `json:"binance_account_id"`
+	WebhookSecret    pgtype.Text     `json:"webhook_secret"`
+	StrategyParams   json.RawMessage `json:"strategy_params"`
+	Mode             string          `json:"mode"`
+	Market           string          `json:"market"`
 }
 
 type CreateBotRow struct {
@@ -30,6 +35,9 @@ type CreateBotRow struct {
 	UserID           int32              `json:"user_id"`
 	Name             string             `json:"name"`
 	Strategy         string             `json:"strategy"`
+	StrategyParams   json.RawMessage    `json:"strategy_params"`
+	Mode             string             `json:"mode"`
+	Market           string             `json:"market"`
 	Status           pgtype.Text        `json:"status"`
 	WinRate          pgtype.Numeric     `json:"win_rate"`
 	ProfitFactor     pgtype.Numeric     `json:"profit_factor"`
@@ -37,6 +45,7 @@ type CreateBotRow struct {
 	InitialHolding   pgtype.Numeric     `json:"initial_holding"`
 	Holding          pgtype.Numeric     `json:"holding"`
 	BinanceAccountID pgtype.Int4        `json:"binance_account_id"`
+	WebhookSecret    pgtype.Text        `json:"webhook_secret"`
 	CreatedAt        pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
 }
@@ -48,6 +57,10 @@ func (q *Queries) CreateBot(ctx context.Context, arg CreateBotParams) (CreateBot
 		arg.Strategy,
 		arg.InitialHolding,
 		arg.BinanceAccountID,
+		arg.WebhookSecret,
+		arg.StrategyParams,
+		arg.Mode,
+		arg.Market,
 	)
 	var i CreateBotRow
 	err := row.Scan(
@@ -55,6 +68,9 @@ func (q *Queries) CreateBot(ctx context.Context, arg CreateBotParams) (CreateBot
 		&i.UserID,
 		&i.Name,
 		&i.Strategy,
+		&i.StrategyParams,
+		&i.Mode,
+		&i.Market,
 		&i.Status,
 		&i.WinRate,
 		&i.ProfitFactor,
@@ -62,6 +78,7 @@ func (q *Queries) CreateBot(ctx context.Context, arg CreateBotParams) (CreateBot
 		&i.InitialHolding,
 		&i.Holding,
 		&i.BinanceAccountID,
+		&i.WebhookSecret,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -129,8 +146,124 @@ func (q *Queries) GetBot(ctx context.Context, arg GetBotParams) (GetBotRow, erro
 	return i, err
 }
 
+const getBotWebhookSecret = `-- name: GetBotWebhookSecret :one
+SELECT webhook_secret
+FROM bots
+WHERE id = $1
+`
+
+func (q *Queries) GetBotWebhookSecret(ctx context.Context, id int32) (pgtype.Text, error) {
+	row := q.db.QueryRow(ctx, getBotWebhookSecret, id)
+	var webhook_secret pgtype.Text
+	err := row.Scan(&webhook_secret)
+	return webhook_secret, err
+}
+
+const getBotWithAccount = `-- name: GetBotWithAccount :one
+SELECT
+    b.id, b.user_id, b.name, b.strategy, b.strategy_params, b.mode, b.market, b.status, b.binance_account_id,
+    ba.name as account_name, ba.api_key, ba.api_secret, ba.environment
+FROM bots b
+JOIN exchange_accounts ba ON b.binance_account_id = ba.id
+WHERE b.id = $1 AND ba.is_active = true
+`
+
+type GetBotWithAccountRow struct {
+	ID               int32           `json:"id"`
+	UserID           int32           `json:"user_id"`
+	Name             string          `json:"name"`
+	Strategy         string          `json:"strategy"`
+	StrategyParams   json.RawMessage `json:"strategy_params"`
+	Mode             string          `json:"mode"`
+	Market           string          `json:"market"`
+	Status           pgtype.Text     `json:"status"`
+	BinanceAccountID pgtype.Int4     `json:"binance_account_id"`
+	AccountName      string          `json:"account_name"`
+	ApiKey           string          `json:"api_key"`
+	ApiSecret        string          `json:"api_secret"`
+	Environment      string          `json:"environment"`
+}
+
+func (q *Queries) GetBotWithAccount(ctx context.Context, id int32) (GetBotWithAccountRow, error) {
+	row := q.db.QueryRow(ctx, getBotWithAccount, id)
+	var i GetBotWithAccountRow
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Strategy,
+		&i.StrategyParams,
+		&i.Mode,
+		&i.Market,
+		&i.Status,
+		&i.BinanceAccountID,
+		&i.AccountName,
+		&i.ApiKey,
+		&i.ApiSecret,
+		&i.Environment,
+	)
+	return i, err
+}
+
+const getBotsForRunner = `-- name: GetBotsForRunner :many
+SELECT
+    b.id, b.user_id, b.name, b.strategy, b.strategy_params, b.mode, b.market, b.status, b.binance_account_id,
+    ba.api_key, ba.api_secret, ba.environment
+FROM bots b
+LEFT JOIN exchange_accounts ba ON b.binance_account_id = ba.id AND ba.is_active = true
+ORDER BY b.id
+`
+
+type GetBotsForRunnerRow struct {
+	ID               int32           `json:"id"`
+	UserID           int32           `json:"user_id"`
+	Name             string          `json:"name"`
+	Strategy         string          `json:"strategy"`
+	StrategyParams   json.RawMessage `json:"strategy_params"`
+	Mode             string          `json:"mode"`
+	Market           string          `json:"market"`
+	Status           pgtype.Text     `json:"status"`
+	BinanceAccountID pgtype.Int4     `json:"binance_account_id"`
+	ApiKey           pgtype.Text     `json:"api_key"`
+	ApiSecret        pgtype.Text     `json:"api_secret"`
+	Environment      pgtype.Text     `json:"environment"`
+}
+
+func (q *Queries) GetBotsForRunner(ctx context.Context) ([]GetBotsForRunnerRow, error) {
+	rows, err := q.db.Query(ctx, getBotsForRunner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetBotsForRunnerRow
+	for rows.Next() {
+		var i GetBotsForRunnerRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Strategy,
+			&i.StrategyParams,
+			&i.Mode,
+			&i.Market,
+			&i.Status,
+			&i.BinanceAccountID,
+			&i.ApiKey,
+			&i.ApiSecret,
+			&i.Environment,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getUserBots = `-- name: GetUserBots :many
-SELECT id, user_id, name, strategy, status, win_rate, profit_factor, trades, initial_holding, holding, binance_account_id, created_at, updated_at
+SELECT id, user_id, name, strategy, strategy_params, mode, market, status, win_rate, profit_factor, trades, initial_holding, holding, binance_account_id, created_at, updated_at
 FROM bots
 WHERE user_id = $1
 `
@@ -140,6 +273,9 @@ type GetUserBotsRow struct {
 	UserID           int32              `json:"user_id"`
 	Name             string             `json:"name"`
 	Strategy         string             `json:"strategy"`
+	StrategyParams   json.RawMessage    `json:"strategy_params"`
+	Mode             string             `json:"mode"`
+	Market           string             `json:"market"`
 	Status           pgtype.Text        `json:"status"`
 	WinRate          pgtype.Numeric     `json:"win_rate"`
 	ProfitFactor     pgtype.Numeric     `json:"profit_factor"`
@@ -165,6 +301,9 @@ func (q *Queries) GetUserBots(ctx context.Context, userID int32) ([]GetUserBotsR
 			&i.UserID,
 			&i.Name,
 			&i.Strategy,
+			&i.StrategyParams,
+			&i.Mode,
+			&i.Market,
 			&i.Status,
 			&i.WinRate,
 			&i.ProfitFactor,
@@ -187,11 +326,11 @@ func (q *Queries) GetUserBots(ctx context.Context, userID int32) ([]GetUserBotsR
 
 const getUserBotsWithAccounts = `-- name: GetUserBotsWithAccounts :many
 SELECT 
-    b.id, b.user_id, b.name, b.strategy, b.status, b.win_rate, b.profit_factor, b.trades, 
-    b.initial_holding, b.holding, b.binance_account_id, b.created_at, b.updated_at,
+    b.id, b.user_id, b.name, b.strategy, b.strategy_params, b.mode, b.market, b.status, b.win_rate, b.profit_factor, b.trades, 
+    b.initial_holding, b.holding, b.binance_account_id, b.created_at, b.updated_at, b.status_reason,
     ba.name as account_name
 FROM bots b
-LEFT JOIN binance_accounts ba ON b.binance_account_id = ba.id
+LEFT JOIN exchange_accounts ba ON b.binance_account_id = ba.id
 WHERE b.user_id = $1
 `
 
@@ -200,6 +339,9 @@ type GetUserBotsWithAccountsRow struct {
 	UserID           int32              `json:"user_id"`
 	Name             string             `json:"name"`
 	Strategy         string             `json:"strategy"`
+	StrategyParams   json.RawMessage    `json:"strategy_params"`
+	Mode             string             `json:"mode"`
+	Market           string             `json:"market"`
 	Status           pgtype.Text        `json:"status"`
 	WinRate          pgtype.Numeric     `json:"win_rate"`
 	ProfitFactor     pgtype.Numeric     `json:"profit_factor"`
@@ -209,6 +351,7 @@ type GetUserBotsWithAccountsRow struct {
 	BinanceAccountID pgtype.Int4        `json:"binance_account_id"`
 	CreatedAt        pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+	StatusReason     pgtype.Text        `json:"status_reason"`
 	AccountName      pgtype.Text        `json:"account_name"`
 }
 
@@ -226,6 +369,9 @@ func (q *Queries) GetUserBotsWithAccounts(ctx context.Context, userID int32) ([]
 			&i.UserID,
 			&i.Name,
 			&i.Strategy,
+			&i.StrategyParams,
+			&i.Mode,
+			&i.Market,
 			&i.Status,
 			&i.WinRate,
 			&i.ProfitFactor,
@@ -235,6 +381,7 @@ func (q *Queries) GetUserBotsWithAccounts(ctx context.Context, userID int32) ([]
 			&i.BinanceAccountID,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.StatusReason,
 			&i.AccountName,
 		); err != nil {
 			return nil, err
@@ -247,6 +394,54 @@ func (q *Queries) GetUserBotsWithAccounts(ctx context.Context, userID int32) ([]
 	return items, nil
 }
 
+const rotateBotWebhookSecret = `-- name: RotateBotWebhookSecret :one
+UPDATE bots
+SET
+    webhook_secret = $3,
+    updated_at = NOW()
+WHERE id = $1 AND user_id = $2
+RETURNING id, name, webhook_secret
+`
+
+type RotateBotWebhookSecretParams struct {
+	ID            int32       `json:"id"`
+	UserID        int32       `json:"user_id"`
+	WebhookSecret pgtype.Text `json:"webhook_secret"`
+}
+
+type RotateBotWebhookSecretRow struct {
+	ID            int32       `json:"id"`
+	Name          string      `json:"name"`
+	WebhookSecret pgtype.Text `json:"webhook_secret"`
+}
+
+func (q *Queries) RotateBotWebhookSecret(ctx context.Context, arg RotateBotWebhookSecretParams) (RotateBotWebhookSecretRow, error) {
+	row := q.db.QueryRow(ctx, rotateBotWebhookSecret, arg.ID, arg.UserID, arg.WebhookSecret)
+	var i RotateBotWebhookSecretRow
+	err := row.Scan(&i.ID, &i.Name, &i.WebhookSecret)
+	return i, err
+}
+
+const setBotError = `-- name: SetBotError :exec
+UPDATE bots
+SET
+    status = 'ERROR',
+    status_reason = $2,
+    status_changed_at = NOW(),
+    updated_at = NOW()
+WHERE id = $1 AND status = 'RUNNING'
+`
+
+type SetBotErrorParams struct {
+	ID           int32       `json:"id"`
+	StatusReason pgtype.Text `json:"status_reason"`
+}
+
+func (q *Queries) SetBotError(ctx context.Context, arg SetBotErrorParams) error {
+	_, err := q.db.Exec(ctx, setBotError, arg.ID, arg.StatusReason)
+	return err
+}
+
 const updateBot = `-- name: UpdateBot :one
 UPDATE bots
 SET
@@ -254,18 +449,24 @@ SET
     strategy = $4,
     initial_holding = $5,
     binance_account_id = $6,
+    strategy_params = $7,
+    mode = $8,
+    market = $9,
     updated_at = NOW()
 WHERE id = $1 AND user_id = $2
-RETURNING id, user_id, name, strategy, status, win_rate, profit_factor, trades, initial_holding, holding, binance_account_id, created_at, updated_at
+RETURNING id, user_id, name, strategy, strategy_params, mode, market, status, win_rate, profit_factor, trades, initial_holding, holding, binance_account_id, created_at, updated_at
 `
 
 type UpdateBotParams struct {
-	ID               int32          `json:"id"`
-	UserID           int32          `json:"user_id"`
-	Name             string         `json:"name"`
-	Strategy         string         `json:"strategy"`
-	InitialHolding   pgtype.Numeric `json:"initial_holding"`
-	BinanceAccountID pgtype.Int4    `json:"binance_account_id"`
+	ID               int32           `json:"id"`
+	UserID           int32           `json:"user_id"`
+	Name             string          `json:"name"`
+	Strategy         string          `json:"strategy"`
+	InitialHolding   pgtype.Numeric  `json:"initial_holding"`
+	BinanceAccountID pgtype.Int4     `json:"binance_account_id"`
+	StrategyParams   json.RawMessage `json:"strategy_params"`
+	Mode             string          `json:"mode"`
+	Market           string          `json:"market"`
 }
 
 type UpdateBotRow struct {
@@ -273,6 +474,9 @@ type UpdateBotRow struct {
 	UserID           int32              `json:"user_id"`
 	Name             string             `json:"name"`
 	Strategy         string             `json:"strategy"`
+	StrategyParams   json.RawMessage    `json:"strategy_params"`
+	Mode             string             `json:"mode"`
+	Market           string             `json:"market"`
 	Status           pgtype.Text        `json:"status"`
 	WinRate          pgtype.Numeric     `json:"win_rate"`
 	ProfitFactor     pgtype.Numeric     `json:"profit_factor"`
@@ -292,6 +496,9 @@ func (q *Queries) UpdateBot(ctx context.Context, arg UpdateBotParams) (UpdateBot
 		arg.Strategy,
 		arg.InitialHolding,
 		arg.BinanceAccountID,
+		arg.StrategyParams,
+		arg.Mode,
+		arg.Market,
 	)
 	var i UpdateBotRow
 	err := row.Scan(
@@ -299,6 +506,9 @@ func (q *Queries) UpdateBot(ctx context.Context, arg UpdateBotParams) (UpdateBot
 		&i.UserID,
 		&i.Name,
 		&i.Strategy,
+		&i.StrategyParams,
+		&i.Mode,
+		&i.Market,
 		&i.Status,
 		&i.WinRate,
 		&i.ProfitFactor,
@@ -312,13 +522,45 @@ func (q *Queries) UpdateBot(ctx context.Context, arg UpdateBotParams) (UpdateBot
 	return i, err
 }
 
+const updateBotStats = `-- name: UpdateBotStats :exec
+UPDATE bots
+SET
+    win_rate = $2,
+    profit_factor = $3,
+    trades = $4,
+    holding = COALESCE(initial_holding, 0) + $5::DECIMAL,
+    updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateBotStatsParams struct {
+	ID           int32          `json:"id"`
+	WinRate      pgtype.Numeric `json:"win_rate"`
+	ProfitFactor pgtype.Numeric `json:"profit_factor"`
+	Trades       pgtype.Int4    `json:"trades"`
+	RealizedPnl  pgtype.Numeric `json:"realized_pnl"`
+}
+
+func (q *Queries) UpdateBotStats(ctx context.Context, arg UpdateBotStatsParams) error {
+	_, err := q.db.Exec(ctx, updateBotStats,
+		arg.ID,
+		arg.WinRate,
+		arg.ProfitFactor,
+		arg.Trades,
+		arg.RealizedPnl,
+	)
+	return err
+}
+
 const updateBotStatus = `-- name: UpdateBotStatus :one
 UPDATE bots
 SET 
     status = $3,
+    status_reason = NULL,
+    status_changed_at = NOW(),
     updated_at = NOW()
 WHERE id = $1 AND user_id = $2
-RETURNING id, user_id, name, strategy, status, win_rate, profit_factor, trades, initial_holding, holding, created_at, updated_at
+RETURNING id, user_id, name, strategy, status, win_rate, profit_factor, trades, initial_holding, holding, created_at, updated_at, status_reason
 `
 
 type UpdateBotStatusParams struct {
@@ -340,6 +582,7 @@ type UpdateBotStatusRow struct {
 	Holding        pgtype.Numeric     `json:"holding"`
 	CreatedAt      pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt      pgtype.Timestamptz `json:"updated_at"`
+	StatusReason   pgtype.Text        `json:"status_reason"`
 }
 
 func (q *Queries) UpdateBotStatus(ctx context.Context, arg UpdateBotStatusParams) (UpdateBotStatusRow, error) {
@@ -358,6 +601,7 @@ func (q *Queries) UpdateBotStatus(ctx context.Context, arg UpdateBotStatusParams
 		&i.Holding,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.StatusReason,
 	)
 	return i, err
 }
@@ -5,11 +5,28 @@
 package db
 
 import (
+	"encoding/json"
 	"net/netip"
 
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type BalanceAssetSnapshot struct {
+	ID               int64              `json:"id"`
+	BalanceHistoryID int32              `json:"balance_history_id"`
+	BinanceAccountID int32              `json:"binance_account_id"`
+	Market           string             `json:"market"`
+	Asset            string             `json:"asset"`
+	Free             pgtype.Numeric     `json:"free"`
+	Locked           pgtype.Numeric     `json:"locked"`
+	Borrowed         pgtype.Numeric     `json:"borrowed"`
+	Interest         pgtype.Numeric     `json:"interest"`
+	NetAsset         pgtype.Numeric     `json:"net_asset"`
+	PriceUsdt        pgtype.Numeric     `json:"price_usdt"`
+	ValueUsdt        pgtype.Numeric     `json:"value_usdt"`
+	RecordedAt       pgtype.Timestamptz `json:"recorded_at"`
+}
+
 type BalanceHistory struct {
 	ID               int32              `json:"id"`
 	BinanceAccountID int32              `json:"binance_account_id"`
@@ -17,17 +34,26 @@ type BalanceHistory struct {
 	RecordedAt       pgtype.Timestamptz `json:"recorded_at"`
 }
 
-type BinanceAccount struct {
-	ID            int32              `json:"id"`
-	UserID        int32              `json:"user_id"`
-	Name          string             `json:"name"`
-	ApiKey        string             `json:"api_key"`
-	ApiSecret     string             `json:"api_secret"`
-	BaseUrl       pgtype.Text        `json:"base_url"`
-	MarginEnabled pgtype.Bool        `json:"margin_enabled"`
-	IsActive      pgtype.Bool        `json:"is_active"`
-	CreatedAt     pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt     pgtype.Timestamptz `json:"updated_at"`
+type BalanceHistoryDaily struct {
+	BinanceAccountID int32              `json:"binance_account_id"`
+	Bucket           pgtype.Timestamptz `json:"bucket"`
+	OpenUsd          pgtype.Numeric     `json:"open_usd"`
+	HighUsd          pgtype.Numeric     `json:"high_usd"`
+	LowUsd           pgtype.Numeric     `json:"low_usd"`
+	CloseUsd         pgtype.Numeric     `json:"close_usd"`
+	Samples          int32              `json:"samples"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+}
+
+type BalanceHistoryHourly struct {
+	BinanceAccountID int32              `json:"binance_account_id"`
+	Bucket           pgtype.Timestamptz `json:"bucket"`
+	OpenUsd          pgtype.Numeric     `json:"open_usd"`
+	HighUsd          pgtype.Numeric     `json:"high_usd"`
+	LowUsd           pgtype.Numeric     `json:"low_usd"`
+	CloseUsd         pgtype.Numeric     `json:"close_usd"`
+	Samples          int32              `json:"samples"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
 }
 
 type Bot struct {
@@ -44,6 +70,115 @@ type Bot struct {
 	CreatedAt        pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
 	BinanceAccountID pgtype.Int4        `json:"binance_account_id"`
+	WebhookSecret    pgtype.Text        `json:"webhook_secret"`
+	StatusReason     pgtype.Text        `json:"status_reason"`
+	StatusChangedAt  pgtype.Timestamptz `json:"status_changed_at"`
+	StrategyParams   json.RawMessage    `json:"strategy_params"`
+	Mode             string             `json:"mode"`
+	Market           string             `json:"market"`
+}
+
+type Candle struct {
+	Symbol    string             `json:"symbol"`
+	Timeframe string             `json:"timeframe"`
+	OpenTime  pgtype.Timestamptz `json:"open_time"`
+	CloseTime pgtype.Timestamptz `json:"close_time"`
+	Open      pgtype.Numeric     `json:"open"`
+	High      pgtype.Numeric     `json:"high"`
+	Low       pgtype.Numeric     `json:"low"`
+	Close     pgtype.Numeric     `json:"close"`
+	Volume    pgtype.Numeric     `json:"volume"`
+	Trades    int64              `json:"trades"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
+type CashFlow struct {
+	ID               int64              `json:"id"`
+	BinanceAccountID int32              `json:"binance_account_id"`
+	Source           string             `json:"source"`
+	Wallet           string             `json:"wallet"`
+	ExternalID       string             `json:"external_id"`
+	Asset            string             `json:"asset"`
+	Amount           pgtype.Numeric     `json:"amount"`
+	Fee              pgtype.Numeric     `json:"fee"`
+	PriceUsdt        pgtype.Numeric     `json:"price_usdt"`
+	AmountUsdt       pgtype.Numeric     `json:"amount_usdt"`
+	OccurredAt       pgtype.Timestamptz `json:"occurred_at"`
+	CreatedAt        pgtype.Timestamptz `json:"created_at"`
+}
+
+type CashFlowSync struct {
+	BinanceAccountID int32              `json:"binance_account_id"`
+	SyncedUntil      pgtype.Timestamptz `json:"synced_until"`
+	UpdatedAt        pgtype.Timestamptz `json:"updated_at"`
+}
+
+type ExchangeAccount struct {
+	ID                 int32              `json:"id"`
+	UserID             int32              `json:"user_id"`
+	Name               string             `json:"name"`
+	ApiKey             string             `json:"api_key"`
+	ApiSecret          string             `json:"api_secret"`
+	MarginEnabled      pgtype.Bool        `json:"margin_enabled"`
+	IsActive           pgtype.Bool        `json:"is_active"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt          pgtype.Timestamptz `json:"updated_at"`
+	ApiKeyMasked       string             `json:"api_key_masked"`
+	Environment        string             `json:"environment"`
+	SpotEnabled        bool               `json:"spot_enabled"`
+	FuturesEnabled     bool               `json:"futures_enabled"`
+	IpRestricted       bool               `json:"ip_restricted"`
+	WithdrawalsEnabled bool               `json:"withdrawals_enabled"`
+	ValidatedAt        pgtype.Timestamptz `json:"validated_at"`
+	Exchange           string             `json:"exchange"`
+}
+
+type Fill struct {
+	ID              int32              `json:"id"`
+	OrderID         int32              `json:"order_id"`
+	TradeID         int64              `json:"trade_id"`
+	Price           pgtype.Numeric     `json:"price"`
+	Quantity        pgtype.Numeric     `json:"quantity"`
+	Commission      pgtype.Numeric     `json:"commission"`
+	CommissionAsset string             `json:"commission_asset"`
+	FilledAt        pgtype.Timestamptz `json:"filled_at"`
+}
+
+type Order struct {
+	ID                 int32              `json:"id"`
+	BotID              pgtype.Int4        `json:"bot_id"`
+	BinanceAccountID   int32              `json:"binance_account_id"`
+	ExchangeOrderID    pgtype.Int8        `json:"exchange_order_id"`
+	ClientOrderID      string             `json:"client_order_id"`
+	Symbol             string             `json:"symbol"`
+	Market             string             `json:"market"`
+	Side               string             `json:"side"`
+	OrderType          string             `json:"order_type"`
+	Status             string             `json:"status"`
+	Price              pgtype.Numeric     `json:"price"`
+	StopPrice          pgtype.Numeric     `json:"stop_price"`
+	OrigQty            pgtype.Numeric     `json:"orig_qty"`
+	ExecutedQty        pgtype.Numeric     `json:"executed_qty"`
+	CumulativeQuoteQty pgtype.Numeric     `json:"cumulative_quote_qty"`
+	ErrorMessage       pgtype.Text        `json:"error_message"`
+	CreatedAt          pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt          pgtype.Timestamptz `json:"updated_at"`
+	Mode               string             `json:"mode"`
+}
+
+type OrderStatusHistory struct {
+	ID          int32              `json:"id"`
+	OrderID     int32              `json:"order_id"`
+	Status      string             `json:"status"`
+	ExecutedQty pgtype.Numeric     `json:"executed_qty"`
+	RecordedAt  pgtype.Timestamptz `json:"recorded_at"`
+}
+
+type PaperBalance struct {
+	BotID     int32              `json:"bot_id"`
+	Asset     string             `json:"asset"`
+	Free      pgtype.Numeric     `json:"free"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
 }
 
 type User struct {
@@ -53,6 +188,7 @@ type User struct {
 	CreatedAt    pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt    pgtype.Timestamptz `json:"updated_at"`
 	PasswordHash string             `json:"password_hash"`
+	Timezone     string             `json:"timezone"`
 }
 
 type WebhookLog struct {
@@ -55,7 +55,7 @@ func (q *Queries) DeleteUser(ctx context.Context, id int32) error {
 }
 
 const getUser = `-- name: GetUser :one
-SELECT id, name, email, created_at, updated_at
+SELECT id, name, email, created_at, updated_at, timezone
 FROM users
 WHERE id = $1
 `
@@ -66,6 +66,7 @@ type GetUserRow struct {
 	Email     string             `json:"email"`
 	CreatedAt pgtype.Timestamptz `json:"created_at"`
 	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+	Timezone  string             `json:"timezone"`
 }
 
 func (q *Queries) GetUser(ctx context.Context, id int32) (GetUserRow, error) {
@@ -77,6 +78,7 @@ func (q *Queries) GetUser(ctx context.Context, id int32) (GetUserRow, error) {
 		&i.Email,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Timezone,
 	)
 	return i, err
 }
@@ -235,3 +237,19 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) (UpdateU
 	)
 	return i, err
 }
+
+const updateUserTimezone = `-- name: UpdateUserTimezone :exec
+UPDATE users
+SET timezone = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateUserTimezoneParams struct {
+	ID       int32  `json:"id"`
+	Timezone string `json:"timezone"`
+}
+
+func (q *Queries) UpdateUserTimezone(ctx context.Context, arg UpdateUserTimezoneParams) error {
+	_, err := q.db.Exec(ctx, updateUserTimezone, arg.ID, arg.Timezone)
+	return err
+}
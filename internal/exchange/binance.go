@@ -0,0 +1,249 @@
+package exchange
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"trade/internal/binance"
+
+	"github.com/shopspring/decimal"
+)
+
+// binanceExchange is an account on Binance. Its spot, cross margin and
+// isolated margin wallets are read where the environment has them, the
+// USDⓈ-M futures wallet only when the key passed validation for futures.
+type binanceExchange struct {
+	client  *binance.Client
+	futures bool
+}
+
+func (e *binanceExchange) Name() Name {
+	return Binance
+}
+
+func (e *binanceExchange) ValidateKey() (KeyInfo, error) {
+	res, err := e.client.ValidateAccount()
+	if err != nil {
+		return KeyInfo{}, err
+	}
+
+	info := KeyInfo{
+		Valid:          res.IsValid,
+		SpotEnabled:    res.SpotEnabled,
+		MarginEnabled:  res.MarginEnabled,
+		FuturesEnabled: res.FuturesEnabled,
+		Error:          res.ErrorMessage,
+	}
+	// The testnets have no restrictions endpoint, their keys can't withdraw
+	if res.Restrictions != nil {
+		info.IPRestricted = res.Restrictions.IPRestrict
+		info.WithdrawalsEnabled = res.Restrictions.EnableWithdrawals
+	}
+
+	return info, nil
+}
+
+// hasMargin reports whether the environment has the margin API, the testnets don't
+func (e *binanceExchange) hasMargin() bool {
+	env := e.client.Environment
+	return env.HasSpot() && !env.IsTestnet()
+}
+
+func (e *binanceExchange) Balances() ([]Balance, error) {
+	var balances []Balance
+
+	if e.client.Environment.HasSpot() {
+		account, err := e.client.GetAccountInfo()
+		if err != nil {
+			return nil, fmt.Errorf("error getting spot account: %w", err)
+		}
+
+		for _, b := range account.Balances {
+			free, locked := parseDecimal(b.Free), parseDecimal(b.Locked)
+			if free.IsZero() && locked.IsZero() {
+				continue
+			}
+			balances = append(balances, Balance{Market: MarketSpot, Asset: b.Asset, Free: free, Locked: locked, Net: free.Add(locked)})
+		}
+	}
+
+	if e.hasMargin() {
+		margin, err := e.client.GetMarginAccountInfo()
+		if err != nil && !noMarginAccount(err) {
+			return nil, fmt.Errorf("error getting margin account: %w", err)
+		}
+
+		for _, a := range margin.UserAssets {
+			if b, ok := marginBalance(MarketMargin, a.Asset, a.Free, a.Locked, a.Borrowed, a.Interest, a.NetAsset); ok {
+				balances = append(balances, b)
+			}
+		}
+
+		isolated, err := e.client.GetIsolatedMarginAccount()
+		if err != nil && !noMarginAccount(err) {
+			return nil, fmt.Errorf("error getting isolated margin account: %w", err)
+		}
+
+		for _, pair := range isolated.Assets {
+			for _, a := range []binance.IsolatedMarginAsset{pair.BaseAsset, pair.QuoteAsset} {
+				b, ok := marginBalance(MarketIsolated, a.Asset, a.Free, a.Locked, a.Borrowed, a.Interest, a.NetAsset)
+				if !ok {
+					continue
+				}
+				b.Symbol = pair.Symbol
+				b.LiquidationPrice = parseDecimal(pair.LiquidatePrice)
+				balances = append(balances, b)
+			}
+		}
+	}
+
+	if e.futures {
+		futures, err := e.client.GetFuturesAccount()
+		if err != nil {
+			return nil, fmt.Errorf("error getting futures account: %w", err)
+		}
+
+		for _, a := range futures.Assets {
+			wallet, marginBalance := parseDecimal(a.WalletBalance), parseDecimal(a.MarginBalance)
+			if wallet.IsZero() && marginBalance.IsZero() {
+				continue
+			}
+			balances = append(balances, Balance{Market: MarketFutures, Asset: a.Asset, Free: wallet, Net: marginBalance})
+		}
+	}
+
+	return balances, nil
+}
+
+func marginBalance(market Market, asset, free, locked, borrowed, interest, net string) (Balance, bool) {
+	b := Balance{
+		Market:   market,
+		Asset:    asset,
+		Free:     parseDecimal(free),
+		Locked:   parseDecimal(locked),
+		Borrowed: parseDecimal(borrowed),
+		Interest: parseDecimal(interest),
+		Net:      parseDecimal(net),
+	}
+	if b.Free.IsZero() && b.Locked.IsZero() && b.Borrowed.IsZero() && b.Interest.IsZero() {
+		return Balance{}, false
+	}
+	return b, true
+}
+
+// NetValue is the net asset value of the margin wallets plus the futures
+// margin balance, which is the wallet balance plus unrealized PnL. Spot
+// balances are left out, as they always have been in the balance history of
+// Binance accounts.
+func (e *binanceExchange) NetValue() (decimal.Decimal, error) {
+	total := decimal.Zero
+
+	if e.hasMargin() {
+		margin, err := e.client.GetMarginAccountInfo()
+		if err != nil && !noMarginAccount(err) {
+			return decimal.Zero, fmt.Errorf("error getting margin account: %w", err)
+		}
+		if err == nil {
+			netAsset, err := decimal.NewFromString(margin.TotalNetAssetOfUSDT)
+			if err != nil {
+				return decimal.Zero, fmt.Errorf("failed to parse TotalNetAssetOfUSDT: %w", err)
+			}
+			total = total.Add(netAsset)
+		}
+
+		isolated, err := e.client.GetIsolatedMarginAccount()
+		if err != nil && !noMarginAccount(err) {
+			return decimal.Zero, fmt.Errorf("error getting isolated margin account: %w", err)
+		}
+		if err == nil {
+			netAsset, err := decimal.NewFromString(isolated.TotalNetAssetOfUSDT)
+			if err != nil {
+				return decimal.Zero, fmt.Errorf("failed to parse isolated TotalNetAssetOfUSDT: %w", err)
+			}
+			total = total.Add(netAsset)
+		}
+	}
+
+	if e.futures {
+		futures, err := e.client.GetFuturesAccount()
+		if err != nil {
+			return decimal.Zero, fmt.Errorf("error getting futures account: %w", err)
+		}
+
+		marginBalance, err := decimal.NewFromString(futures.TotalMarginBalance)
+		if err != nil {
+			return decimal.Zero, fmt.Errorf("failed to parse futures totalMarginBalance: %w", err)
+		}
+		total = total.Add(marginBalance)
+	}
+
+	return total, nil
+}
+
+// noMarginAccount reports whether err is Binance saying the account has no
+// margin account, which just means there is nothing in it
+func noMarginAccount(err error) bool {
+	return errors.Is(err, binance.ErrNoMarginAccount)
+}
+
+// Prices are the spot prices. An environment without a spot API has none.
+func (e *binanceExchange) Prices(symbols ...string) (map[string]decimal.Decimal, error) {
+	prices := make(map[string]decimal.Decimal)
+	if !e.client.Environment.HasSpot() {
+		return prices, nil
+	}
+
+	raw, err := e.client.GetPrices(symbols...)
+	if err != nil {
+		return nil, err
+	}
+
+	for symbol, price := range raw {
+		if p, err := decimal.NewFromString(price); err == nil {
+			prices[symbol] = p
+		}
+	}
+
+	return prices, nil
+}
+
+// Positions are the USDⓈ-M futures positions
+func (e *binanceExchange) Positions() ([]Position, error) {
+	if !e.futures {
+		return nil, nil
+	}
+
+	risks, err := e.client.GetFuturesPositions("")
+	if err != nil {
+		return nil, fmt.Errorf("error getting futures positions: %w", err)
+	}
+
+	var positions []Position
+	for _, p := range risks {
+		amount := parseDecimal(p.PositionAmt)
+		if amount.IsZero() {
+			continue
+		}
+
+		side := "LONG"
+		if p.PositionSide == binance.PositionSideShort || amount.IsNegative() {
+			side = "SHORT"
+		}
+		leverage, _ := strconv.Atoi(p.Leverage)
+
+		positions = append(positions, Position{
+			Market:           MarketFutures,
+			Symbol:           p.Symbol,
+			Side:             side,
+			Quantity:         amount.Abs(),
+			EntryPrice:       parseDecimal(p.EntryPrice),
+			MarkPrice:        parseDecimal(p.MarkPrice),
+			UnrealizedPnl:    parseDecimal(p.UnRealizedProfit),
+			Leverage:         leverage,
+			LiquidationPrice: parseDecimal(p.LiquidationPrice),
+		})
+	}
+
+	return positions, nil
+}
@@ -0,0 +1,154 @@
+package exchange
+
+import (
+	"errors"
+	"fmt"
+
+	"trade/internal/bybit"
+
+	"github.com/shopspring/decimal"
+)
+
+// bybitExchange is a unified trading account on Bybit. Its one wallet holds
+// spot and derivatives alike and is reported as the spot market, positions
+// are the USDT perpetuals.
+type bybitExchange struct {
+	client *bybit.Client
+}
+
+func (e *bybitExchange) Name() Name {
+	return Bybit
+}
+
+func (e *bybitExchange) ValidateKey() (KeyInfo, error) {
+	key, err := e.client.GetAPIKeyInfo()
+	if err != nil {
+		if errors.Is(err, bybit.ErrUnauthorized) || errors.Is(err, bybit.ErrForbidden) || errors.Is(err, bybit.ErrInvalidSignature) {
+			return KeyInfo{Error: fmt.Sprintf("API key check failed: %s", err.Error())}, nil
+		}
+		return KeyInfo{}, fmt.Errorf("error validating key: %w", err)
+	}
+
+	if key.Unified != 1 {
+		return KeyInfo{Error: "Only unified trading accounts are supported"}, nil
+	}
+
+	canTrade := key.ReadOnly == 0
+	return KeyInfo{
+		Valid:              true,
+		SpotEnabled:        canTrade && key.Can("Spot", "SpotTrade"),
+		FuturesEnabled:     canTrade && key.Can("ContractTrade", "Order"),
+		IPRestricted:       key.IPRestricted(),
+		WithdrawalsEnabled: key.Can("Wallet", "Withdraw"),
+	}, nil
+}
+
+func (e *bybitExchange) Balances() ([]Balance, error) {
+	wallet, err := e.client.GetWallet()
+	if err != nil {
+		return nil, fmt.Errorf("error getting wallet: %w", err)
+	}
+
+	var balances []Balance
+	for _, c := range wallet.Coins {
+		total, locked := parseDecimal(c.WalletBalance), parseDecimal(c.Locked)
+		borrowed, interest := parseDecimal(c.BorrowAmount), parseDecimal(c.AccruedInterest)
+		if total.IsZero() && borrowed.IsZero() && interest.IsZero() {
+			continue
+		}
+
+		balances = append(balances, Balance{
+			Market:   MarketSpot,
+			Asset:    c.Coin,
+			Free:     total.Sub(locked),
+			Locked:   locked,
+			Borrowed: borrowed,
+			Interest: interest,
+			Net:      parseDecimal(c.Equity),
+		})
+	}
+
+	return balances, nil
+}
+
+// NetValue is the equity of the unified account in USD, unrealized PnL
+// included. The unified account is a margin wallet, so its spot balances are
+// part of it.
+func (e *bybitExchange) NetValue() (decimal.Decimal, error) {
+	wallet, err := e.client.GetWallet()
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("error getting wallet: %w", err)
+	}
+
+	equity, err := decimal.NewFromString(wallet.TotalEquity)
+	if err != nil {
+		return decimal.Zero, fmt.Errorf("failed to parse totalEquity: %w", err)
+	}
+
+	return equity, nil
+}
+
+func (e *bybitExchange) Prices(symbols ...string) (map[string]decimal.Decimal, error) {
+	symbol := ""
+	if len(symbols) == 1 {
+		symbol = symbols[0]
+	}
+
+	tickers, err := e.client.GetTickers(bybit.CategorySpot, symbol)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[string]bool, len(symbols))
+	for _, s := range symbols {
+		wanted[s] = true
+	}
+
+	prices := make(map[string]decimal.Decimal, len(tickers))
+	for _, t := range tickers {
+		if len(wanted) > 0 && !wanted[t.Symbol] {
+			continue
+		}
+		if p, err := decimal.NewFromString(t.LastPrice); err == nil {
+			prices[t.Symbol] = p
+		}
+	}
+
+	return prices, nil
+}
+
+// Positions are the USDT perpetual positions
+func (e *bybitExchange) Positions() ([]Position, error) {
+	res, err := e.client.GetPositions(bybit.CategoryLinear, "USDT")
+	if err != nil {
+		return nil, fmt.Errorf("error getting positions: %w", err)
+	}
+
+	var positions []Position
+	for _, p := range res {
+		size := parseDecimal(p.Size)
+		if size.IsZero() || p.Side == "" {
+			continue
+		}
+
+		side := "LONG"
+		if p.Side == bybit.SideSell {
+			side = "SHORT"
+		}
+		leverage, _ := decimal.NewFromString(p.Leverage)
+
+		positions = append(positions, Position{
+			Market:           MarketFutures,
+			Symbol:           p.Symbol,
+			Side:             side,
+			Quantity:         size,
+			EntryPrice:       parseDecimal(p.AvgPrice),
+			MarkPrice:        parseDecimal(p.MarkPrice),
+			UnrealizedPnl:    parseDecimal(p.UnrealisedPnl),
+			Leverage:         int(leverage.IntPart()),
+			LiquidationPrice: parseDecimal(p.LiqPrice),
+		})
+	}
+
+	return positions, nil
+}
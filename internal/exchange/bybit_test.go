@@ -0,0 +1,132 @@
+package exchange
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"trade/internal/bybit"
+
+	"github.com/shopspring/decimal"
+)
+
+// newBybitServer serves results by path the way the v5 API wraps them, and
+// refuses requests that aren't signed
+func newBybitServer(t *testing.T, results map[string]any) *bybitExchange {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Header.Get("X-BAPI-API-KEY") != "key" || r.Header.Get("X-BAPI-SIGN") == "" {
+			json.NewEncoder(w).Encode(map[string]any{"retCode": 10003, "retMsg": "API key is invalid."})
+			return
+		}
+
+		result, ok := results[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]any{"retCode": 0, "retMsg": "OK", "result": result})
+	}))
+	t.Cleanup(server.Close)
+
+	client, err := bybit.New("key", "secret", bybit.Mainnet)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.BaseURL = server.URL
+
+	return &bybitExchange{client: client}
+}
+
+func TestBybitReads(t *testing.T) {
+	e := newBybitServer(t, map[string]any{
+		"/v5/account/wallet-balance": map[string]any{"list": []any{map[string]any{
+			"accountType": "UNIFIED",
+			"totalEquity": "1520.5",
+			"coin": []any{
+				map[string]any{"coin": "USDT", "equity": "1000", "walletBalance": "1000", "locked": "200"},
+				map[string]any{"coin": "BTC", "equity": "0.009", "walletBalance": "0.01", "locked": "", "borrowAmount": "0", "accruedInterest": "0.001"},
+				map[string]any{"coin": "ETH", "equity": "0", "walletBalance": "0", "locked": "0"},
+			},
+		}}},
+		"/v5/market/tickers": map[string]any{"list": []any{
+			map[string]any{"symbol": "BTCUSDT", "lastPrice": "60000"},
+			map[string]any{"symbol": "ETHUSDT", "lastPrice": "3000"},
+		}},
+		"/v5/position/list": map[string]any{"list": []any{
+			map[string]any{"symbol": "BTCUSDT", "side": "Sell", "size": "0.5", "avgPrice": "61000", "markPrice": "60000", "unrealisedPnl": "500", "leverage": "10", "liqPrice": "65000"},
+			map[string]any{"symbol": "ETHUSDT", "side": "", "size": "0"},
+		}},
+	})
+
+	balances, err := e.Balances()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(balances) != 2 {
+		t.Fatalf("got %d balances, want USDT and BTC: %+v", len(balances), balances)
+	}
+	if b := balances[0]; b.Asset != "USDT" || !b.Free.Equal(decimal.NewFromInt(800)) || !b.Locked.Equal(decimal.NewFromInt(200)) {
+		t.Errorf("USDT balance = %+v, want 800 free and 200 locked", b)
+	}
+	if b := balances[1]; b.Asset != "BTC" || !b.Net.Equal(decimal.RequireFromString("0.009")) || !b.Interest.Equal(decimal.RequireFromString("0.001")) {
+		t.Errorf("BTC balance = %+v, want 0.009 net with 0.001 interest", b)
+	}
+
+	value, err := e.NetValue()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !value.Equal(decimal.RequireFromString("1520.5")) {
+		t.Errorf("net value = %s, want 1520.5", value)
+	}
+
+	prices, err := e.Prices("BTCUSDT", "SOLUSDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(prices) != 1 || !prices["BTCUSDT"].Equal(decimal.NewFromInt(60000)) {
+		t.Errorf("prices = %v, want only BTCUSDT at 60000", prices)
+	}
+
+	positions, err := e.Positions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(positions) != 1 {
+		t.Fatalf("got %d positions, want the BTCUSDT short: %+v", len(positions), positions)
+	}
+	if p := positions[0]; p.Side != "SHORT" || p.Leverage != 10 || !p.Quantity.Equal(decimal.RequireFromString("0.5")) {
+		t.Errorf("position = %+v, want a 0.5 short at 10x", p)
+	}
+}
+
+func TestBybitValidateKey(t *testing.T) {
+	e := newBybitServer(t, map[string]any{
+		"/v5/user/query-api": map[string]any{
+			"readOnly":    0,
+			"ips":         []string{"*"},
+			"uta":         1,
+			"permissions": map[string][]string{"Spot": {"SpotTrade"}, "Wallet": {"AccountTransfer"}},
+		},
+	})
+
+	info, err := e.ValidateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.Valid || !info.SpotEnabled || info.FuturesEnabled || info.IPRestricted || info.WithdrawalsEnabled {
+		t.Errorf("key info = %+v, want a valid spot key without restrictions", info)
+	}
+
+	// A key Bybit refuses is invalid, not an error
+	e.client.ApiKey = "other"
+	info, err = e.ValidateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Valid || info.Error == "" {
+		t.Errorf("key info = %+v, want invalid with a reason", info)
+	}
+}
@@ -0,0 +1,228 @@
+// Package exchange puts the exchanges accounts are on behind one interface,
+// so services can validate keys and read balances, prices and positions
+// without knowing which exchange an account is on. It only covers these read
+// paths: orders, klines and cash flows go through internal/binance directly,
+// which is why bots and cash flow syncing are Binance only. The exchange
+// clients themselves live in their own packages, such as internal/binance.
+package exchange
+
+import (
+	"fmt"
+
+	"trade/internal/binance"
+	"trade/internal/bybit"
+	"trade/internal/secrets"
+
+	"github.com/shopspring/decimal"
+)
+
+// Name is the exchange of an account, as stored in exchange_accounts
+type Name string
+
+const (
+	Binance Name = "binance"
+	Bybit   Name = "bybit"
+)
+
+// Names lists the supported exchanges, Binance first
+func Names() []Name {
+	return []Name{Binance, Bybit}
+}
+
+func (n Name) IsValid() bool {
+	return n == Binance || n == Bybit
+}
+
+// Environments lists the environments an account on the exchange can be on,
+// mainnet first
+func (n Name) Environments() []string {
+	var envs []string
+	switch n {
+	case Binance:
+		for _, env := range binance.Environments() {
+			envs = append(envs, string(env))
+		}
+	case Bybit:
+		for _, env := range bybit.Environments() {
+			envs = append(envs, string(env))
+		}
+	}
+	return envs
+}
+
+// IsTestnet reports whether env of the exchange trades with test funds
+func (n Name) IsTestnet(env string) bool {
+	switch n {
+	case Binance:
+		return binance.Environment(env).IsTestnet()
+	case Bybit:
+		return bybit.Environment(env).IsTestnet()
+	}
+	return false
+}
+
+// Market is the wallet of an account a balance or position is in
+type Market string
+
+const (
+	MarketSpot     Market = "SPOT"
+	MarketMargin   Market = "MARGIN"
+	MarketIsolated Market = "ISOLATED"
+	MarketFutures  Market = "FUTURES"
+)
+
+type Side string
+
+const (
+	SideBuy  Side = "BUY"
+	SideSell Side = "SELL"
+)
+
+// Balance is one asset of one wallet. Net is what the asset is worth to the
+// account after its loan and interest, negative when more is owed than held.
+type Balance struct {
+	Market   Market
+	Asset    string
+	Free     decimal.Decimal
+	Locked   decimal.Decimal
+	Borrowed decimal.Decimal
+	Interest decimal.Decimal
+	Net      decimal.Decimal
+	// Isolated margin only, the pair the balance belongs to and the price
+	// it gets liquidated at
+	Symbol           string
+	LiquidationPrice decimal.Decimal
+}
+
+// Position is an open derivatives position, valued by the exchange itself
+type Position struct {
+	Market           Market
+	Symbol           string
+	Side             string // LONG or SHORT
+	Quantity         decimal.Decimal
+	EntryPrice       decimal.Decimal
+	MarkPrice        decimal.Decimal
+	UnrealizedPnl    decimal.Decimal
+	Leverage         int
+	LiquidationPrice decimal.Decimal // zero when there is none
+}
+
+// KeyInfo is what the exchange lets a key do. A key the exchange refuses is
+// not valid and has the reason in Error.
+type KeyInfo struct {
+	Valid              bool
+	SpotEnabled        bool
+	MarginEnabled      bool
+	FuturesEnabled     bool
+	IPRestricted       bool
+	WithdrawalsEnabled bool
+	Error              string
+}
+
+// Exchange is an account on an exchange, read only. Symbols are written like
+// Binance's, e.g. BTCUSDT.
+type Exchange interface {
+	Name() Name
+
+	// ValidateKey checks what the key may do. A refused key gives an invalid
+	// KeyInfo, failures that say nothing about the key are returned as errors.
+	ValidateKey() (KeyInfo, error)
+
+	// Balances returns the non-zero balances of every wallet of the account
+	Balances() ([]Balance, error)
+
+	// NetValue is the USD value of the account's margin wallets, the wallets
+	// that back borrowing and derivatives, net of loans and with unrealized
+	// PnL. Funds that sit in a wallet of their own, such as Binance spot, are
+	// left out. On Binance these are the cross and isolated margin wallets
+	// and the futures wallet, on Bybit the unified account, which holds spot
+	// balances as collateral. It is what the balance history records.
+	NetValue() (decimal.Decimal, error)
+
+	// Prices returns the last spot price by symbol, of every symbol if none
+	// are given
+	Prices(symbols ...string) (map[string]decimal.Decimal, error)
+
+	// Positions returns the open derivatives positions
+	Positions() ([]Position, error)
+}
+
+// Account is what opening an exchange takes from an exchange_accounts row
+type Account struct {
+	Exchange        Name
+	Environment     string
+	EncryptedKey    string
+	EncryptedSecret string
+	FuturesEnabled  bool
+}
+
+// New opens an account on env of the exchange with a plaintext key, as given
+// when the account is created. Its futures wallet is included if env has one.
+func New(name Name, env, key, secret string) (Exchange, error) {
+	return open(name, env, key, secret, true)
+}
+
+// Open opens a stored account. The plaintext key and secret only live in
+// the exchange client.
+func Open(acc Account) (Exchange, error) {
+	key, err := secrets.Decrypt(acc.EncryptedKey)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting api key: %w", err)
+	}
+
+	secret, err := secrets.Decrypt(acc.EncryptedSecret)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting api secret: %w", err)
+	}
+
+	return open(acc.Exchange, acc.Environment, key, secret, acc.FuturesEnabled)
+}
+
+func open(name Name, env, key, secret string, futures bool) (Exchange, error) {
+	switch name {
+	case Binance:
+		client, err := binance.NewForEnvironment(key, secret, binance.Environment(env))
+		if err != nil {
+			return nil, err
+		}
+		return &binanceExchange{client: client, futures: futures && client.Environment.FuturesURL() != ""}, nil
+	case Bybit:
+		client, err := bybit.New(key, secret, bybit.Environment(env))
+		if err != nil {
+			return nil, err
+		}
+		return &bybitExchange{client: client}, nil
+	}
+	return nil, fmt.Errorf("unknown exchange %q", name)
+}
+
+// USDTPrice returns the price of asset in USDT from a Prices result.
+// Assets without a USDT market are priced through their BTC market.
+func USDTPrice(prices map[string]decimal.Decimal, asset string) (decimal.Decimal, bool) {
+	if asset == "USDT" {
+		return decimal.NewFromInt(1), true
+	}
+
+	if price, ok := prices[asset+"USDT"]; ok {
+		return price, true
+	}
+
+	inBtc, ok := prices[asset+"BTC"]
+	if !ok {
+		return decimal.Zero, false
+	}
+
+	btcPrice, ok := prices["BTCUSDT"]
+	if !ok {
+		return decimal.Zero, false
+	}
+
+	return inBtc.Mul(btcPrice), true
+}
+
+// parseDecimal reads a number as the exchanges send it, where an empty
+// string means zero
+func parseDecimal(s string) decimal.Decimal {
+	d, _ := decimal.NewFromString(s)
+	return d
+}
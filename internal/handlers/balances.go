@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"trade/internal/database"
+	db "trade/internal/db/sqlc"
+	"trade/internal/exchange"
+	"trade/internal/stats"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// maxHistoryPoints caps the grid of a balance history response
+const maxHistoryPoints = 10000
+
+// balanceResolution is a supported step of /api/balances/history and the
+// range served when the request has no from
+type balanceResolution struct {
+	step         time.Duration
+	defaultRange time.Duration
+}
+
+var balanceResolutions = map[string]balanceResolution{
+	"1m": {step: time.Minute, defaultRange: 24 * time.Hour},
+	"1h": {step: time.Hour, defaultRange: 30 * 24 * time.Hour},
+	"1d": {step: 24 * time.Hour, defaultRange: 365 * 24 * time.Hour},
+}
+
+type AccountEquity struct {
+	AccountID int32               `json:"account_id"`
+	Name      string              `json:"name"`
+	Points    []stats.EquityPoint `json:"points"`
+}
+
+type BalanceHistory struct {
+	From       time.Time           `json:"from"`
+	To         time.Time           `json:"to"`
+	Resolution string              `json:"resolution"`
+	Accounts   []AccountEquity     `json:"accounts"`
+	Total      []stats.EquityPoint `json:"total"`
+}
+
+// GetBalanceHistory returns the equity curve of every active account of the
+// user and the sum of the mainnet accounts, between from and to (RFC3339) at
+// resolution 1m, 1h or 1d. 1m reads the raw snapshots, 1h and 1d the rollups.
+// Gaps are filled forward.
+func (h *UserHandlers) GetBalanceHistory(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, ok := ctx.Value("userID").(int32)
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	query := r.URL.Query()
+
+	resolutionName := query.Get("resolution")
+	if resolutionName == "" {
+		resolutionName = "1h"
+	}
+	resolution, ok := balanceResolutions[resolutionName]
+	if !ok {
+		http.Error(w, "Invalid resolution, use 1m, 1h or 1d", http.StatusBadRequest)
+		return
+	}
+
+	to := time.Now()
+	if toStr := query.Get("to"); toStr != "" {
+		t, err := time.Parse(time.RFC3339, toStr)
+		if err != nil {
+			http.Error(w, "Invalid to, use RFC3339", http.StatusBadRequest)
+			return
+		}
+		to = t
+	}
+
+	from := to.Add(-resolution.defaultRange)
+	if fromStr := query.Get("from"); fromStr != "" {
+		t, err := time.Parse(time.RFC3339, fromStr)
+		if err != nil {
+			http.Error(w, "Invalid from, use RFC3339", http.StatusBadRequest)
+			return
+		}
+		from = t
+	}
+
+	if !from.Before(to) {
+		http.Error(w, "from must be before to", http.StatusBadRequest)
+		return
+	}
+
+	if to.Sub(from)/resolution.step > maxHistoryPoints {
+		http.Error(w, "Range too large for resolution", http.StatusBadRequest)
+		return
+	}
+
+	accounts, err := h.db.Queries.GetUserBinanceAccounts(ctx, userID)
+	if err != nil {
+		http.Error(w, "Error getting accounts", http.StatusInternalServerError)
+		return
+	}
+
+	history := BalanceHistory{
+		From:       from,
+		To:         to,
+		Resolution: resolutionName,
+		Accounts:   []AccountEquity{},
+		Total:      []stats.EquityPoint{},
+	}
+
+	var balances []stats.AccountBalance
+
+	for _, acc := range accounts {
+		curve, err := h.accountEquity(ctx, acc.ID, resolutionName, from, to)
+		if err != nil {
+			http.Error(w, "Error getting balance history", http.StatusInternalServerError)
+			return
+		}
+
+		points := stats.FillForward(curve, from, to, resolution.step)
+		if points == nil {
+			points = []stats.EquityPoint{}
+		}
+
+		// Test funds are shown per account but not added to the total
+		if !exchange.Name(acc.Exchange).IsTestnet(acc.Environment) {
+			for _, p := range points {
+				balances = append(balances, stats.AccountBalance{AccountID: acc.ID, Time: p.Time, Balance: p.Equity})
+			}
+		}
+
+		history.Accounts = append(history.Accounts, AccountEquity{
+			AccountID: acc.ID,
+			Name:      acc.Name,
+			Points:    points,
+		})
+	}
+
+	// Every account is already on the same grid, so summing per timestamp
+	// gives the aggregated curve
+	if total := stats.EquityCurve(balances); total != nil {
+		history.Total = total
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(history)
+}
+
+// accountEquity reads the balances of one account at the given resolution,
+// starting with the last one at or before from. A rollup bucket is placed at
+// its close, the start of the next bucket, like everywhere else balances are
+// read from the rollups.
+func (h *UserHandlers) accountEquity(ctx context.Context, accountID int32, resolution string, from, to time.Time) ([]stats.EquityPoint, error) {
+	fromTime := pgtype.Timestamptz{Time: from, Valid: true}
+	toTime := pgtype.Timestamptz{Time: to, Valid: true}
+	// The last bucket closed by from started a bucket earlier
+	bucketsFrom := pgtype.Timestamptz{Time: from.Add(-balanceResolutions[resolution].step), Valid: true}
+
+	var curve []stats.EquityPoint
+	add := func(t time.Time, balance pgtype.Numeric) {
+		curve = append(curve, stats.EquityPoint{Time: t, Equity: database.Decimal(balance)})
+	}
+
+	switch resolution {
+	case "1m":
+		rows, err := h.db.Queries.GetAccountBalanceHistory(ctx, db.GetAccountBalanceHistoryParams{
+			BinanceAccountID: accountID,
+			FromTime:         fromTime,
+			ToTime:           toTime,
+			RowLimit:         maxHistoryPoints + 1,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			add(row.RecordedAt.Time, row.TotalBalanceUsd)
+		}
+
+	case "1h":
+		rows, err := h.db.Queries.GetAccountHourlyBalanceHistory(ctx, db.GetAccountHourlyBalanceHistoryParams{
+			BinanceAccountID: accountID,
+			FromTime:         bucketsFrom,
+			ToTime:           toTime,
+			RowLimit:         maxHistoryPoints + 1,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			add(row.Bucket.Time.Add(time.Hour), row.CloseUsd)
+		}
+
+	case "1d":
+		rows, err := h.db.Queries.GetAccountDailyBalanceHistory(ctx, db.GetAccountDailyBalanceHistoryParams{
+			BinanceAccountID: accountID,
+			FromTime:         bucketsFrom,
+			ToTime:           toTime,
+			RowLimit:         maxHistoryPoints + 1,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			add(row.Bucket.Time.AddDate(0, 0, 1), row.CloseUsd)
+		}
+	}
+
+	return curve, nil
+}
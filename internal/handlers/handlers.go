@@ -1,21 +1,27 @@
 package handlers
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"html/template"
 	"net/http"
 	"os"
+	"slices"
 	"strconv"
 	"strings"
-	"sync"
+	"time"
 
 	"trade/internal/auth"
 	"trade/internal/database"
 	db "trade/internal/db/sqlc"
+	"trade/internal/exchange"
 	"trade/internal/middleware"
 	"trade/internal/models"
+	"trade/internal/orders"
+	"trade/internal/positions"
+	"trade/internal/secrets"
+	"trade/internal/stats"
 
 	"github.com/gorilla/mux"
 
@@ -23,68 +29,22 @@ import (
 
 	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/shopspring/decimal"
-
-	bn "github.com/adshao/go-binance/v2"
 )
 
 type UserHandlers struct {
-	db      *database.Database
-	clients map[string]*bn.Client
-	mu      *sync.RWMutex
+	db        *database.Database
+	orders    *orders.Service
+	positions *positions.Service
 }
 
 func NewUserHandler(db *database.Database) *UserHandlers {
 	return &UserHandlers{
-		db:      db,
-		clients: make(map[string]*bn.Client),
-		mu:      &sync.RWMutex{},
+		db:        db,
+		orders:    orders.New(db),
+		positions: positions.New(db),
 	}
 }
 
-func (h *UserHandlers) GetOrCreateClient(apiKey, apiSecret, name string) *bn.Client {
-	h.mu.RLock()
-	if client, exists := h.clients[name]; exists {
-		h.mu.RUnlock()
-		return client
-	}
-	h.mu.RUnlock()
-
-	h.mu.Lock()
-	defer h.mu.Unlock()
-
-	// Double-check in case another goroutine created it
-	if client, exists := h.clients[name]; exists {
-		return client
-	}
-
-	if apiKey != "" || apiSecret != "" {
-		client := bn.NewClient(apiKey, apiSecret)
-
-		h.clients[name] = client
-		return client
-	}
-
-	return nil
-}
-
-func (h *UserHandlers) CacheUserClients(ctx context.Context, userID int32) error {
-	userID, ok := ctx.Value("userID").(int32)
-	if !ok {
-		return fmt.Errorf("User ID not found in context")
-	}
-
-	accounts, err := h.db.Queries.GetUserBinanceAccounts(ctx, userID)
-	if err != nil {
-		return err
-	}
-
-	for _, account := range accounts {
-		h.GetOrCreateClient(account.ApiKey, account.ApiSecret, account.Name)
-	}
-
-	return nil
-}
-
 func (h *UserHandlers) CreateUser(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -261,8 +221,6 @@ func (h *UserHandlers) dashboardHandler(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// h.CacheUserClients(ctx, userID)
-
 	tmpl, err := template.ParseFiles("web/templates/index.html") // You'll create this
 	if err != nil {
 		http.Error(w, "Failed to parse template", http.StatusInternalServerError)
@@ -397,6 +355,56 @@ func (h UserHandlers) GetProfile(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(user)
 }
 
+// UpdateTimezone sets the IANA time zone, e.g. "Europe/Copenhagen", that the
+// user's daily, monthly and yearly returns follow
+func (h UserHandlers) UpdateTimezone(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := ctx.Value("userID").(int32)
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	var req struct {
+		Timezone string `json:"timezone"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON", http.StatusBadRequest)
+		return
+	}
+
+	// An empty name loads as UTC, which should be asked for explicitly
+	if req.Timezone == "" {
+		http.Error(w, "Timezone is required", http.StatusBadRequest)
+		return
+	}
+
+	// Local is the server's zone, not one the user can be in
+	if req.Timezone == "Local" {
+		http.Error(w, "Unknown timezone", http.StatusBadRequest)
+		return
+	}
+
+	loc, err := time.LoadLocation(req.Timezone)
+	if err != nil {
+		http.Error(w, "Unknown timezone", http.StatusBadRequest)
+		return
+	}
+
+	err = h.db.Queries.UpdateUserTimezone(ctx, db.UpdateUserTimezoneParams{
+		ID:       userID,
+		Timezone: loc.String(),
+	})
+	if err != nil {
+		http.Error(w, "Error updating timezone", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"timezone": loc.String()})
+}
+
 func (h UserHandlers) Logout(w http.ResponseWriter, r *http.Request) {
 	// Clear the authentication cookie
 	http.SetCookie(w, &http.Cookie{
@@ -420,34 +428,146 @@ func (h UserHandlers) Logout(w http.ResponseWriter, r *http.Request) {
 }
 
 // Dashboard API endpoints
+// GetDashboardMetrics computes the user's metrics from balance history, cash flows and bot fills.
+// The optional days query parameter limits the lookback window, default is all history.
+// Like returns, the balance metrics only cover Binance accounts.
 func (h UserHandlers) GetDashboardMetrics(w http.ResponseWriter, r *http.Request) {
-	// Mock dashboard metrics - in a real app, this would come from your trading system
-	metrics := map[string]any{
-		"total_pnl":      5000.00,
-		"annualized_roi": 18.5,
-		"max_drawdown":   12.3,
-		"uptime":         99.8,
-		"active_bots":    2,
-		"total_trades":   540,
-		"win_rate":       52.3,
+	type Metrics struct {
+		TotalPnl      float64   `json:"total_pnl"`
+		AnnualizedRoi float64   `json:"annualized_roi"`
+		MaxDrawdown   float64   `json:"max_drawdown"`
+		ActiveBots    int       `json:"active_bots"`
+		TotalTrades   int32     `json:"total_trades"`
+		WinRate       float64   `json:"win_rate"`
+		Since         time.Time `json:"since"`
+	}
+
+	ctx := r.Context()
+	userID, ok := ctx.Value("userID").(int32)
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	var since time.Time
+	if daysStr := r.URL.Query().Get("days"); daysStr != "" {
+		days, err := strconv.Atoi(daysStr)
+		if err != nil || days <= 0 {
+			http.Error(w, "Invalid days", http.StatusBadRequest)
+			return
+		}
+		since = time.Now().AddDate(0, 0, -days)
+	}
+
+	// Hourly buckets where they are still retained, daily buckets before that
+	balanceRows, err := h.db.Queries.GetUserHourlyBalances(ctx, db.GetUserHourlyBalancesParams{
+		UserID: userID,
+		Since:  pgtype.Timestamptz{Time: since, Valid: true},
+	})
+	if err != nil {
+		http.Error(w, "Error getting balance history from DB", http.StatusInternalServerError)
+		return
+	}
+
+	// Buckets are placed at their close, like the balance history shows them
+	balances := make([]stats.AccountBalance, 0, len(balanceRows))
+	for _, row := range balanceRows {
+		balances = append(balances, stats.AccountBalance{
+			AccountID: row.BinanceAccountID,
+			Time:      row.ClosedAt.Time,
+			Balance:   database.Decimal(row.TotalBalanceUsd),
+		})
+	}
+
+	metrics := Metrics{Since: since}
+
+	// Deposits and withdrawals are no profit or loss. PnL leaves out the net
+	// flows, ROI and drawdown are read from the curve with the flows taken out.
+	curve := stats.EquityCurve(balances)
+	if len(curve) > 0 {
+		first, last := curve[0], curve[len(curve)-1]
+
+		flows, err := h.userCashFlows(ctx, userID, first.Time, last.Time)
+		if err != nil {
+			http.Error(w, "Error getting cash flows from DB", http.StatusInternalServerError)
+			return
+		}
+		netFlows := decimal.Zero
+		for _, flow := range flows {
+			netFlows = netFlows.Add(flow.Amount)
+		}
+
+		growth := stats.GrowthCurve(curve, flows)
+		metrics.Since = first.Time
+		metrics.TotalPnl = last.Equity.Sub(first.Equity).Sub(netFlows).Round(2).InexactFloat64()
+		metrics.AnnualizedRoi = stats.AnnualizedReturn(decimal.NewFromInt(1), growth[len(growth)-1].Equity, last.Time.Sub(first.Time)).InexactFloat64()
+		metrics.MaxDrawdown = stats.MaxDrawdown(growth).InexactFloat64()
+	}
+
+	bots, err := h.db.Queries.GetUserBots(ctx, userID)
+	if err != nil {
+		http.Error(w, "Error getting bots from DB", http.StatusInternalServerError)
+		return
+	}
+	for _, bot := range bots {
+		if bot.Status.String == "RUNNING" {
+			metrics.ActiveBots++
+		}
+	}
+
+	fillRows, err := h.db.Queries.GetUserBotFills(ctx, userID)
+	if err != nil {
+		http.Error(w, "Error getting fills from DB", http.StatusInternalServerError)
+		return
+	}
+
+	// Round trips are paired per bot so bots trading the same symbol don't mix
+	botFills := make(map[int32][]stats.Fill)
+	for _, row := range fillRows {
+		botFills[row.BotID.Int32] = append(botFills[row.BotID.Int32], stats.Fill{
+			Symbol:          row.Symbol,
+			Side:            row.Side,
+			Price:           database.Decimal(row.Price),
+			Quantity:        database.Decimal(row.Quantity),
+			Commission:      database.Decimal(row.Commission),
+			CommissionAsset: row.CommissionAsset,
+			Time:            row.FilledAt.Time,
+			Step:            binance.LotStep(row.Symbol),
+		})
+	}
+
+	var trades []stats.Trade
+	for _, fills := range botFills {
+		for _, trade := range stats.RoundTrips(fills) {
+			if !trade.ClosedAt.Before(since) {
+				trades = append(trades, trade)
+			}
+		}
 	}
 
+	summary := stats.Summarize(trades)
+	metrics.TotalTrades = summary.Trades
+	metrics.WinRate = summary.WinRate.InexactFloat64()
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(metrics)
 }
 
 func (h UserHandlers) GetBotStats(w http.ResponseWriter, r *http.Request) {
 	type Stats struct {
-		ID               int32   `json:"id"`
-		Name             string  `json:"name"`
-		Status           string  `json:"status"`
-		WinRate          float64 `json:"win_rate"`
-		ProfitFactor     float64 `json:"profit_factor"`
-		Trades           int32   `json:"trades"`
-		Pnl              float64 `json:"pnl"`
-		Strategy         string  `json:"strategy"`
-		AccountName      string  `json:"account_name,omitempty"`
-		BinanceAccountID *int32  `json:"binance_account_id,omitempty"`
+		ID               int32           `json:"id"`
+		Name             string          `json:"name"`
+		Status           string          `json:"status"`
+		StatusReason     string          `json:"status_reason,omitempty"`
+		WinRate          float64         `json:"win_rate"`
+		ProfitFactor     float64         `json:"profit_factor"`
+		Trades           int32           `json:"trades"`
+		Pnl              float64         `json:"pnl"`
+		Strategy         string          `json:"strategy"`
+		StrategyParams   json.RawMessage `json:"strategy_params"`
+		Mode             string          `json:"mode"`
+		AccountName      string          `json:"account_name,omitempty"`
+		BinanceAccountID *int32          `json:"binance_account_id,omitempty"`
 	}
 
 	ctx := r.Context()
@@ -494,11 +614,14 @@ func (h UserHandlers) GetBotStats(w http.ResponseWriter, r *http.Request) {
 			ID:               bot.ID,
 			Name:             bot.Name,
 			Status:           bot.Status.String,
+			StatusReason:     bot.StatusReason.String,
 			WinRate:          winRate.Float64,
 			ProfitFactor:     profitFactor.Float64,
 			Trades:           bot.Trades.Int32,
 			Pnl:              holding.Float64 - initialHolding.Float64,
 			Strategy:         bot.Strategy,
+			StrategyParams:   bot.StrategyParams,
+			Mode:             bot.Mode,
 			AccountName:      bot.AccountName.String,
 			BinanceAccountID: &bot.BinanceAccountID.Int32,
 		}
@@ -530,6 +653,9 @@ func (h *UserHandlers) UpdateBot(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Name             string          `json:"name"`
 		Strategy         string          `json:"strategy"`
+		StrategyParams   json.RawMessage `json:"strategy_params"`
+		Mode             models.BotMode  `json:"mode"`
+		Market           orders.Market   `json:"market"`
 		InitialHolding   decimal.Decimal `json:"initial_holding"`
 		BinanceAccountID *int32          `json:"binance_account_id"`
 	}
@@ -544,6 +670,28 @@ func (h *UserHandlers) UpdateBot(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	strategyParams, err := validateStrategyParams(req.Strategy, req.StrategyParams)
+	if err != nil {
+		writeStrategyError(w, err)
+		return
+	}
+
+	if req.Mode == "" {
+		req.Mode = models.BotModeLive
+	}
+	if !req.Mode.IsValid() {
+		http.Error(w, "Invalid mode. Must be live, paper or testnet", http.StatusBadRequest)
+		return
+	}
+
+	if req.Market == "" {
+		req.Market = orders.MarketSpot
+	}
+	if err := checkMarket(req.Mode, req.Market); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	initialHolding, err := decimalToPgNumeric(req.InitialHolding)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("%v", err), http.StatusInternalServerError)
@@ -553,7 +701,7 @@ func (h *UserHandlers) UpdateBot(w http.ResponseWriter, r *http.Request) {
 	var binanceAccountID pgtype.Int4
 	if req.BinanceAccountID != nil {
 		// Validate user owns the account
-		_, err := h.db.Queries.GetBinanceAccount(ctx, db.GetBinanceAccountParams{
+		account, err := h.db.Queries.GetBinanceAccount(ctx, db.GetBinanceAccountParams{
 			ID:     *req.BinanceAccountID,
 			UserID: userID,
 		})
@@ -562,6 +710,11 @@ func (h *UserHandlers) UpdateBot(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if err := checkAccountMode(req.Mode, exchange.Name(account.Exchange), account.Environment); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
 		// Check if account is already used by another bot (excluding current bot)
 		existingBots, err := h.db.Queries.GetUserBots(ctx, userID)
 		if err != nil {
@@ -590,6 +743,9 @@ func (h *UserHandlers) UpdateBot(w http.ResponseWriter, r *http.Request) {
 		UserID:           userID,
 		Name:             req.Name,
 		Strategy:         req.Strategy,
+		StrategyParams:   strategyParams,
+		Mode:             string(req.Mode),
+		Market:           string(req.Market),
 		InitialHolding:   initialHolding,
 		BinanceAccountID: binanceAccountID,
 	}
@@ -604,47 +760,133 @@ func (h *UserHandlers) UpdateBot(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Leaving paper mode discards the virtual balances, the next paper run
+	// starts over from the initial holding
+	if models.BotMode(bot.Mode) != models.BotModePaper {
+		if err := h.db.Queries.DeletePaperBalances(ctx, bot.ID); err != nil {
+			fmt.Printf("Failed to reset paper balances of bot %d: %v\n", bot.ID, err)
+		}
+	}
+
+	// Stats only count the fills of the current mode, and the holding
+	// follows the initial holding
+	if err := h.orders.RefreshBotStats(ctx, bot.ID); err != nil {
+		fmt.Printf("Failed to refresh stats of bot %d: %v\n", bot.ID, err)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(bot)
 }
 
+// checkAccountMode makes sure live bots trade on a real account and testnet
+// bots on a testnet one. Bots trade spot, so the account needs a spot API.
+// Paper bots only read prices, any spot account will do. The bot runner and
+// the webhook trade through the Binance client, so bots need Binance accounts.
+func checkAccountMode(mode models.BotMode, name exchange.Name, environment string) error {
+	env := binance.Environment(environment)
+	switch {
+	case name != exchange.Binance:
+		return fmt.Errorf("Bots can only trade on Binance accounts, not on %s", name)
+	case !env.HasSpot():
+		return errors.New("Account has no spot API, bots need a mainnet or spot testnet account")
+	case mode == models.BotModeLive && env.IsTestnet():
+		return errors.New("Account is on the Binance testnet, use testnet mode")
+	case mode == models.BotModeTestnet && !env.IsTestnet():
+		return errors.New("Testnet mode needs an account on the Binance testnet")
+	}
+	return nil
+}
+
+// checkMarket makes sure the bot trades a market it can. Paper mode only
+// simulates spot and the testnets have no margin API, so margin bots are live.
+func checkMarket(mode models.BotMode, market orders.Market) error {
+	switch {
+	case !market.Tradable():
+		return errors.New("Invalid market. Must be SPOT, MARGIN or ISOLATED")
+	case market != orders.MarketSpot && mode != models.BotModeLive:
+		return fmt.Errorf("%s bots must be live, paper and testnet mode only trade spot", market)
+	}
+	return nil
+}
+
+// parseExchange reads the exchange of an account request, Binance when empty
+func parseExchange(s string) (exchange.Name, error) {
+	if s == "" {
+		return exchange.Binance, nil
+	}
+
+	name := exchange.Name(s)
+	if !name.IsValid() {
+		return "", fmt.Errorf("Invalid exchange, must be one of %v", exchange.Names())
+	}
+	return name, nil
+}
+
+// parseEnvironment reads the environment of an account request on the
+// exchange, mainnet when empty
+func parseEnvironment(name exchange.Name, s string) (string, error) {
+	if s == "" {
+		return "mainnet", nil
+	}
+
+	if !slices.Contains(name.Environments(), s) {
+		return "", fmt.Errorf("Invalid environment, must be one of %v", name.Environments())
+	}
+	return s, nil
+}
+
+var (
+	errKeyRefused         = errors.New("The exchange refused the API key")
+	errWithdrawalsEnabled = errors.New("API keys with withdrawals enabled are not accepted, disable withdrawals for the key on the exchange")
+)
+
+// validateKeys checks the account's keys against the exchange before they
+// are stored. Keys that can withdraw are refused, a leaked key must never be
+// able to move funds off the account.
+func validateKeys(ex exchange.Exchange) (exchange.KeyInfo, error) {
+	info, err := ex.ValidateKey()
+	switch {
+	case err != nil:
+		return info, err
+	case !info.Valid:
+		return info, fmt.Errorf("%w: %s", errKeyRefused, info.Error)
+	case info.WithdrawalsEnabled:
+		return info, errWithdrawalsEnabled
+	}
+	return info, nil
+}
+
+// writeKeyError answers an account request whose keys failed validation.
+// Refused keys are the client's fault, the exchange being unreachable is not.
+func writeKeyError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errKeyRefused) || errors.Is(err, errWithdrawalsEnabled) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	http.Error(w, fmt.Sprintf("Could not validate the keys with the exchange: %v", err), http.StatusBadGateway)
+}
+
+// GetPositions lists the open positions on the user's active accounts
 func (h UserHandlers) GetPositions(w http.ResponseWriter, r *http.Request) {
-	// Mock open positions
-	positions := []map[string]any{
-		{
-			"trade_id": "#25678",
-			"bot":      "Alpha1",
-			"position": "LONG",
-			"entry":    "20000",
-			"current":  "22000",
-			"pnl":      2000,
-			"time":     "2h 45m",
-			"symbol":   "BTC/USD",
-		},
-		{
-			"trade_id": "#25679",
-			"bot":      "Gamma3",
-			"position": "SHORT",
-			"entry":    "1850",
-			"current":  "1820",
-			"pnl":      300,
-			"time":     "1h 15m",
-			"symbol":   "ETH/USD",
-		},
-		{
-			"trade_id": "#25680",
-			"bot":      "Alpha1",
-			"position": "LONG",
-			"entry":    "0.52",
-			"current":  "0.48",
-			"pnl":      -400,
-			"time":     "3h 20m",
-			"symbol":   "ADA/USD",
-		},
+	ctx := r.Context()
+	userID, ok := ctx.Value("userID").(int32)
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	openPositions, err := h.positions.ForUser(ctx, userID)
+	if err != nil {
+		if openPositions == nil {
+			http.Error(w, "Error getting positions", http.StatusInternalServerError)
+			return
+		}
+		// Some accounts couldn't be read, show the rest
+		fmt.Printf("Failed to get positions for some accounts: %v\n", err)
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(positions)
+	json.NewEncoder(w).Encode(openPositions)
 }
 
 func (h *UserHandlers) CreateBot(w http.ResponseWriter, r *http.Request) {
@@ -653,6 +895,9 @@ func (h *UserHandlers) CreateBot(w http.ResponseWriter, r *http.Request) {
 	var req struct {
 		Name             string          `json:"name"`
 		Strategy         string          `json:"strategy"`
+		StrategyParams   json.RawMessage `json:"strategy_params"`
+		Mode             models.BotMode  `json:"mode"`
+		Market           orders.Market   `json:"market"`
 		InitialHolding   decimal.Decimal `json:"initial_holding"`
 		BinanceAccountID *int32          `json:"binance_account_id,omitempty"`
 	}
@@ -673,6 +918,28 @@ func (h *UserHandlers) CreateBot(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	strategyParams, err := validateStrategyParams(req.Strategy, req.StrategyParams)
+	if err != nil {
+		writeStrategyError(w, err)
+		return
+	}
+
+	if req.Mode == "" {
+		req.Mode = models.BotModeLive
+	}
+	if !req.Mode.IsValid() {
+		http.Error(w, "Invalid mode. Must be live, paper or testnet", http.StatusBadRequest)
+		return
+	}
+
+	if req.Market == "" {
+		req.Market = orders.MarketSpot
+	}
+	if err := checkMarket(req.Mode, req.Market); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	initialHolding, err := decimalToPgNumeric(req.InitialHolding)
 	if err != nil {
 		http.Error(w, fmt.Sprintf("%v", err), http.StatusInternalServerError)
@@ -682,7 +949,7 @@ func (h *UserHandlers) CreateBot(w http.ResponseWriter, r *http.Request) {
 	var binanceAccountID pgtype.Int4
 	if req.BinanceAccountID != nil {
 		// Check if account is owned by user
-		_, err := h.db.Queries.GetBinanceAccount(ctx, db.GetBinanceAccountParams{
+		account, err := h.db.Queries.GetBinanceAccount(ctx, db.GetBinanceAccountParams{
 			ID:     *req.BinanceAccountID,
 			UserID: UserID,
 		})
@@ -691,6 +958,11 @@ func (h *UserHandlers) CreateBot(w http.ResponseWriter, r *http.Request) {
 			return
 		}
 
+		if err := checkAccountMode(req.Mode, exchange.Name(account.Exchange), account.Environment); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
 		existingBots, err := h.db.Queries.GetUserBots(ctx, UserID)
 		if err != nil {
 			http.Error(w, "Error checking existing bots", http.StatusInternalServerError)
@@ -709,12 +981,22 @@ func (h *UserHandlers) CreateBot(w http.ResponseWriter, r *http.Request) {
 		binanceAccountID = pgtype.Int4{Valid: false}
 	}
 
+	webhookSecret, err := auth.GenerateWebhookSecret()
+	if err != nil {
+		http.Error(w, "Error generating webhook secret", http.StatusInternalServerError)
+		return
+	}
+
 	params := db.CreateBotParams{
 		UserID:           UserID,
 		Name:             req.Name,
 		Strategy:         req.Strategy,
+		StrategyParams:   strategyParams,
+		Mode:             string(req.Mode),
+		Market:           string(req.Market),
 		InitialHolding:   initialHolding,
 		BinanceAccountID: binanceAccountID,
+		WebhookSecret:    pgtype.Text{String: webhookSecret, Valid: true},
 	}
 
 	res, err := h.db.Queries.CreateBot(ctx, params)
@@ -804,6 +1086,97 @@ func (h UserHandlers) UpdateBotStatus(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(bot)
 }
 
+func (h UserHandlers) GetBotOrders(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	idStr := vars["botID"]
+
+	botID, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid bot ID", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := ctx.Value("userID").(int32)
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	limit, offset := 50, 0
+	if l, err := strconv.Atoi(r.URL.Query().Get("limit")); err == nil && l > 0 && l <= 500 {
+		limit = l
+	}
+	if o, err := strconv.Atoi(r.URL.Query().Get("offset")); err == nil && o >= 0 {
+		offset = o
+	}
+
+	params := db.GetBotOrdersParams{
+		BotID:  pgtype.Int4{Int32: int32(botID), Valid: true},
+		UserID: userID,
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	}
+
+	botOrders, err := h.db.Queries.GetBotOrders(ctx, params)
+	if err != nil {
+		http.Error(w, "Error getting bot orders from DB", http.StatusInternalServerError)
+		return
+	}
+
+	if botOrders == nil {
+		botOrders = []db.Order{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(botOrders)
+}
+
+func (h UserHandlers) RotateBotWebhookSecret(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	idStr := vars["botID"]
+
+	botID, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, "Invalid bot ID", http.StatusBadRequest)
+		return
+	}
+
+	userID, ok := ctx.Value("userID").(int32)
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	webhookSecret, err := auth.GenerateWebhookSecret()
+	if err != nil {
+		http.Error(w, "Error generating webhook secret", http.StatusInternalServerError)
+		return
+	}
+
+	params := db.RotateBotWebhookSecretParams{
+		ID:            int32(botID),
+		UserID:        userID,
+		WebhookSecret: pgtype.Text{String: webhookSecret, Valid: true},
+	}
+
+	bot, err := h.db.Queries.RotateBotWebhookSecret(ctx, params)
+	if err != nil {
+		if strings.Contains(err.Error(), "no rows") {
+			http.Error(w, "Bot not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Failed to rotate webhook secret", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(bot)
+}
+
 func (h UserHandlers) DeleteBot(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -843,7 +1216,7 @@ func (h UserHandlers) DeleteBot(w http.ResponseWriter, r *http.Request) {
 func (h *UserHandlers) TestBinance(w http.ResponseWriter, r *http.Request) {
 	key := os.Getenv("TEST_API_KEY")
 	secret := os.Getenv("TEST_API_SECRET")
-	client, err := binance.New(key, secret, "https://testnet.binance.vision")
+	client, err := binance.NewForEnvironment(key, secret, binance.SpotTestnet)
 	if err != nil {
 		http.Error(w, "error creating client", http.StatusInternalServerError)
 		return
@@ -852,6 +1225,7 @@ func (h *UserHandlers) TestBinance(w http.ResponseWriter, r *http.Request) {
 	price, err := client.GetPrice("BTCUSDT")
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -879,23 +1253,47 @@ func (h *UserHandlers) GetAccountInfo(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *UserHandlers) GetMarginAccountInfo(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := ctx.Value("userID").(int32)
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
 	var req struct {
-		Key    string `json:"key"`
-		Secret string `json:"secret"`
+		AccountID int32 `json:"account_id"`
 	}
 
-	// TODO: Key and Secret needs to be secured
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid JSON", http.StatusBadRequest)
 		return
 	}
 
-	if req.Key == "" || req.Secret == "" {
-		http.Error(w, "API key and secret are required", http.StatusBadRequest)
+	if req.AccountID == 0 {
+		http.Error(w, "Account ID is required", http.StatusBadRequest)
+		return
+	}
+
+	acc, err := h.db.Queries.GetBinanceAccount(ctx, db.GetBinanceAccountParams{
+		ID:     req.AccountID,
+		UserID: userID,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "no rows") {
+			http.Error(w, "Account not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Error getting the account from db", http.StatusInternalServerError)
+		return
+	}
+
+	if exchange.Name(acc.Exchange) != exchange.Binance {
+		http.Error(w, "Margin accounts are only available on Binance", http.StatusBadRequest)
 		return
 	}
 
-	client, err := binance.New(req.Key, req.Secret, "")
+	client, err := binance.NewFromEncrypted(acc.ApiKey, acc.ApiSecret, binance.Environment(acc.Environment))
 	if err != nil {
 		http.Error(w, "Failed to create client", http.StatusInternalServerError)
 		return
@@ -932,8 +1330,8 @@ func (h *UserHandlers) UpdateBinanceAccount(w http.ResponseWriter, r *http.Reque
 	}
 
 	var req struct {
-		Name    string `json:"name"`
-		BaseURL string `json:"base_url"`
+		Name        string `json:"name"`
+		Environment string `json:"environment"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -957,21 +1355,57 @@ func (h *UserHandlers) UpdateBinanceAccount(w http.ResponseWriter, r *http.Reque
 	}
 
 	updateParams := db.UpdateBinanceAccountInfoParams{
-		ID:      int32(accID),
-		UserID:  userID,
-		Name:    acc.Name,
-		BaseUrl: acc.BaseUrl,
+		ID:          int32(accID),
+		UserID:      userID,
+		Name:        acc.Name,
+		Environment: acc.Environment,
 	}
 
 	if req.Name != "" {
 		updateParams.Name = req.Name
 	}
 
-	if req.BaseURL != "" {
-		updateParams.BaseUrl = pgtype.Text{String: req.BaseURL, Valid: true}
+	if req.Environment != "" {
+		env, err := parseEnvironment(exchange.Name(acc.Exchange), req.Environment)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		updateParams.Environment = env
+	}
+
+	// The keys belong to one environment, moving the account means
+	// validating them again. The exchange of an account never changes.
+	var validation *exchange.KeyInfo
+	if updateParams.Environment != acc.Environment {
+		ex, err := exchange.Open(exchange.Account{
+			Exchange:        exchange.Name(acc.Exchange),
+			Environment:     updateParams.Environment,
+			EncryptedKey:    acc.ApiKey,
+			EncryptedSecret: acc.ApiSecret,
+		})
+		if err != nil {
+			http.Error(w, "Failed to create client", http.StatusInternalServerError)
+			return
+		}
+
+		info, err := validateKeys(ex)
+		if err != nil {
+			writeKeyError(w, err)
+			return
+		}
+		validation = &info
+	}
+
+	tx, err := h.db.DBPool.Begin(ctx)
+	if err != nil {
+		http.Error(w, "Error updating the account in db", http.StatusInternalServerError)
+		return
 	}
+	defer tx.Rollback(ctx)
 
-	updatedAcc, err := h.db.Queries.UpdateBinanceAccountInfo(ctx, updateParams)
+	qtx := h.db.Queries.WithTx(tx)
+	updatedAcc, err := qtx.UpdateBinanceAccountInfo(ctx, updateParams)
 	if err != nil {
 		if strings.Contains(err.Error(), "duplicate key") {
 			http.Error(w, "Account name already exists", http.StatusConflict)
@@ -981,6 +1415,26 @@ func (h *UserHandlers) UpdateBinanceAccount(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	if validation != nil {
+		err = qtx.SetBinanceAccountCapabilities(ctx, db.SetBinanceAccountCapabilitiesParams{
+			ID:                 updatedAcc.ID,
+			SpotEnabled:        validation.SpotEnabled,
+			MarginEnabled:      pgtype.Bool{Bool: validation.MarginEnabled, Valid: true},
+			FuturesEnabled:     validation.FuturesEnabled,
+			IpRestricted:       validation.IPRestricted,
+			WithdrawalsEnabled: validation.WithdrawalsEnabled,
+		})
+		if err != nil {
+			http.Error(w, "Error storing the account capabilities", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		http.Error(w, "Error updating the account in db", http.StatusInternalServerError)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(updatedAcc)
 }
@@ -989,10 +1443,11 @@ func (h *UserHandlers) CreateBinanceAccount(w http.ResponseWriter, r *http.Reque
 	ctx := r.Context()
 
 	var req struct {
-		Name      string `json:"name"`
-		ApiKey    string `json:"api_key"`
-		ApiSecret string `json:"api_secret"`
-		BaseURL   string `json:"base_url"`
+		Name        string `json:"name"`
+		ApiKey      string `json:"api_key"`
+		ApiSecret   string `json:"api_secret"`
+		Exchange    string `json:"exchange"`
+		Environment string `json:"environment"`
 	}
 
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -1006,6 +1461,47 @@ func (h *UserHandlers) CreateBinanceAccount(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	if req.Name == "" || req.ApiKey == "" || req.ApiSecret == "" {
+		http.Error(w, "Name, API key and secret are required", http.StatusBadRequest)
+		return
+	}
+
+	name, err := parseExchange(req.Exchange)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	env, err := parseEnvironment(name, req.Environment)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	ex, err := exchange.New(name, env, req.ApiKey, req.ApiSecret)
+	if err != nil {
+		http.Error(w, "Failed to create client", http.StatusInternalServerError)
+		return
+	}
+
+	validation, err := validateKeys(ex)
+	if err != nil {
+		writeKeyError(w, err)
+		return
+	}
+
+	encryptedKey, err := secrets.Encrypt(req.ApiKey)
+	if err != nil {
+		http.Error(w, "Error encrypting API key", http.StatusInternalServerError)
+		return
+	}
+
+	encryptedSecret, err := secrets.Encrypt(req.ApiSecret)
+	if err != nil {
+		http.Error(w, "Error encrypting API secret", http.StatusInternalServerError)
+		return
+	}
+
 	existingAccount, err := h.db.Queries.GetInactiveBinanceAccount(ctx, db.GetInactiveBinanceAccountParams{
 		UserID: userID,
 		Name:   req.Name,
@@ -1014,11 +1510,18 @@ func (h *UserHandlers) CreateBinanceAccount(w http.ResponseWriter, r *http.Reque
 	if err == nil {
 		// Account exists but is inactive - reactivate it
 		updatedAccount, err := h.db.Queries.ReactivateBinanceAccount(ctx, db.ReactivateBinanceAccountParams{
-			ID:        existingAccount.ID,
-			UserID:    userID,
-			ApiKey:    req.ApiKey,
-			ApiSecret: req.ApiSecret,
-			BaseUrl:   pgtype.Text{String: req.BaseURL, Valid: true},
+			ID:                 existingAccount.ID,
+			UserID:             userID,
+			ApiKey:             encryptedKey,
+			ApiSecret:          encryptedSecret,
+			ApiKeyMasked:       secrets.Mask(req.ApiKey),
+			Environment:        env,
+			SpotEnabled:        validation.SpotEnabled,
+			MarginEnabled:      pgtype.Bool{Bool: validation.MarginEnabled, Valid: true},
+			FuturesEnabled:     validation.FuturesEnabled,
+			IpRestricted:       validation.IPRestricted,
+			WithdrawalsEnabled: validation.WithdrawalsEnabled,
+			Exchange:           string(name),
 		})
 		if err != nil {
 			http.Error(w, "Failed to reactivate account", http.StatusInternalServerError)
@@ -1030,11 +1533,18 @@ func (h *UserHandlers) CreateBinanceAccount(w http.ResponseWriter, r *http.Reque
 	}
 
 	params := db.CreateBinanceAccountParams{
-		UserID:    userID,
-		Name:      req.Name,
-		ApiKey:    req.ApiKey,
-		ApiSecret: req.ApiSecret,
-		BaseUrl:   pgtype.Text{String: req.BaseURL, Valid: true},
+		UserID:             userID,
+		Name:               req.Name,
+		ApiKey:             encryptedKey,
+		ApiSecret:          encryptedSecret,
+		ApiKeyMasked:       secrets.Mask(req.ApiKey),
+		Environment:        env,
+		SpotEnabled:        validation.SpotEnabled,
+		MarginEnabled:      pgtype.Bool{Bool: validation.MarginEnabled, Valid: true},
+		FuturesEnabled:     validation.FuturesEnabled,
+		IpRestricted:       validation.IPRestricted,
+		WithdrawalsEnabled: validation.WithdrawalsEnabled,
+		Exchange:           string(name),
 	}
 
 	acc, err := h.db.Queries.CreateBinanceAccount(ctx, params)
@@ -1073,6 +1583,58 @@ func (h *UserHandlers) GetUserBinanceAccounts(w http.ResponseWriter, r *http.Req
 	json.NewEncoder(w).Encode(accs)
 }
 
+// GetAccountAllocation returns the per-asset value of an account over the
+// last days (default 30), as recorded by the balance snapshots
+func (h *UserHandlers) GetAccountAllocation(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	userID, ok := ctx.Value("userID").(int32)
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	accID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		http.Error(w, "Invalid account ID", http.StatusBadRequest)
+		return
+	}
+
+	days := 30
+	if d, err := strconv.Atoi(r.URL.Query().Get("days")); err == nil && d > 0 {
+		days = d
+	}
+
+	_, err = h.db.Queries.GetBinanceAccount(ctx, db.GetBinanceAccountParams{
+		ID:     int32(accID),
+		UserID: userID,
+	})
+	if err != nil {
+		if strings.Contains(err.Error(), "no rows") {
+			http.Error(w, "Account not found", http.StatusNotFound)
+			return
+		}
+		http.Error(w, "Error getting the account from db", http.StatusInternalServerError)
+		return
+	}
+
+	allocation, err := h.db.Queries.GetAccountAllocationHistory(ctx, db.GetAccountAllocationHistoryParams{
+		BinanceAccountID: int32(accID),
+		RecordedAt:       pgtype.Timestamptz{Time: time.Now().AddDate(0, 0, -days), Valid: true},
+	})
+	if err != nil {
+		http.Error(w, "Error getting allocation history", http.StatusInternalServerError)
+		return
+	}
+
+	if allocation == nil {
+		allocation = []db.GetAccountAllocationHistoryRow{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(allocation)
+}
+
 func (h *UserHandlers) DeleteBinanceAccount(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 
@@ -1113,44 +1675,6 @@ func decimalToPgNumeric(d decimal.Decimal) (pgtype.Numeric, error) {
 	return pgNum, nil
 }
 
-func (h *UserHandlers) getBalanceReturn(w http.ResponseWriter, r *http.Request, queryFunc func(context.Context, int32) (any, error), errorMsg string) {
-	ctx := r.Context()
-	userID, ok := ctx.Value("userID").(int32)
-	if !ok {
-		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
-		return
-	}
-
-	balance, err := queryFunc(ctx, userID)
-	if err != nil {
-		http.Error(w, errorMsg, http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(balance)
-}
-
-// Specific handlers using the generic function
-func (h *UserHandlers) GetPreviousMonthReturn(w http.ResponseWriter, r *http.Request) {
-	h.getBalanceReturn(w, r, h.db.Queries.GetUserTotalBalanceLatestCompleteMonth,
-		"Error getting complete month balance from db")
-}
-
-func (h *UserHandlers) GetPreviousYearReturn(w http.ResponseWriter, r *http.Request) {
-	h.getBalanceReturn(w, r, h.db.Queries.GetUserTotalBalanceEarliestInYear,
-		"Error getting complete year balance from db")
-}
-
-func (h *UserHandlers) GetPreviousDayReturn(w http.ResponseWriter, r *http.Request) {
-	h.getBalanceReturn(w, r, h.db.Queries.GetUserTotalBalanceLatestCompleteDay,
-		"Error getting complete day balance from db")
-}
-
-func (h *UserHandlers) testCache(w http.ResponseWriter, r *http.Request) {
-	h.GetOrCreateClient("", "", "main")
-}
-
 func SetupRoutes(db *database.Database) *mux.Router {
 	userHandler := NewUserHandler(db)
 	r := mux.NewRouter()
@@ -1168,7 +1692,6 @@ func SetupRoutes(db *database.Database) *mux.Router {
 	r.HandleFunc("/api/webhook", userHandler.Webhook).Methods("POST")
 
 	// Protected web pages (require authentication)
-	// r.HandleFunc("/", userHandler.testCache).Methods("GET")                 // Dashboard/home
 	r.HandleFunc("/", userHandler.dashboardHandler).Methods("GET")          // Dashboard page
 	r.HandleFunc("/dashboard", userHandler.dashboardHandler).Methods("GET") // Dashboard page
 	// r.HandleFunc("/profile", profilePageHandler).Methods("GET") // Profile page
@@ -1180,6 +1703,7 @@ func SetupRoutes(db *database.Database) *mux.Router {
 	// Protected API routes (require authentication)
 	r.HandleFunc("/api/hello", apiHelloWorld).Methods("GET") // Test API endpoint
 	r.HandleFunc("/api/profile", userHandler.GetProfile).Methods("GET")
+	r.HandleFunc("/api/profile/timezone", userHandler.UpdateTimezone).Methods("PUT")
 	r.HandleFunc("/api/users", userHandler.ListUsers).Methods("GET")
 	r.HandleFunc("/api/users/{id}", userHandler.GetUser).Methods("GET")
 	r.HandleFunc("/api/users/{id}", userHandler.UpdateUser).Methods("PUT")
@@ -1190,13 +1714,16 @@ func SetupRoutes(db *database.Database) *mux.Router {
 	r.HandleFunc("/api/dashboard/bot-stats", userHandler.GetBotStats).Methods("GET")
 	r.HandleFunc("/api/dashboard/positions", userHandler.GetPositions).Methods("GET")
 
-	r.HandleFunc("/api/dashboard/monthly-return", userHandler.GetPreviousMonthReturn).Methods("GET")
-	r.HandleFunc("/api/dashboard/yearly-return", userHandler.GetPreviousYearReturn).Methods("GET")
-	r.HandleFunc("/api/dashboard/daily-return", userHandler.GetPreviousDayReturn).Methods("GET")
+	r.HandleFunc("/api/dashboard/returns/{period}", userHandler.GetPeriodReturn).Methods("GET")
+	r.HandleFunc("/api/balances/history", userHandler.GetBalanceHistory).Methods("GET")
 	// Bot api endpoints
+	r.HandleFunc("/api/strategies", userHandler.GetStrategies).Methods("GET")
+	r.HandleFunc("/api/strategies/{name}/schema", userHandler.GetStrategySchema).Methods("GET")
 	r.HandleFunc("/api/bots", userHandler.CreateBot).Methods("POST")
 	r.HandleFunc("/api/bots", userHandler.GetUserBots).Methods("GET")
 	r.HandleFunc("/api/bots/{botID}/status", userHandler.UpdateBotStatus).Methods("PUT")
+	r.HandleFunc("/api/bots/{botID}/orders", userHandler.GetBotOrders).Methods("GET")
+	r.HandleFunc("/api/bots/{botID}/webhook-secret", userHandler.RotateBotWebhookSecret).Methods("POST")
 	r.HandleFunc("/api/bots/{botID}", userHandler.DeleteBot).Methods("DELETE")
 	r.HandleFunc("/api/bots/{botID}", userHandler.UpdateBot).Methods("PUT")
 
@@ -1206,12 +1733,9 @@ func SetupRoutes(db *database.Database) *mux.Router {
 	r.HandleFunc("/api/get-margin-account-info", userHandler.GetMarginAccountInfo).Methods("POST")
 	r.HandleFunc("/api/binance-accounts", userHandler.CreateBinanceAccount).Methods("POST")
 	r.HandleFunc("/api/binance-accounts", userHandler.GetUserBinanceAccounts).Methods("GET")
+	r.HandleFunc("/api/binance-accounts/{id}/allocation", userHandler.GetAccountAllocation).Methods("GET")
 	r.HandleFunc("/api/binance-accounts/{id}", userHandler.DeleteBinanceAccount).Methods("DELETE")
 	r.HandleFunc("/api/binance-accounts/{id}", userHandler.UpdateBinanceAccount).Methods("PUT")
 
-	// Tests
-	r.HandleFunc("/test/btb", userHandler.BnTestBiance).Methods("GET")
-	r.HandleFunc("/test/ccc", userHandler.BnGetMarginAccountInfo).Methods("GET")
-
 	return r
 }
@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"trade/internal/cashflows"
+	"trade/internal/database"
+	db "trade/internal/db/sqlc"
+	"trade/internal/stats"
+
+	"github.com/gorilla/mux"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+)
+
+type PeriodReturn struct {
+	Period        string          `json:"period"`
+	Timezone      string          `json:"timezone"`
+	Start         time.Time       `json:"start"`
+	End           time.Time       `json:"end"`
+	StartBalance  decimal.Decimal `json:"start_balance"`
+	EndBalance    decimal.Decimal `json:"end_balance"`
+	NetFlows      decimal.Decimal `json:"net_flows"`      // deposits minus withdrawals in the period, in USDT
+	Change        decimal.Decimal `json:"change"`         // end minus start balance, without net flows
+	ChangePercent decimal.Decimal `json:"change_percent"` // change relative to the start balance
+	TWR           decimal.Decimal `json:"twr"`            // time-weighted return in percent
+}
+
+// periodBounds returns the start and end of the day, month or year containing
+// now in loc. With complete it returns the previous, finished period instead.
+func periodBounds(period string, now time.Time, loc *time.Location, complete bool) (time.Time, time.Time, bool) {
+	now = now.In(loc)
+
+	var start, previous time.Time
+	switch period {
+	case "day":
+		start = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+		previous = start.AddDate(0, 0, -1)
+	case "month":
+		start = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+		previous = start.AddDate(0, -1, 0)
+	case "year":
+		start = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, loc)
+		previous = start.AddDate(-1, 0, 0)
+	default:
+		return time.Time{}, time.Time{}, false
+	}
+
+	if complete {
+		return previous, start, true
+	}
+
+	return start, now, true
+}
+
+// GetPeriodReturn returns the change of the user's total balance over the
+// current day, month or year, or the previous one with ?complete=true.
+// Period boundaries follow the user's time zone. Money moved in or out of the
+// accounts is reported as net flows and doesn't count as change.
+func (h *UserHandlers) GetPeriodReturn(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	userID, ok := ctx.Value("userID").(int32)
+	if !ok {
+		http.Error(w, "User ID not found in context", http.StatusInternalServerError)
+		return
+	}
+
+	period := mux.Vars(r)["period"]
+	complete := r.URL.Query().Get("complete") == "true"
+
+	loc, err := h.userLocation(ctx, userID)
+	if err != nil {
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+
+	start, end, ok := periodBounds(period, time.Now(), loc, complete)
+	if !ok {
+		http.Error(w, "Invalid period, use day, month or year", http.StatusBadRequest)
+		return
+	}
+
+	result, err := h.periodReturn(ctx, userID, start, end)
+	if err != nil {
+		http.Error(w, "Error calculating return", http.StatusInternalServerError)
+		return
+	}
+
+	result.Period = period
+	result.Timezone = loc.String()
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+// userLocation loads the user's time zone, UTC if the stored one is unknown
+func (h *UserHandlers) userLocation(ctx context.Context, userID int32) (*time.Location, error) {
+	user, err := h.db.Queries.GetUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	loc, err := time.LoadLocation(user.Timezone)
+	if err != nil {
+		return time.UTC, nil
+	}
+
+	return loc, nil
+}
+
+// periodReturn sums the Binance accounts of the user. Cash flows are only
+// synced for Binance, so the deposits into a Bybit account would count as
+// change and its accounts are left out.
+//
+// It reads the balances at start and end from the raw snapshots or the hourly
+// rollups, which line up with any time zone that is a whole number of hours
+// from UTC. Past the hourly retention the bucket closing at the user's
+// midnight is still kept, only a zone changed since then or one a fraction of
+// an hour from UTC falls back to the UTC days of the daily rollups.
+func (h *UserHandlers) periodReturn(ctx context.Context, userID int32, start, end time.Time) (PeriodReturn, error) {
+	result := PeriodReturn{Start: start, End: end}
+
+	startRows, err := h.db.Queries.GetUserBalancesAt(ctx, db.GetUserBalancesAtParams{
+		At:     pgtype.Timestamptz{Time: start, Valid: true},
+		UserID: userID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	endRows, err := h.db.Queries.GetUserBalancesAt(ctx, db.GetUserBalancesAtParams{
+		At:     pgtype.Timestamptz{Time: end, Valid: true},
+		UserID: userID,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	balanceRows, err := h.db.Queries.GetUserHourlyBalances(ctx, db.GetUserHourlyBalancesParams{
+		UserID: userID,
+		Since:  pgtype.Timestamptz{Time: start, Valid: true},
+	})
+	if err != nil {
+		return result, err
+	}
+
+	startBalances := make(map[int32]decimal.Decimal)
+	for _, row := range startRows {
+		startBalances[row.BinanceAccountID] = database.Decimal(row.TotalBalanceUsd)
+	}
+
+	// Buckets are placed at their close, so flows during a bucket land
+	// before its balance
+	var balances []stats.AccountBalance
+	for _, row := range balanceRows {
+		if row.ClosedAt.Time.After(end) {
+			continue
+		}
+
+		// An account added during the period starts at its first balance
+		if _, ok := startBalances[row.BinanceAccountID]; !ok {
+			startBalances[row.BinanceAccountID] = database.Decimal(row.TotalBalanceUsd)
+		}
+
+		balances = append(balances, stats.AccountBalance{
+			AccountID: row.BinanceAccountID,
+			Time:      row.ClosedAt.Time,
+			Balance:   database.Decimal(row.TotalBalanceUsd),
+		})
+	}
+
+	for _, row := range endRows {
+		balance := database.Decimal(row.TotalBalanceUsd)
+		if _, ok := startBalances[row.BinanceAccountID]; !ok {
+			startBalances[row.BinanceAccountID] = balance
+		}
+
+		result.EndBalance = result.EndBalance.Add(balance)
+		balances = append(balances, stats.AccountBalance{AccountID: row.BinanceAccountID, Time: end, Balance: balance})
+	}
+
+	for accountID, balance := range startBalances {
+		result.StartBalance = result.StartBalance.Add(balance)
+		balances = append(balances, stats.AccountBalance{AccountID: accountID, Time: start, Balance: balance})
+	}
+
+	flows, err := h.userCashFlows(ctx, userID, start, end)
+	if err != nil {
+		return result, err
+	}
+	for _, flow := range flows {
+		result.NetFlows = result.NetFlows.Add(flow.Amount)
+	}
+
+	result.Change = result.EndBalance.Sub(result.StartBalance).Sub(result.NetFlows).Round(2)
+	if result.StartBalance.IsPositive() {
+		result.ChangePercent = result.Change.Div(result.StartBalance).Mul(decimal.NewFromInt(100)).Round(2)
+	}
+	result.TWR = stats.TimeWeightedReturn(stats.EquityCurve(balances), flows)
+
+	return result, nil
+}
+
+// userCashFlows returns the valued cash flows of the user after start until
+// end. Only flows of the wallets the balance history values change it, moves
+// between two of them cancel out.
+func (h *UserHandlers) userCashFlows(ctx context.Context, userID int32, start, end time.Time) ([]stats.CashFlow, error) {
+	rows, err := h.db.Queries.GetUserCashFlows(ctx, db.GetUserCashFlowsParams{
+		UserID:     userID,
+		OccurredAt: pgtype.Timestamptz{Time: start, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var flows []stats.CashFlow
+	for _, row := range rows {
+		if !cashflows.Tracked(row.Wallet, row.FuturesEnabled) {
+			continue
+		}
+		if !row.AmountUsdt.Valid || row.OccurredAt.Time.After(end) {
+			continue
+		}
+		flows = append(flows, stats.CashFlow{Time: row.OccurredAt.Time, Amount: database.Decimal(row.AmountUsdt)})
+	}
+
+	return flows, nil
+}
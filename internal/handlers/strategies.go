@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"trade/internal/strategy"
+
+	"github.com/gorilla/mux"
+)
+
+// GetStrategies lists the strategies bots can be configured with, each with
+// the JSON schema of its params
+func (h UserHandlers) GetStrategies(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(strategy.Definitions())
+}
+
+// GetStrategySchema returns the JSON schema of one strategy's params
+func (h UserHandlers) GetStrategySchema(w http.ResponseWriter, r *http.Request) {
+	def, ok := strategy.Lookup(mux.Vars(r)["name"])
+	if !ok {
+		http.Error(w, "Strategy not found", http.StatusNotFound)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/schema+json")
+	json.NewEncoder(w).Encode(def.Schema)
+}
+
+// validateStrategyParams validates params for the named strategy and returns
+// them as they are stored, with missing params stored as {}
+func validateStrategyParams(name string, params json.RawMessage) (json.RawMessage, error) {
+	if len(bytes.TrimSpace(params)) == 0 || bytes.Equal(bytes.TrimSpace(params), []byte("null")) {
+		params = json.RawMessage("{}")
+	}
+
+	if err := strategy.Validate(name, params); err != nil {
+		return nil, err
+	}
+
+	return params, nil
+}
+
+// writeStrategyError answers a request with invalid strategy params. Field
+// errors are returned as JSON so the dashboard can show them next to the
+// fields.
+func writeStrategyError(w http.ResponseWriter, err error) {
+	var verr *strategy.ValidationError
+	if !errors.As(err, &verr) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]any{
+		"error":  verr.Error(),
+		"fields": verr.Fields,
+	})
+}
@@ -1,20 +1,127 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"net/http"
 	"net/netip"
 	"strings"
+	"time"
 
+	"trade/internal/auth"
+	"trade/internal/binance"
 	db "trade/internal/db/sqlc"
+	"trade/internal/models"
+	"trade/internal/orders"
+	"trade/internal/paper"
+	"trade/internal/strategy"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
 )
 
-func (h *UserHandlers) CreateWebhookLog(r *http.Request, responseStatus int, isSuccessful bool) error {
+const (
+	webhookSource          = "tradingview"
+	webhookSignatureHeader = "X-Signature"
+	webhookTimestampHeader = "X-Timestamp"
+	maxWebhookBodySize     = 1 << 20 // 1MB
+)
+
+// WebhookSignal is the alert payload posted to /api/webhook.
+// Either Quantity or Percent (of the current holding) must be set.
+// The sender authenticates with an X-Signature header, the hex HMAC-SHA256 of
+// "<X-Timestamp>.<body>" where X-Timestamp is the unix time in seconds, or, if
+// it cannot sign, with the bot's webhook secret as Token.
+type WebhookSignal struct {
+	BotID    int32           `json:"bot_id"`
+	Token    string          `json:"token,omitempty"`
+	Symbol   string          `json:"symbol"`
+	Side     string          `json:"side"`
+	Type     string          `json:"type"`
+	Quantity decimal.Decimal `json:"quantity"`
+	Percent  decimal.Decimal `json:"percent"`
+	Price    decimal.Decimal `json:"price"`
+}
+
+func (s WebhookSignal) Validate() error {
+	if s.BotID <= 0 {
+		return errors.New("bot_id is required")
+	}
+
+	if s.Symbol == "" {
+		return errors.New("symbol is required")
+	}
+
+	switch binance.OrderSide(s.Side) {
+	case binance.SideBuy, binance.SideSell:
+	default:
+		return fmt.Errorf("invalid side %q, must be BUY or SELL", s.Side)
+	}
+
+	switch binance.OrderType(s.Type) {
+	case binance.OrderTypeMarket:
+	case binance.OrderTypeLimit:
+		if !s.Price.IsPositive() {
+			return errors.New("price is required for LIMIT orders")
+		}
+	default:
+		return fmt.Errorf("invalid type %q, must be MARKET or LIMIT", s.Type)
+	}
+
+	hasQuantity := s.Quantity.IsPositive()
+	hasPercent := s.Percent.IsPositive()
+	if hasQuantity == hasPercent {
+		return errors.New("exactly one of quantity or percent must be set")
+	}
+
+	if hasPercent && s.Percent.GreaterThan(decimal.NewFromInt(100)) {
+		return errors.New("percent must be between 0 and 100")
+	}
+
+	return nil
+}
+
+// WebhookResult is the outcome of a signal. It is returned to the caller
+// and stored as the response body in webhook_logs.
+type WebhookResult struct {
+	BotID         int32  `json:"bot_id"`
+	Mode          string `json:"mode,omitempty"`
+	Symbol        string `json:"symbol"`
+	Side          string `json:"side"`
+	Type          string `json:"type"`
+	Quantity      string `json:"quantity,omitempty"`
+	QuoteQuantity string `json:"quote_quantity,omitempty"`
+	Price         string `json:"price,omitempty"`
+	OrderID       int64  `json:"order_id,omitempty"`
+	ClientOrderID string `json:"client_order_id,omitempty"`
+	Status        string `json:"status,omitempty"`
+	ExecutedQty   string `json:"executed_qty,omitempty"`
+}
+
+func (r *WebhookResult) EventType() string {
+	return fmt.Sprintf("%s_%s", r.Type, r.Side)
+}
+
+// webhookError carries the HTTP status the webhook should answer with
+type webhookError struct {
+	status int
+	msg    string
+}
+
+func (e *webhookError) Error() string {
+	return e.msg
+}
+
+func newWebhookError(status int, format string, args ...any) error {
+	return &webhookError{status: status, msg: fmt.Sprintf(format, args...)}
+}
+
+func (h *UserHandlers) CreateWebhookLog(r *http.Request, requestBody []byte, responseStatus int, result *WebhookResult, processErr error, processingTime time.Duration) error {
 	ctx := r.Context()
 
 	// Prepare headers JSON
@@ -35,13 +142,14 @@ func (h *UserHandlers) CreateWebhookLog(r *http.Request, responseStatus int, isS
 		return fmt.Errorf("failed to marshal query params: %w", err)
 	}
 
-	// Read request body if needed (be careful with large bodies)
-	var requestBody []byte
-	if r.Body != nil && r.ContentLength > 0 && r.ContentLength < 10*1024*1024 { // Limit to 10MB
-		requestBody, _ = io.ReadAll(r.Body)
-		r.Body = io.NopCloser(strings.NewReader(string(requestBody)))
+	// request_body is a JSONB column, so only store bodies that are valid JSON
+	if !json.Valid(requestBody) {
+		requestBody = nil
 	}
 
+	// Signals can carry the bot's webhook token, which mustn't be logged
+	requestBody = redactWebhookToken(requestBody)
+
 	// Parse IP address
 	clientIP := h.getClientIP(r)
 	ipAddr, err := netip.ParseAddr(clientIP)
@@ -50,15 +158,32 @@ func (h *UserHandlers) CreateWebhookLog(r *http.Request, responseStatus int, isS
 	}
 
 	params := db.CreateWebhookLogParams{
-		Method:         r.Method,
-		UrlPath:        r.URL.Path,
-		Headers:        headersJSON,
-		QueryParams:    queryParamsJSON,
-		RequestBody:    requestBody,
-		ResponseStatus: pgtype.Int4{Int32: int32(responseStatus), Valid: true},
-		IsSuccessful:   pgtype.Bool{Bool: isSuccessful, Valid: true},
-		UserAgent:      pgtype.Text{String: r.UserAgent(), Valid: true},
-		IpAddress:      &ipAddr,
+		WebhookSource:    webhookSource,
+		Method:           r.Method,
+		UrlPath:          r.URL.Path,
+		Headers:          headersJSON,
+		QueryParams:      queryParamsJSON,
+		RequestBody:      requestBody,
+		ResponseStatus:   pgtype.Int4{Int32: int32(responseStatus), Valid: true},
+		IsSuccessful:     pgtype.Bool{Bool: processErr == nil, Valid: true},
+		UserAgent:        pgtype.Text{String: r.UserAgent(), Valid: true},
+		IpAddress:        &ipAddr,
+		ProcessingTimeMs: pgtype.Int4{Int32: int32(processingTime.Milliseconds()), Valid: true},
+	}
+
+	// Record what the signal resulted in
+	if result != nil {
+		params.EventType = pgtype.Text{String: result.EventType(), Valid: true}
+
+		resultJSON, err := json.Marshal(result)
+		if err != nil {
+			return fmt.Errorf("failed to marshal webhook result: %w", err)
+		}
+		params.ResponseBody = pgtype.Text{String: string(resultJSON), Valid: true}
+	}
+
+	if processErr != nil {
+		params.ErrorMessage = pgtype.Text{String: processErr.Error(), Valid: true}
 	}
 
 	_, err = h.db.Queries.CreateWebhookLog(ctx, params)
@@ -85,28 +210,37 @@ func (h *UserHandlers) getClientIP(r *http.Request) string {
 }
 
 func (h *UserHandlers) Webhook(w http.ResponseWriter, r *http.Request) {
-	// Your webhook logic here
+	start := time.Now()
+
+	// Read the body once so it can be both processed and logged
+	body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBodySize))
+	if err != nil {
+		http.Error(w, "Failed to read request body", http.StatusBadRequest)
+		return
+	}
 
 	// Process the webhook
-	err := h.processWebhook(r)
+	result, err := h.processWebhook(r.Context(), body, r.Header.Get(webhookTimestampHeader), r.Header.Get(webhookSignatureHeader))
 
 	// Log the webhook
 	var status int
-	var success bool
 
 	if err != nil {
 		status = http.StatusInternalServerError
-		success = false
+		var whErr *webhookError
+		if errors.As(err, &whErr) {
+			status = whErr.status
+		}
 		http.Error(w, err.Error(), status)
 	} else {
 		status = http.StatusOK
-		success = true
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(status)
-		json.NewEncoder(w).Encode(map[string]string{"status": "success"})
+		json.NewEncoder(w).Encode(map[string]any{"status": "success", "order": result})
 	}
 
 	// Log the webhook (don't fail the request if logging fails)
-	if logErr := h.CreateWebhookLog(r, status, success); logErr != nil {
+	if logErr := h.CreateWebhookLog(r, body, status, result, err, time.Since(start)); logErr != nil {
 		// Log error but don't return it to client
 		fmt.Printf("Failed to log webhook: %v\n", logErr)
 	}
@@ -126,7 +260,283 @@ func (h *UserHandlers) isSensitiveHeader(headerName string) bool {
 	return false
 }
 
-func (h *UserHandlers) processWebhook(r *http.Request) error {
-	// Your webhook processing logic
+// redactWebhookToken removes the token from a signal body before it is stored
+func redactWebhookToken(body []byte) []byte {
+	var payload map[string]any
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil
+	}
+
+	if _, ok := payload["token"]; !ok {
+		return body
+	}
+	payload["token"] = "[REDACTED]"
+
+	redacted, err := json.Marshal(payload)
+	if err != nil {
+		return nil
+	}
+	return redacted
+}
+
+// authenticateWebhook checks the signal against the bot's webhook secret
+func (h *UserHandlers) authenticateWebhook(ctx context.Context, signal WebhookSignal, body []byte, timestamp, signature string) error {
+	if signature == "" && signal.Token == "" {
+		return newWebhookError(http.StatusUnauthorized, "missing webhook signature or token")
+	}
+
+	secret, err := h.db.Queries.GetBotWebhookSecret(ctx, signal.BotID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return fmt.Errorf("error getting webhook secret from db: %w", err)
+	}
+
+	// Unknown bots and bots without a secret get the same answer as a bad signature
+	if err != nil || !secret.Valid || secret.String == "" {
+		return newWebhookError(http.StatusUnauthorized, "invalid webhook signature or token")
+	}
+
+	if signature != "" {
+		err = auth.CheckWebhookSignature(secret.String, timestamp, body, signature, time.Now())
+	} else {
+		err = auth.CheckWebhookToken(secret.String, signal.Token)
+	}
+	if err != nil {
+		return newWebhookError(http.StatusUnauthorized, "invalid webhook signature or token")
+	}
+
+	return nil
+}
+
+func (h *UserHandlers) processWebhook(ctx context.Context, body []byte, timestamp, signature string) (*WebhookResult, error) {
+	var signal WebhookSignal
+	if err := json.Unmarshal(body, &signal); err != nil {
+		return nil, newWebhookError(http.StatusBadRequest, "invalid signal payload: %v", err)
+	}
+
+	if err := h.authenticateWebhook(ctx, signal, body, timestamp, signature); err != nil {
+		return nil, err
+	}
+
+	signal.Symbol = strings.ToUpper(strings.TrimSpace(signal.Symbol))
+	signal.Side = strings.ToUpper(strings.TrimSpace(signal.Side))
+	signal.Type = strings.ToUpper(strings.TrimSpace(signal.Type))
+	if signal.Type == "" {
+		signal.Type = string(binance.OrderTypeMarket)
+	}
+
+	result := &WebhookResult{
+		BotID:  signal.BotID,
+		Symbol: signal.Symbol,
+		Side:   signal.Side,
+		Type:   signal.Type,
+	}
+
+	if err := signal.Validate(); err != nil {
+		return result, newWebhookError(http.StatusBadRequest, "%v", err)
+	}
+
+	bot, err := h.db.Queries.GetBotWithAccount(ctx, signal.BotID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return result, newWebhookError(http.StatusNotFound, "bot %d not found or has no active Binance account", signal.BotID)
+		}
+		return result, fmt.Errorf("error getting bot from db: %w", err)
+	}
+
+	if models.BotStatus(bot.Status.String) != models.BotStatusRunning {
+		return result, newWebhookError(http.StatusConflict, "bot %d is %s, signal ignored", bot.ID, bot.Status.String)
+	}
+
+	strat, err := strategy.New(bot.Strategy, bot.StrategyParams)
+	if err != nil {
+		return result, fmt.Errorf("error creating strategy %s: %w", bot.Strategy, err)
+	}
+
+	intents := strat.OnSignal(strategy.Signal{
+		Symbol:   signal.Symbol,
+		Side:     binance.OrderSide(signal.Side),
+		Type:     binance.OrderType(signal.Type),
+		Quantity: signal.Quantity,
+		Percent:  signal.Percent,
+		Price:    signal.Price,
+	})
+	if len(intents) == 0 {
+		return result, newWebhookError(http.StatusConflict, "bot %d strategy %s ignored the signal", bot.ID, bot.Strategy)
+	}
+
+	client, err := binance.NewFromEncrypted(bot.ApiKey, bot.ApiSecret, binance.Environment(bot.Environment))
+	if err != nil {
+		return result, fmt.Errorf("error creating client: %w", err)
+	}
+
+	// The result describes the last order placed when a strategy turns the
+	// signal into several
+	for _, intent := range intents {
+		if err := h.placeIntent(ctx, client, bot, intent, result); err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// placeIntent places the order a strategy asked for and records it in result
+func (h *UserHandlers) placeIntent(ctx context.Context, client *binance.Client, bot db.GetBotWithAccountRow, intent strategy.Intent, result *WebhookResult) error {
+	order := binance.OrderRequest{
+		Symbol: intent.Symbol,
+		Side:   intent.Side,
+		Type:   intent.Type,
+	}
+	result.Mode = bot.Mode
+	result.Symbol = intent.Symbol
+	result.Side = string(intent.Side)
+	result.Type = string(intent.Type)
+
+	if order.Type == binance.OrderTypeLimit {
+		order.TimeInForce = binance.TimeInForceGTC
+		order.Price = intent.Price.String()
+		result.Price = order.Price
+	}
+
+	paperMode := models.BotMode(bot.Mode) == models.BotModePaper
+
+	if intent.Percent.IsPositive() {
+		balances := accountBalances(client, orders.Market(bot.Market), intent.Symbol)
+		if paperMode {
+			balances = paper.New(h.db, client, bot.ID).Balances
+		}
+		if err := sizeOrderFromHolding(ctx, client, balances, intent, &order); err != nil {
+			return err
+		}
+	} else {
+		order.Quantity = intent.Quantity.String()
+	}
+	result.Quantity = order.Quantity
+	result.QuoteQuantity = order.QuoteOrderQty
+
+	var res binance.Order
+	var err error
+	if paperMode {
+		res, err = h.orders.SubmitPaper(ctx, client, bot.ID, bot.BinanceAccountID.Int32, order)
+	} else {
+		res, err = h.orders.Submit(ctx, client, bot.ID, bot.BinanceAccountID.Int32, orders.Market(bot.Market), order)
+	}
+	if err != nil {
+		status := http.StatusBadGateway
+		if errors.Is(err, binance.ErrInvalidOrder) || errors.Is(err, binance.ErrInvalidSymbol) {
+			status = http.StatusBadRequest
+		} else if errors.Is(err, binance.ErrInsufficientBalance) || errors.Is(err, binance.ErrOrderRejected) {
+			status = http.StatusUnprocessableEntity
+		}
+		return newWebhookError(status, "failed to place order: %v", err)
+	}
+
+	result.OrderID = res.OrderID
+	result.ClientOrderID = res.ClientOrderID
+	result.Status = string(res.Status)
+	result.ExecutedQty = res.ExecutedQty
+
+	return nil
+}
+
+// balanceSource returns the free balance per asset. quote is the quote asset of
+// the symbol being traded, a paper account that hasn't traded yet holds it.
+type balanceSource func(ctx context.Context, quote string) (map[string]decimal.Decimal, error)
+
+// accountBalances reads the free balances of the bot's market on the Binance
+// account. On isolated margin that's the account of symbol only.
+func accountBalances(client *binance.Client, market orders.Market, symbol string) balanceSource {
+	return func(ctx context.Context, quote string) (map[string]decimal.Decimal, error) {
+		free := make(map[string]string)
+
+		switch market {
+		case orders.MarketMargin:
+			account, err := client.GetMarginAccountInfo()
+			if err != nil {
+				return nil, err
+			}
+			for _, asset := range account.UserAssets {
+				free[asset.Asset] = asset.Free
+			}
+		case orders.MarketIsolated:
+			account, err := client.GetIsolatedMarginAccount(symbol)
+			if err != nil {
+				return nil, err
+			}
+			for _, pair := range account.Assets {
+				free[pair.BaseAsset.Asset] = pair.BaseAsset.Free
+				free[pair.QuoteAsset.Asset] = pair.QuoteAsset.Free
+			}
+		default:
+			account, err := client.GetAccountInfo()
+			if err != nil {
+				return nil, err
+			}
+			for _, balance := range account.Balances {
+				free[balance.Asset] = balance.Free
+			}
+		}
+
+		balances := make(map[string]decimal.Decimal, len(free))
+		for asset, amount := range free {
+			balance, err := decimal.NewFromString(amount)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing %s balance: %w", asset, err)
+			}
+			balances[asset] = balance
+		}
+
+		return balances, nil
+	}
+}
+
+// sizeOrderFromHolding turns a percent-of-holding signal into an order size.
+// Buys spend a share of the free quote asset, sells a share of the free base asset.
+func sizeOrderFromHolding(ctx context.Context, client *binance.Client, balances balanceSource, intent strategy.Intent, order *binance.OrderRequest) error {
+	symbol, err := client.GetSymbolInfo(intent.Symbol)
+	if err != nil {
+		if errors.Is(err, binance.ErrInvalidSymbol) {
+			return newWebhookError(http.StatusBadRequest, "unknown symbol %s", intent.Symbol)
+		}
+		return newWebhookError(http.StatusBadGateway, "failed to get exchange info for %s: %v", intent.Symbol, err)
+	}
+
+	free, err := balances(ctx, symbol.QuoteAsset)
+	if err != nil {
+		return newWebhookError(http.StatusBadGateway, "failed to get account balances: %v", err)
+	}
+
+	asset := symbol.BaseAsset
+	if order.Side == binance.SideBuy {
+		asset = symbol.QuoteAsset
+	}
+
+	amount := free[asset].Mul(intent.Percent).Div(decimal.NewFromInt(100))
+
+	// A market buy can spend the quote amount directly
+	if order.Side == binance.SideBuy && order.Type == binance.OrderTypeMarket {
+		quoteQty := amount.Truncate(symbol.QuoteAssetPrecision)
+		if !quoteQty.IsPositive() {
+			return newWebhookError(http.StatusUnprocessableEntity, "no free %s to buy %s with", asset, intent.Symbol)
+		}
+		order.QuoteOrderQty = quoteQty.String()
+		return nil
+	}
+
+	quantity := amount
+	if order.Side == binance.SideBuy {
+		quantity = amount.Div(intent.Price)
+	}
+
+	step, err := decimal.NewFromString(symbol.StepSize())
+	if err == nil && step.IsPositive() {
+		quantity = quantity.Div(step).Floor().Mul(step)
+	}
+
+	if !quantity.IsPositive() {
+		return newWebhookError(http.StatusUnprocessableEntity, "%s%% of free %s is below the minimum order size", intent.Percent, asset)
+	}
+
+	order.Quantity = quantity.String()
 	return nil
 }
@@ -28,3 +28,21 @@ func (s BotStatus) IsValid() bool {
 		return false
 	}
 }
+
+// BotMode is where a bot's orders go
+type BotMode string
+
+const (
+	BotModeLive    BotMode = "live"    // the bot's Binance account
+	BotModePaper   BotMode = "paper"   // simulated against live prices with virtual balances
+	BotModeTestnet BotMode = "testnet" // a Binance testnet account
+)
+
+func (m BotMode) IsValid() bool {
+	switch m {
+	case BotModeLive, BotModePaper, BotModeTestnet:
+		return true
+	default:
+		return false
+	}
+}
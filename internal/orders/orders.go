@@ -0,0 +1,384 @@
+package orders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"trade/internal/binance"
+	"trade/internal/database"
+	db "trade/internal/db/sqlc"
+	"trade/internal/models"
+	"trade/internal/paper"
+	"trade/internal/stats"
+
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/shopspring/decimal"
+)
+
+type Market string
+
+const (
+	MarketSpot   Market = "SPOT"
+	MarketMargin Market = "MARGIN"
+	// The isolated margin account of the order's symbol
+	MarketIsolated Market = "ISOLATED"
+	// USDⓈ-M futures positions are read from the exchange, bots don't
+	// place futures orders
+	MarketFutures Market = "FUTURES"
+)
+
+// statusUnknown marks an order whose placement got no answer from Binance.
+// It may or may not have been placed, reconciling looks it up by its client
+// order id.
+const statusUnknown = "UNKNOWN"
+
+// Binance may take a moment to list an order it just accepted, an unknown
+// order is only taken as never placed once it is older than this
+const unknownOrderGrace = 5 * time.Minute
+
+// Tradable reports whether bots can place orders on the market
+func (m Market) Tradable() bool {
+	return m == MarketSpot || m == MarketMargin || m == MarketIsolated
+}
+
+// Service keeps the orders and fills tables in sync with what was sent to Binance
+type Service struct {
+	db *database.Database
+}
+
+func New(db *database.Database) *Service {
+	return &Service{db: db}
+}
+
+// Submit records the order, places it on Binance and stores the result.
+// Orders rejected by Binance are kept with status REJECTED and the error
+// message. When Binance doesn't answer the order is kept as UNKNOWN until
+// reconciling finds out whether it was placed.
+func (s *Service) Submit(ctx context.Context, client *binance.Client, botID, accountID int32, market Market, req binance.OrderRequest) (binance.Order, error) {
+	mode := models.BotModeLive
+	if client.Environment.IsTestnet() {
+		mode = models.BotModeTestnet
+	}
+
+	place := client.PlaceOrder
+	switch market {
+	case MarketMargin:
+		place = client.PlaceMarginOrder
+	case MarketIsolated:
+		place = client.PlaceIsolatedMarginOrder
+	}
+
+	return s.submit(ctx, botID, accountID, market, mode, req, place)
+}
+
+// SubmitPaper records the order like Submit, but fills it on the bot's paper
+// exchange at the current prices of client instead of sending it to Binance
+func (s *Service) SubmitPaper(ctx context.Context, client *binance.Client, botID, accountID int32, req binance.OrderRequest) (binance.Order, error) {
+	exchange := paper.New(s.db, client, botID)
+	return s.submit(ctx, botID, accountID, MarketSpot, models.BotModePaper, req, func(req binance.OrderRequest) (binance.Order, error) {
+		return exchange.PlaceOrder(ctx, req)
+	})
+}
+
+func (s *Service) submit(ctx context.Context, botID, accountID int32, market Market, mode models.BotMode, req binance.OrderRequest, place func(binance.OrderRequest) (binance.Order, error)) (binance.Order, error) {
+	if req.NewClientOrderID == "" {
+		req.NewClientOrderID = fmt.Sprintf("bot%d_%d", botID, time.Now().UnixNano())
+	}
+
+	record, err := s.db.Queries.CreateOrder(ctx, db.CreateOrderParams{
+		BotID:            pgtype.Int4{Int32: botID, Valid: botID != 0},
+		BinanceAccountID: accountID,
+		ClientOrderID:    req.NewClientOrderID,
+		Symbol:           req.Symbol,
+		Market:           string(market),
+		Side:             string(req.Side),
+		OrderType:        string(req.Type),
+		Price:            database.ParseNumeric(req.Price),
+		StopPrice:        database.ParseNumeric(req.StopPrice),
+		OrigQty:          database.ParseNumeric(req.Quantity),
+		Mode:             string(mode),
+	})
+	if err != nil {
+		return binance.Order{}, fmt.Errorf("error recording order: %w", err)
+	}
+
+	order, err := place(req)
+	if err != nil {
+		message := pgtype.Text{String: err.Error(), Valid: true}
+		var recordErr error
+		if mode == models.BotModePaper || rejected(err) {
+			recordErr = s.db.Queries.RejectOrder(ctx, db.RejectOrderParams{ID: record.ID, ErrorMessage: message})
+		} else {
+			recordErr = s.db.Queries.MarkOrderUnknown(ctx, db.MarkOrderUnknownParams{ID: record.ID, ErrorMessage: message})
+		}
+		return binance.Order{}, errors.Join(err, recordErr)
+	}
+
+	fills := make([]binance.Trade, 0, len(order.Fills))
+	for _, fill := range order.Fills {
+		fills = append(fills, binance.Trade{
+			ID:              fill.TradeID,
+			Price:           fill.Price,
+			Qty:             fill.Qty,
+			Commission:      fill.Commission,
+			CommissionAsset: fill.CommissionAsset,
+			Time:            order.TransactTime,
+		})
+	}
+
+	if err := s.record(ctx, record.ID, order, fills); err != nil {
+		return order, fmt.Errorf("order %d placed but not recorded: %w", order.OrderID, err)
+	}
+
+	if botID != 0 && len(fills) > 0 {
+		if err := s.RefreshBotStats(ctx, botID); err != nil {
+			return order, err
+		}
+	}
+
+	return order, nil
+}
+
+// rejected reports whether err means Binance did not place the order. Only
+// an answer from Binance says so, after a timeout or a server error the
+// order may have executed.
+func rejected(err error) bool {
+	var apiErr *binance.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode < http.StatusInternalServerError
+	}
+	// Orders that fail validation are never sent
+	return errors.Is(err, binance.ErrInvalidOrder)
+}
+
+// Reconcile refreshes every open order from Binance and stores new fills
+func (s *Service) Reconcile(ctx context.Context) error {
+	open, err := s.db.Queries.GetOpenOrdersWithAccounts(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting open orders: %w", err)
+	}
+
+	clients := make(map[int32]*binance.Client)
+	var errs []error
+
+	for _, o := range open {
+		client, ok := clients[o.BinanceAccountID]
+		if !ok {
+			client, err = binance.NewFromEncrypted(o.ApiKey, o.ApiSecret, binance.Environment(o.Environment))
+			if err != nil {
+				errs = append(errs, fmt.Errorf("account %d: %w", o.BinanceAccountID, err))
+				continue
+			}
+			clients[o.BinanceAccountID] = client
+		}
+
+		if err := s.reconcileOrder(ctx, client, o); err != nil {
+			errs = append(errs, fmt.Errorf("order %d: %w", o.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// CancelBotOrders cancels the open orders of a bot on Binance and records
+// their final state, including fills that happened before the cancel
+func (s *Service) CancelBotOrders(ctx context.Context, botID int32) error {
+	open, err := s.db.Queries.GetBotOpenOrdersWithAccounts(ctx, pgtype.Int4{Int32: botID, Valid: true})
+	if err != nil {
+		return fmt.Errorf("error getting open orders of bot %d: %w", botID, err)
+	}
+
+	var errs []error
+	for _, o := range open {
+		client, err := binance.NewFromEncrypted(o.ApiKey, o.ApiSecret, binance.Environment(o.Environment))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("order %d: %w", o.ID, err))
+			continue
+		}
+
+		switch Market(o.Market) {
+		case MarketMargin:
+			_, err = client.CancelMarginOrder(o.Symbol, o.ExchangeOrderID.Int64)
+		case MarketIsolated:
+			_, err = client.CancelIsolatedMarginOrder(o.Symbol, o.ExchangeOrderID.Int64)
+		default:
+			_, err = client.CancelOrder(o.Symbol, o.ExchangeOrderID.Int64)
+		}
+		// An order that filled or was cancelled meanwhile is unknown to cancel,
+		// reconciling records what happened to it
+		if err != nil && !errors.Is(err, binance.ErrUnknownOrder) {
+			errs = append(errs, fmt.Errorf("order %d: %w", o.ID, err))
+			continue
+		}
+
+		if err := s.reconcileOrder(ctx, client, db.GetOpenOrdersWithAccountsRow(o)); err != nil {
+			errs = append(errs, fmt.Errorf("order %d: %w", o.ID, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *Service) reconcileOrder(ctx context.Context, client *binance.Client, o db.GetOpenOrdersWithAccountsRow) error {
+	var trades []binance.Trade
+
+	order, err := getOrder(client, o)
+	if errors.Is(err, binance.ErrUnknownOrder) && o.Status == statusUnknown {
+		if time.Since(o.CreatedAt.Time) < unknownOrderGrace {
+			return nil
+		}
+		return s.db.Queries.RejectOrder(ctx, db.RejectOrderParams{
+			ID:           o.ID,
+			ErrorMessage: pgtype.Text{String: "order was never placed on Binance", Valid: true},
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	// Only fetch trades when something was executed
+	executed, _ := decimal.NewFromString(order.ExecutedQty)
+	if executed.IsPositive() {
+		switch Market(o.Market) {
+		case MarketMargin:
+			trades, err = client.GetMarginOrderTrades(o.Symbol, order.OrderID)
+		case MarketIsolated:
+			trades, err = client.GetIsolatedMarginOrderTrades(o.Symbol, order.OrderID)
+		default:
+			trades, err = client.GetOrderTrades(o.Symbol, order.OrderID)
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := s.record(ctx, o.ID, order, trades); err != nil {
+		return err
+	}
+
+	if o.BotID.Valid && len(trades) > 0 {
+		return s.RefreshBotStats(ctx, o.BotID.Int32)
+	}
+
+	return nil
+}
+
+// getOrder reads the order from Binance, by its client order id when its
+// placement never got an answer
+func getOrder(client *binance.Client, o db.GetOpenOrdersWithAccountsRow) (binance.Order, error) {
+	if !o.ExchangeOrderID.Valid {
+		switch Market(o.Market) {
+		case MarketMargin:
+			return client.GetMarginOrderByClientID(o.Symbol, o.ClientOrderID)
+		case MarketIsolated:
+			return client.GetIsolatedMarginOrderByClientID(o.Symbol, o.ClientOrderID)
+		default:
+			return client.GetOrderByClientID(o.Symbol, o.ClientOrderID)
+		}
+	}
+
+	switch Market(o.Market) {
+	case MarketMargin:
+		return client.GetMarginOrder(o.Symbol, o.ExchangeOrderID.Int64)
+	case MarketIsolated:
+		return client.GetIsolatedMarginOrder(o.Symbol, o.ExchangeOrderID.Int64)
+	default:
+		return client.GetOrder(o.Symbol, o.ExchangeOrderID.Int64)
+	}
+}
+
+// botFills returns the fills of a bot in its current mode
+func (s *Service) botFills(ctx context.Context, botID int32) ([]stats.Fill, error) {
+	rows, err := s.db.Queries.GetBotFills(ctx, pgtype.Int4{Int32: botID, Valid: true})
+	if err != nil {
+		return nil, fmt.Errorf("error getting fills of bot %d: %w", botID, err)
+	}
+
+	fills := make([]stats.Fill, 0, len(rows))
+	for _, row := range rows {
+		fills = append(fills, stats.Fill{
+			Symbol:          row.Symbol,
+			Side:            row.Side,
+			Price:           database.Decimal(row.Price),
+			Quantity:        database.Decimal(row.Quantity),
+			Commission:      database.Decimal(row.Commission),
+			CommissionAsset: row.CommissionAsset,
+			Time:            row.FilledAt.Time,
+			Step:            binance.LotStep(row.Symbol),
+		})
+	}
+
+	return fills, nil
+}
+
+// BotPositions returns what the recorded fills of a bot leave open
+func (s *Service) BotPositions(ctx context.Context, botID int32) ([]stats.Position, error) {
+	fills, err := s.botFills(ctx, botID)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats.OpenPositions(fills), nil
+}
+
+// RefreshBotStats recomputes win rate, profit factor, trades and holding
+// of a bot from its recorded fills
+func (s *Service) RefreshBotStats(ctx context.Context, botID int32) error {
+	fills, err := s.botFills(ctx, botID)
+	if err != nil {
+		return err
+	}
+
+	botStats := stats.Compute(fills)
+
+	err = s.db.Queries.UpdateBotStats(ctx, db.UpdateBotStatsParams{
+		ID:           botID,
+		WinRate:      database.ParseNumeric(botStats.WinRate.String()),
+		ProfitFactor: database.ParseNumeric(botStats.ProfitFactor.String()),
+		Trades:       pgtype.Int4{Int32: botStats.Trades, Valid: true},
+		RealizedPnl:  database.ParseNumeric(botStats.RealizedPnl.Round(2).String()),
+	})
+	if err != nil {
+		return fmt.Errorf("error updating stats of bot %d: %w", botID, err)
+	}
+
+	return nil
+}
+
+func (s *Service) record(ctx context.Context, orderID int32, order binance.Order, trades []binance.Trade) error {
+	_, err := s.db.Queries.UpdateOrderFromExchange(ctx, db.UpdateOrderFromExchangeParams{
+		ID:                 orderID,
+		ExchangeOrderID:    pgtype.Int8{Int64: order.OrderID, Valid: true},
+		Status:             string(order.Status),
+		ExecutedQty:        database.ParseNumeric(order.ExecutedQty),
+		CumulativeQuoteQty: database.ParseNumeric(order.CummulativeQuoteQty),
+	})
+	if err != nil {
+		return fmt.Errorf("error updating order: %w", err)
+	}
+
+	for _, trade := range trades {
+		filledAt := time.Now()
+		if trade.Time != 0 {
+			filledAt = time.UnixMilli(trade.Time)
+		}
+
+		err := s.db.Queries.CreateFill(ctx, db.CreateFillParams{
+			OrderID:         orderID,
+			TradeID:         trade.ID,
+			Price:           database.ParseNumeric(trade.Price),
+			Quantity:        database.ParseNumeric(trade.Qty),
+			Commission:      database.ParseNumeric(trade.Commission),
+			CommissionAsset: trade.CommissionAsset,
+			FilledAt:        pgtype.Timestamptz{Time: filledAt, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("error recording fill %d: %w", trade.ID, err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,258 @@
+// Package paper simulates the spot account of a bot in paper mode. Orders
+// fill right away against the live order book and move virtual balances, so
+// a strategy can run on real prices without touching real funds.
+package paper
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"trade/internal/binance"
+	"trade/internal/database"
+	db "trade/internal/db/sqlc"
+
+	"github.com/shopspring/decimal"
+)
+
+// DefaultFee is Binance's spot fee without discounts, as a fraction of what a fill brings in
+var DefaultFee = decimal.RequireFromString("0.001")
+
+// Exchange is the simulated account of one bot. Prices come from client, which
+// is only used for market data.
+type Exchange struct {
+	db     *database.Database
+	client *binance.Client
+	botID  int32
+	fee    decimal.Decimal
+}
+
+func New(db *database.Database, client *binance.Client, botID int32) *Exchange {
+	return &Exchange{db: db, client: client, botID: botID, fee: DefaultFee}
+}
+
+// Balances returns the virtual free balance per asset. A bot that hasn't
+// traded yet holds its initial_holding in quote, the quote asset of the
+// symbol it is about to trade.
+func (e *Exchange) Balances(ctx context.Context, quote string) (map[string]decimal.Decimal, error) {
+	return e.balances(ctx, e.db.Queries, quote)
+}
+
+// PlaceOrder fills req at the best ask for buys and the best bid for sells,
+// like a taker order on Binance would, and settles it with Settle. Limit orders
+// that wouldn't fill right away are rejected, they aren't kept on a book.
+func (e *Exchange) PlaceOrder(ctx context.Context, req binance.OrderRequest) (binance.Order, error) {
+	if req.Side != binance.SideBuy && req.Side != binance.SideSell {
+		return binance.Order{}, fmt.Errorf("%w: invalid side %q", binance.ErrInvalidOrder, req.Side)
+	}
+	if req.Type != binance.OrderTypeMarket && req.Type != binance.OrderTypeLimit {
+		return binance.Order{}, fmt.Errorf("%w: %s orders are not simulated in paper mode", binance.ErrInvalidOrder, req.Type)
+	}
+
+	symbol, err := e.client.GetSymbolInfo(req.Symbol)
+	if err != nil {
+		return binance.Order{}, err
+	}
+
+	price, err := e.fillPrice(req)
+	if err != nil {
+		return binance.Order{}, err
+	}
+
+	quantity, err := orderQuantity(req, symbol, price)
+	if err != nil {
+		return binance.Order{}, err
+	}
+
+	notional := quantity.Mul(price)
+
+	tx, err := e.db.DBPool.Begin(ctx)
+	if err != nil {
+		return binance.Order{}, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	qtx := e.db.Queries.WithTx(tx)
+	balances, err := e.balances(ctx, qtx, symbol.QuoteAsset)
+	if err != nil {
+		return binance.Order{}, err
+	}
+
+	base, quote := symbol.BaseAsset, symbol.QuoteAsset
+	commission, commissionAsset, err := Settle(balances, base, quote, req.Side, quantity, price, e.fee)
+	if err != nil {
+		return binance.Order{}, err
+	}
+
+	for _, asset := range []string{base, quote} {
+		err := qtx.SetPaperBalance(ctx, db.SetPaperBalanceParams{
+			BotID: e.botID,
+			Asset: asset,
+			Free:  database.ParseNumeric(balances[asset].String()),
+		})
+		if err != nil {
+			return binance.Order{}, fmt.Errorf("error storing paper %s balance: %w", asset, err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return binance.Order{}, fmt.Errorf("error committing paper balances: %w", err)
+	}
+
+	now := time.Now()
+	id := now.UnixNano()
+	return binance.Order{
+		Symbol:              req.Symbol,
+		OrderID:             id,
+		OrderListID:         -1,
+		ClientOrderID:       req.NewClientOrderID,
+		Price:               req.Price,
+		OrigQty:             quantity.String(),
+		ExecutedQty:         quantity.String(),
+		CummulativeQuoteQty: notional.String(),
+		Status:              binance.OrderStatusFilled,
+		TimeInForce:         req.TimeInForce,
+		Type:                req.Type,
+		Side:                req.Side,
+		Time:                now.UnixMilli(),
+		UpdateTime:          now.UnixMilli(),
+		TransactTime:        now.UnixMilli(),
+		Fills: []binance.Fill{{
+			TradeID:         id,
+			Price:           price.String(),
+			Qty:             quantity.String(),
+			Commission:      commission.String(),
+			CommissionAsset: commissionAsset,
+		}},
+	}, nil
+}
+
+// Settle moves balances by a fill of quantity base at price and returns the
+// commission and the asset it was paid in. Like a Binance order without a
+// BNB discount, fee is taken from what the fill brings in, the base asset on
+// buys and the quote asset on sells. Backtests settle their fills the same
+// way, so their results compare with paper bots.
+func Settle(balances map[string]decimal.Decimal, base, quote string, side binance.OrderSide, quantity, price, fee decimal.Decimal) (decimal.Decimal, string, error) {
+	notional := quantity.Mul(price)
+
+	if side == binance.SideBuy {
+		if notional.GreaterThan(balances[quote]) {
+			return decimal.Zero, "", fmt.Errorf("%w: buying %s %s needs %s %s, have %s", binance.ErrInsufficientBalance, quantity, base, notional.Round(8), quote, balances[quote])
+		}
+		commission := quantity.Mul(fee)
+		balances[quote] = balances[quote].Sub(notional)
+		balances[base] = balances[base].Add(quantity).Sub(commission)
+		return commission, base, nil
+	}
+
+	if quantity.GreaterThan(balances[base]) {
+		return decimal.Zero, "", fmt.Errorf("%w: selling %s %s, have %s", binance.ErrInsufficientBalance, quantity, base, balances[base])
+	}
+	commission := notional.Mul(fee)
+	balances[base] = balances[base].Sub(quantity)
+	balances[quote] = balances[quote].Add(notional).Sub(commission)
+	return commission, quote, nil
+}
+
+// balances reads the bot's balances and locks the bot when q runs in a
+// transaction, so concurrent orders of the bot fill one after the other
+func (e *Exchange) balances(ctx context.Context, q *db.Queries, quote string) (map[string]decimal.Decimal, error) {
+	initialHolding, err := q.GetPaperBotForUpdate(ctx, e.botID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting bot %d: %w", e.botID, err)
+	}
+
+	rows, err := q.GetPaperBalances(ctx, e.botID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting paper balances of bot %d: %w", e.botID, err)
+	}
+
+	balances := make(map[string]decimal.Decimal, len(rows))
+	if len(rows) == 0 {
+		balances[quote] = database.Decimal(initialHolding)
+		return balances, nil
+	}
+
+	for _, row := range rows {
+		balances[row.Asset] = database.Decimal(row.Free)
+	}
+
+	return balances, nil
+}
+
+// fillPrice is the best price on the side of the book the order takes from,
+// or the last price when that side is empty
+func (e *Exchange) fillPrice(req binance.OrderRequest) (decimal.Decimal, error) {
+	book, err := e.client.GetBookTicker(req.Symbol)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	quoted := book.AskPrice
+	if req.Side == binance.SideSell {
+		quoted = book.BidPrice
+	}
+
+	price, err := decimal.NewFromString(quoted)
+	if err != nil || !price.IsPositive() {
+		last, err := e.client.GetPrice(req.Symbol)
+		if err != nil {
+			return decimal.Zero, err
+		}
+		price, err = decimal.NewFromString(last.Price)
+		if err != nil {
+			return decimal.Zero, fmt.Errorf("error parsing %s price: %w", req.Symbol, err)
+		}
+	}
+
+	if req.Type != binance.OrderTypeLimit {
+		return price, nil
+	}
+
+	limit, err := decimal.NewFromString(req.Price)
+	if err != nil || !limit.IsPositive() {
+		return decimal.Zero, fmt.Errorf("%w: invalid price %q", binance.ErrInvalidOrder, req.Price)
+	}
+
+	if (req.Side == binance.SideBuy && price.GreaterThan(limit)) || (req.Side == binance.SideSell && price.LessThan(limit)) {
+		return decimal.Zero, fmt.Errorf("%w: limit %s at %s would rest on the book at %s, paper mode only fills orders that execute right away",
+			binance.ErrOrderRejected, req.Side, limit, price)
+	}
+
+	return price, nil
+}
+
+// orderQuantity is the base quantity of req. A quote order quantity is
+// converted at price and rounded down to the symbol's step size.
+func orderQuantity(req binance.OrderRequest, symbol binance.SymbolInfo, price decimal.Decimal) (decimal.Decimal, error) {
+	if req.Quantity != "" {
+		quantity, err := decimal.NewFromString(req.Quantity)
+		if err != nil || !quantity.IsPositive() {
+			return decimal.Zero, fmt.Errorf("%w: invalid quantity %q", binance.ErrInvalidOrder, req.Quantity)
+		}
+		return quantity, nil
+	}
+
+	if req.Type != binance.OrderTypeMarket || req.QuoteOrderQty == "" {
+		return decimal.Zero, fmt.Errorf("%w: %s orders need a quantity", binance.ErrInvalidOrder, req.Type)
+	}
+
+	quoteQty, err := decimal.NewFromString(req.QuoteOrderQty)
+	if err != nil || !quoteQty.IsPositive() {
+		return decimal.Zero, fmt.Errorf("%w: invalid quote order quantity %q", binance.ErrInvalidOrder, req.QuoteOrderQty)
+	}
+
+	quantity := quoteQty.Div(price)
+	step, err := decimal.NewFromString(symbol.StepSize())
+	if err == nil && step.IsPositive() {
+		quantity = quantity.Div(step).Floor().Mul(step)
+	} else {
+		quantity = quantity.Truncate(symbol.BaseAssetPrecision)
+	}
+
+	if !quantity.IsPositive() {
+		return decimal.Zero, fmt.Errorf("%w: %s %s buys less than the minimum quantity", binance.ErrInvalidOrder, quoteQty, symbol.QuoteAsset)
+	}
+
+	return quantity, nil
+}
@@ -0,0 +1,71 @@
+package paper
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"trade/internal/binance"
+	"trade/internal/stats"
+
+	"github.com/shopspring/decimal"
+)
+
+func TestSettleBuyThenSell(t *testing.T) {
+	balances := map[string]decimal.Decimal{"USDT": decimal.NewFromInt(1000)}
+	start := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	var fills []stats.Fill
+	settle := func(side binance.OrderSide, quantity, price string) error {
+		q, p := decimal.RequireFromString(quantity), decimal.RequireFromString(price)
+		commission, asset, err := Settle(balances, "BTC", "USDT", side, q, p, DefaultFee)
+		if err != nil {
+			return err
+		}
+		fills = append(fills, stats.Fill{
+			Symbol:          "BTCUSDT",
+			Side:            string(side),
+			Price:           p,
+			Quantity:        q,
+			Commission:      commission,
+			CommissionAsset: asset,
+			Time:            start.Add(time.Duration(len(fills)) * time.Minute),
+		})
+		return nil
+	}
+
+	if err := settle(binance.SideBuy, "1", "100"); err != nil {
+		t.Fatal(err)
+	}
+	if got := fills[0]; got.CommissionAsset != "BTC" || !got.Commission.Equal(decimal.RequireFromString("0.001")) {
+		t.Errorf("buy commission = %s %s, want 0.001 BTC", got.Commission, got.CommissionAsset)
+	}
+	if !balances["BTC"].Equal(decimal.RequireFromString("0.999")) || !balances["USDT"].Equal(decimal.NewFromInt(900)) {
+		t.Errorf("after the buy holding %s BTC and %s USDT, want 0.999 and 900", balances["BTC"], balances["USDT"])
+	}
+
+	// The bought quantity is more than the fee left
+	err := settle(binance.SideSell, "1", "110")
+	if !errors.Is(err, binance.ErrInsufficientBalance) {
+		t.Fatalf("selling the bought quantity: got %v, want ErrInsufficientBalance", err)
+	}
+
+	if err := settle(binance.SideSell, "0.999", "110"); err != nil {
+		t.Fatal(err)
+	}
+	if got := fills[1]; got.CommissionAsset != "USDT" || !got.Commission.Equal(decimal.RequireFromString("0.10989")) {
+		t.Errorf("sell commission = %s %s, want 0.10989 USDT", got.Commission, got.CommissionAsset)
+	}
+	if !balances["BTC"].IsZero() || !balances["USDT"].Equal(decimal.RequireFromString("1009.78011")) {
+		t.Errorf("after the sell holding %s BTC and %s USDT, want 0 and 1009.78011", balances["BTC"], balances["USDT"])
+	}
+
+	// The stats see the same round trip the balances do
+	if open := stats.OpenPositions(fills); len(open) > 0 {
+		t.Errorf("%s %s left open, want flat", open[0].Side, open[0].Quantity)
+	}
+	s := stats.Compute(fills)
+	if s.Trades != 1 || !s.RealizedPnl.Equal(decimal.RequireFromString("9.78011")) {
+		t.Errorf("got %d trades with pnl %s, want 1 with 9.78011", s.Trades, s.RealizedPnl)
+	}
+}
@@ -0,0 +1,393 @@
+package positions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"trade/internal/binance"
+	"trade/internal/database"
+	db "trade/internal/db/sqlc"
+	"trade/internal/exchange"
+	"trade/internal/orders"
+	"trade/internal/stats"
+
+	"github.com/shopspring/decimal"
+)
+
+// Positions are valued against this quote asset
+const quoteAsset = "USDT"
+
+// Holdings worth less than this (in quote asset) are dust and not listed
+var dustThreshold = decimal.NewFromInt(1)
+
+type Position struct {
+	AccountID   int32            `json:"account_id"`
+	AccountName string           `json:"account_name"`
+	Market      orders.Market    `json:"market"`
+	Symbol      string           `json:"symbol"`
+	Bot         string           `json:"bot"`
+	BotIDs      []int32          `json:"bot_ids"`
+	Position    string           `json:"position"` // LONG or SHORT
+	Quantity    decimal.Decimal  `json:"quantity"`
+	Entry       *decimal.Decimal `json:"entry"` // nil when no bot fills explain the holding
+	Current     decimal.Decimal  `json:"current"`
+	Pnl         *decimal.Decimal `json:"pnl"`
+	OpenedAt    *time.Time       `json:"opened_at"`
+	Time        string           `json:"time"` // holding time, e.g. "2h 45m"
+	// Futures and isolated margin only
+	Leverage         int              `json:"leverage,omitempty"`
+	LiquidationPrice *decimal.Decimal `json:"liquidation_price,omitempty"`
+}
+
+// holding is a non-zero asset balance on the exchange, negative when borrowed.
+// Its symbol is the asset against the quote asset, except on isolated margin
+// where it's the pair the balance belongs to.
+type holding struct {
+	account     db.GetUserBinanceAccountsRow
+	market      orders.Market
+	asset       string
+	symbol      string
+	quantity    decimal.Decimal
+	liquidation decimal.Decimal // isolated margin only
+}
+
+// botPosition is the part of a holding opened by one bot's fills
+type botPosition struct {
+	botID int32
+	name  string
+	stats.Position
+}
+
+type positionKey struct {
+	accountID int32
+	market    orders.Market
+	symbol    string
+}
+
+type Service struct {
+	db *database.Database
+}
+
+func New(db *database.Database) *Service {
+	return &Service{db: db}
+}
+
+// ForUser lists the open positions on all active accounts of the user.
+// Accounts that can't be read are skipped and reported in the returned error,
+// so one broken key doesn't hide the other accounts.
+func (s *Service) ForUser(ctx context.Context, userID int32) ([]Position, error) {
+	accounts, err := s.db.Queries.GetUserBinanceAccounts(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting accounts: %w", err)
+	}
+
+	botPositions, err := s.botPositions(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var holdings []holding
+	var futures []Position
+	var errs []error
+	exchanges := make(map[string]exchange.Exchange) // one per exchange and environment, used for prices
+
+	for _, account := range accounts {
+		ex, err := exchange.Open(exchange.Account{
+			Exchange:        exchange.Name(account.Exchange),
+			Environment:     account.Environment,
+			EncryptedKey:    account.ApiKey,
+			EncryptedSecret: account.ApiSecret,
+			FuturesEnabled:  account.FuturesEnabled,
+		})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("account %d: %w", account.ID, err))
+			continue
+		}
+
+		accountFutures, err := fetchFuturesPositions(ex, account)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("account %d: %w", account.ID, err))
+		}
+		futures = append(futures, accountFutures...)
+
+		accountHoldings, err := fetchHoldings(ex, account)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("account %d: %w", account.ID, err))
+			continue
+		}
+
+		if len(accountHoldings) > 0 {
+			holdings = append(holdings, accountHoldings...)
+			exchanges[pricesKey(account)] = ex
+		}
+	}
+
+	// All tickers in one request per exchange, instead of a request per symbol
+	prices := make(map[string]map[string]decimal.Decimal)
+	for key, ex := range exchanges {
+		p, err := ex.Prices()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("error getting prices from %s: %w", key, err))
+			continue
+		}
+		prices[key] = p
+	}
+
+	now := time.Now()
+	positions := append([]Position{}, futures...)
+
+	for _, h := range holdings {
+		accountPrices := prices[pricesKey(h.account)]
+
+		symbol := h.symbol
+		price, ok := accountPrices[symbol]
+		if !ok {
+			// No market for the asset, it can't be valued
+			continue
+		}
+
+		value, ok := exchange.USDTPrice(accountPrices, h.asset)
+		if !ok || h.quantity.Abs().Mul(value).LessThan(dustThreshold) {
+			continue
+		}
+
+		side := "LONG"
+		if h.quantity.IsNegative() {
+			side = "SHORT"
+		}
+
+		position := Position{
+			AccountID:   h.account.ID,
+			AccountName: h.account.Name,
+			Market:      h.market,
+			Symbol:      symbol,
+			BotIDs:      []int32{},
+			Position:    side,
+			Quantity:    h.quantity.Abs(),
+			Current:     price,
+		}
+		if h.liquidation.IsPositive() {
+			position.LiquidationPrice = &h.liquidation
+		}
+
+		key := positionKey{accountID: h.account.ID, market: h.market, symbol: symbol}
+		attribute(&position, botPositions[key], now)
+
+		positions = append(positions, position)
+	}
+
+	sort.Slice(positions, func(i, j int) bool {
+		if positions[i].AccountID != positions[j].AccountID {
+			return positions[i].AccountID < positions[j].AccountID
+		}
+		if positions[i].Market != positions[j].Market {
+			return positions[i].Market < positions[j].Market
+		}
+		return positions[i].Symbol < positions[j].Symbol
+	})
+
+	return positions, errors.Join(errs...)
+}
+
+// botPositions replays the fills of every bot of the user and returns what
+// each bot still holds, keyed by account, market and symbol
+func (s *Service) botPositions(ctx context.Context, userID int32) (map[positionKey][]botPosition, error) {
+	rows, err := s.db.Queries.GetUserAccountFills(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("error getting fills: %w", err)
+	}
+
+	type botKey struct {
+		accountID int32
+		market    orders.Market
+		botID     int32
+	}
+
+	fills := make(map[botKey][]stats.Fill)
+	names := make(map[int32]string)
+	for _, row := range rows {
+		key := botKey{accountID: row.BinanceAccountID, market: orders.Market(row.Market), botID: row.BotID.Int32}
+		fills[key] = append(fills[key], stats.Fill{
+			Symbol:          row.Symbol,
+			Side:            row.Side,
+			Price:           database.Decimal(row.Price),
+			Quantity:        database.Decimal(row.Quantity),
+			Commission:      database.Decimal(row.Commission),
+			CommissionAsset: row.CommissionAsset,
+			Time:            row.FilledAt.Time,
+			Step:            binance.LotStep(row.Symbol),
+		})
+		names[row.BotID.Int32] = row.BotName
+	}
+
+	positions := make(map[positionKey][]botPosition)
+	for key, botFills := range fills {
+		for _, open := range stats.OpenPositions(botFills) {
+			posKey := positionKey{accountID: key.accountID, market: key.market, symbol: open.Symbol}
+			positions[posKey] = append(positions[posKey], botPosition{
+				botID:    key.botID,
+				name:     names[key.botID],
+				Position: open,
+			})
+		}
+	}
+
+	return positions, nil
+}
+
+// attribute fills in entry price, PnL, holding time and owning bots from the
+// bot positions on the same side as the holding. PnL only covers the quantity
+// the bots account for, anything held beyond that has no known entry.
+func attribute(position *Position, candidates []botPosition, now time.Time) {
+	side := exchange.SideBuy
+	if position.Position == "SHORT" {
+		side = exchange.SideSell
+	}
+
+	quantity, cost := decimal.Zero, decimal.Zero
+	var names []string
+	var openedAt time.Time
+
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].OpenedAt.Before(candidates[j].OpenedAt)
+	})
+
+	for _, c := range candidates {
+		if c.Side != string(side) {
+			continue
+		}
+
+		quantity = quantity.Add(c.Quantity)
+		cost = cost.Add(c.EntryPrice.Mul(c.Quantity))
+		names = append(names, c.name)
+		position.BotIDs = append(position.BotIDs, c.botID)
+		if openedAt.IsZero() {
+			openedAt = c.OpenedAt
+		}
+	}
+
+	if quantity.IsZero() {
+		return
+	}
+
+	entry := cost.Div(quantity)
+	pnl := position.Current.Sub(entry).Mul(decimal.Min(quantity, position.Quantity))
+	if position.Position == "SHORT" {
+		pnl = pnl.Neg()
+	}
+	pnl = pnl.Round(2)
+
+	position.Bot = strings.Join(names, ", ")
+	position.Entry = &entry
+	position.Pnl = &pnl
+	position.OpenedAt = &openedAt
+	position.Time = formatDuration(now.Sub(openedAt))
+}
+
+// pricesKey is the exchange and environment of an account, the accounts
+// sharing it see the same prices
+func pricesKey(account db.GetUserBinanceAccountsRow) string {
+	return account.Exchange + " " + account.Environment
+}
+
+func fetchHoldings(ex exchange.Exchange, account db.GetUserBinanceAccountsRow) ([]holding, error) {
+	balances, err := ex.Balances()
+	if err != nil {
+		return nil, err
+	}
+
+	var holdings []holding
+	for _, b := range balances {
+		if b.Net.IsZero() {
+			continue
+		}
+
+		switch b.Market {
+		case exchange.MarketSpot, exchange.MarketMargin:
+			if b.Asset == quoteAsset {
+				continue
+			}
+			holdings = append(holdings, holding{account: account, market: orders.Market(b.Market), asset: b.Asset, symbol: b.Asset + quoteAsset, quantity: b.Net})
+		case exchange.MarketIsolated:
+			// The position of a pair is its base asset, the quote asset is
+			// what was paid or borrowed for it
+			if !strings.HasPrefix(b.Symbol, b.Asset) {
+				continue
+			}
+			holdings = append(holdings, holding{
+				account:     account,
+				market:      orders.MarketIsolated,
+				asset:       b.Asset,
+				symbol:      b.Symbol,
+				quantity:    b.Net,
+				liquidation: b.LiquidationPrice,
+			})
+		}
+	}
+
+	return holdings, nil
+}
+
+// fetchFuturesPositions lists the open futures positions. The exchange
+// reports entry price, mark price and unrealized PnL itself, so there are no
+// bot fills to replay. It doesn't say when a position was opened.
+func fetchFuturesPositions(ex exchange.Exchange, account db.GetUserBinanceAccountsRow) ([]Position, error) {
+	open, err := ex.Positions()
+	if err != nil {
+		return nil, err
+	}
+
+	var positions []Position
+	for _, p := range open {
+		if p.Quantity.Mul(p.MarkPrice).LessThan(dustThreshold) {
+			continue
+		}
+
+		entry := p.EntryPrice
+		pnl := p.UnrealizedPnl.Round(2)
+
+		position := Position{
+			AccountID:   account.ID,
+			AccountName: account.Name,
+			Market:      orders.MarketFutures,
+			Symbol:      p.Symbol,
+			BotIDs:      []int32{},
+			Position:    p.Side,
+			Quantity:    p.Quantity,
+			Entry:       &entry,
+			Current:     p.MarkPrice,
+			Pnl:         &pnl,
+			Leverage:    p.Leverage,
+		}
+
+		if p.LiquidationPrice.IsPositive() {
+			liquidation := p.LiquidationPrice
+			position.LiquidationPrice = &liquidation
+		}
+
+		positions = append(positions, position)
+	}
+
+	return positions, nil
+}
+
+// formatDuration renders a holding time like "3d 4h", "2h 45m" or "12m"
+func formatDuration(d time.Duration) string {
+	d = d.Round(time.Minute)
+	days := int(d.Hours()) / 24
+	hours := int(d.Hours()) % 24
+	minutes := int(d.Minutes()) % 60
+
+	switch {
+	case days > 0:
+		return fmt.Sprintf("%dd %dh", days, hours)
+	case hours > 0:
+		return fmt.Sprintf("%dh %dm", hours, minutes)
+	default:
+		return fmt.Sprintf("%dm", minutes)
+	}
+}
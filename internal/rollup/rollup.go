@@ -0,0 +1,159 @@
+package rollup
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"trade/internal/database"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const day = 24 * time.Hour
+
+// Policy is how long each resolution of balance history is kept. Zero keeps
+// it forever.
+type Policy struct {
+	Raw    time.Duration
+	Hourly time.Duration
+	Daily  time.Duration
+}
+
+// PolicyFromEnv reads BALANCE_RAW_RETENTION_DAYS (default 7),
+// BALANCE_HOURLY_RETENTION_DAYS (default 90) and
+// BALANCE_DAILY_RETENTION_DAYS (default 0, forever)
+func PolicyFromEnv() Policy {
+	return Policy{
+		Raw:    retentionFromEnv("BALANCE_RAW_RETENTION_DAYS", 7),
+		Hourly: retentionFromEnv("BALANCE_HOURLY_RETENTION_DAYS", 90),
+		Daily:  retentionFromEnv("BALANCE_DAILY_RETENTION_DAYS", 0),
+	}
+}
+
+func retentionFromEnv(name string, defaultDays int) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return time.Duration(defaultDays) * day
+	}
+
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 0 {
+		fmt.Printf("invalid %s %q, using %d days\n", name, value, defaultDays)
+		return time.Duration(defaultDays) * day
+	}
+
+	return time.Duration(days) * day
+}
+
+// Compactor rolls minute snapshots up into hourly and daily buckets and
+// prunes each resolution according to the policy. Buckets are UTC hours and
+// days. Pruning keeps the hourly bucket that closes at each user's local
+// midnight, so their periods can still be measured from it.
+type Compactor struct {
+	db     *database.Database
+	policy Policy
+}
+
+func New(db *database.Database, policy Policy) *Compactor {
+	return &Compactor{db: db, policy: policy}
+}
+
+// Run compacts right away and then every hour until ctx is cancelled
+func (c *Compactor) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		if err := c.Compact(ctx); err != nil {
+			fmt.Printf("failed to compact balance history: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Compact updates the rollups from the bucket before the latest onwards, so
+// the current hour and day are refreshed on every run, then applies
+// retention. Rows are only deleted once the next resolution covers them.
+//
+// The latest bucket is the same for all accounts, a snapshot that commits
+// late into the previous hour, such as after a slow account, would otherwise
+// never be rolled into it.
+func (c *Compactor) Compact(ctx context.Context) error {
+	latestHourly, err := c.db.Queries.GetLatestHourlyBucket(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting latest hourly bucket: %w", err)
+	}
+
+	since := pgtype.Timestamptz{Time: latestHourly.Time.Add(-time.Hour), Valid: true}
+	if err := c.db.Queries.RollupHourlyBalances(ctx, since); err != nil {
+		return fmt.Errorf("error rolling up hourly balances: %w", err)
+	}
+
+	latestDaily, err := c.db.Queries.GetLatestDailyBucket(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting latest daily bucket: %w", err)
+	}
+
+	since = pgtype.Timestamptz{Time: latestDaily.Time.AddDate(0, 0, -1), Valid: true}
+	if err := c.db.Queries.RollupDailyBalances(ctx, since); err != nil {
+		return fmt.Errorf("error rolling up daily balances: %w", err)
+	}
+
+	// Buckets written by this run
+	latestHourly, err = c.db.Queries.GetLatestHourlyBucket(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting latest hourly bucket: %w", err)
+	}
+
+	latestDaily, err = c.db.Queries.GetLatestDailyBucket(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting latest daily bucket: %w", err)
+	}
+
+	now := time.Now()
+
+	// The buckets rolled again next run still need their source rows
+	if cutoff, ok := cutoff(now, c.policy.Raw, latestHourly.Time.Add(-time.Hour)); ok {
+		if err := c.db.Queries.DeleteOldBalanceRecords(ctx, cutoff); err != nil {
+			return fmt.Errorf("error deleting old balance records: %w", err)
+		}
+	}
+
+	if cutoff, ok := cutoff(now, c.policy.Hourly, latestDaily.Time.AddDate(0, 0, -1)); ok {
+		if err := c.db.Queries.DeleteOldHourlyBalances(ctx, cutoff); err != nil {
+			return fmt.Errorf("error deleting old hourly balances: %w", err)
+		}
+	}
+
+	if c.policy.Daily > 0 {
+		cutoff := pgtype.Timestamptz{Time: now.Add(-c.policy.Daily), Valid: true}
+		if err := c.db.Queries.DeleteOldDailyBalances(ctx, cutoff); err != nil {
+			return fmt.Errorf("error deleting old daily balances: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// cutoff is now minus retention, but never later than the start of the
+// buckets of the coarser resolution that are still being filled
+func cutoff(now time.Time, retention time.Duration, coveredUntil time.Time) (pgtype.Timestamptz, bool) {
+	if retention == 0 {
+		return pgtype.Timestamptz{}, false
+	}
+
+	t := now.Add(-retention)
+	if coveredUntil.Before(t) {
+		t = coveredUntil
+	}
+
+	return pgtype.Timestamptz{Time: t, Valid: true}, true
+}
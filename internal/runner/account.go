@@ -0,0 +1,49 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"trade/internal/binance"
+)
+
+const accountCheckInterval = time.Minute
+
+// accountWorker is the worker of bots that trade on webhook signals. Signals
+// are handled by the server, so the worker only keeps checking that the
+// account is reachable. Revoked or restricted keys put the bot into ERROR
+// instead of failing every signal.
+type accountWorker struct {
+	client *binance.Client
+}
+
+// NewAccountWorker is a WorkerFactory for webhook driven bots
+func NewAccountWorker(bot Bot) (Worker, error) {
+	client, err := Client(bot)
+	if err != nil {
+		return nil, err
+	}
+	return &accountWorker{client: client}, nil
+}
+
+func (w *accountWorker) Run(ctx context.Context) error {
+	ticker := time.NewTicker(accountCheckInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := w.client.GetAccountInfo(); err != nil {
+			if errors.Is(err, binance.ErrUnauthorized) || errors.Is(err, binance.ErrForbidden) {
+				return Permanent(fmt.Errorf("account rejected the API key: %w", err))
+			}
+			return fmt.Errorf("error reaching account: %w", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
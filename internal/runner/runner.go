@@ -0,0 +1,363 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"trade/internal/binance"
+	"trade/internal/database"
+	db "trade/internal/db/sqlc"
+	"trade/internal/models"
+	"trade/internal/orders"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// How often bots.status is checked for transitions
+	pollInterval = 5 * time.Second
+
+	minBackoff = 5 * time.Second
+	maxBackoff = 5 * time.Minute
+
+	// A bot failing this many times in a row is put into ERROR
+	maxFailures = 5
+)
+
+type Bot = db.GetBotsForRunnerRow
+
+// Worker is what a running bot does. Run blocks until ctx is cancelled, which
+// is a clean stop, or until the bot fails.
+type Worker interface {
+	Run(ctx context.Context) error
+}
+
+// WorkerFactory builds the worker of a bot. An error puts the bot into ERROR.
+type WorkerFactory func(bot Bot) (Worker, error)
+
+// permanentError puts a bot into ERROR right away instead of restarting it
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent marks a worker error as one a restart won't fix
+func Permanent(err error) error {
+	return &permanentError{err: err}
+}
+
+// BotState is the runtime state of one supervised bot
+type BotState struct {
+	BotID     int32      `json:"bot_id"`
+	UserID    int32      `json:"user_id"`
+	Name      string     `json:"name"`
+	StartedAt time.Time  `json:"started_at"`
+	Failures  int        `json:"failures"`
+	LastError string     `json:"last_error,omitempty"`
+	FailedAt  *time.Time `json:"failed_at,omitempty"`
+}
+
+type run struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+	config string // what the worker was built from, a change restarts it
+	state  BotState
+}
+
+// finished reports whether the supervisor gave up on the bot
+func (r *run) finished() bool {
+	select {
+	case <-r.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Runner runs one supervised goroutine per RUNNING bot. It follows bots.status,
+// so RUNNING bots are resumed after a restart, PAUSED bots stop and keep their
+// orders and STOPPED bots stop and have their open orders cancelled. A bot
+// that keeps failing is put into ERROR with the reason.
+type Runner struct {
+	db        *database.Database
+	orders    *orders.Service
+	newWorker WorkerFactory
+
+	mu   sync.Mutex
+	runs map[int32]*run
+}
+
+func New(db *database.Database, newWorker WorkerFactory) *Runner {
+	return &Runner{
+		db:        db,
+		orders:    orders.New(db),
+		newWorker: newWorker,
+		runs:      make(map[int32]*run),
+	}
+}
+
+// Run follows bots.status until ctx is cancelled, then stops every bot and
+// waits for them. Bots keep their status, so they resume on the next start.
+func (r *Runner) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.sync(ctx); err != nil {
+			fmt.Printf("failed to sync bots: %v\n", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			r.stopAll()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// States returns the state of every bot that is running
+func (r *Runner) States() []BotState {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	states := make([]BotState, 0, len(r.runs))
+	for _, run := range r.runs {
+		states = append(states, run.state)
+	}
+
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].BotID < states[j].BotID
+	})
+
+	return states
+}
+
+// sync starts bots that became RUNNING, restarts bots whose configuration
+// changed and stops bots that left RUNNING or were deleted
+func (r *Runner) sync(ctx context.Context) error {
+	bots, err := r.db.Queries.GetBotsForRunner(ctx)
+	if err != nil {
+		return fmt.Errorf("error getting bots: %w", err)
+	}
+
+	seen := make(map[int32]bool, len(bots))
+	var errs []error
+
+	for _, bot := range bots {
+		seen[bot.ID] = true
+
+		r.mu.Lock()
+		existing := r.runs[bot.ID]
+		r.mu.Unlock()
+
+		status := models.BotStatus(bot.Status.String)
+
+		if status == models.BotStatusRunning {
+			if existing != nil && existing.config == config(bot) && !existing.finished() {
+				continue
+			}
+			if existing != nil {
+				fmt.Printf("bot %d changed or gave up, restarting\n", bot.ID)
+				r.stop(bot.ID)
+			}
+			r.start(ctx, bot)
+			continue
+		}
+
+		if existing == nil {
+			continue
+		}
+
+		r.stop(bot.ID)
+		fmt.Printf("bot %d is %s\n", bot.ID, status)
+
+		if status == models.BotStatusStopped {
+			if err := r.orders.CancelBotOrders(ctx, bot.ID); err != nil {
+				errs = append(errs, fmt.Errorf("bot %d: %w", bot.ID, err))
+			}
+		}
+	}
+
+	r.mu.Lock()
+	var removed []int32
+	for id := range r.runs {
+		if !seen[id] {
+			removed = append(removed, id)
+		}
+	}
+	r.mu.Unlock()
+
+	for _, id := range removed {
+		r.stop(id)
+		fmt.Printf("bot %d was deleted\n", id)
+	}
+
+	return errors.Join(errs...)
+}
+
+func (r *Runner) start(ctx context.Context, bot Bot) {
+	botCtx, cancel := context.WithCancel(ctx)
+	run := &run{
+		cancel: cancel,
+		done:   make(chan struct{}),
+		config: config(bot),
+		state: BotState{
+			BotID:     bot.ID,
+			UserID:    bot.UserID,
+			Name:      bot.Name,
+			StartedAt: time.Now(),
+		},
+	}
+
+	r.mu.Lock()
+	r.runs[bot.ID] = run
+	r.mu.Unlock()
+
+	fmt.Printf("starting bot %d (%s)\n", bot.ID, bot.Strategy)
+	go r.supervise(botCtx, bot, run)
+}
+
+// stop cancels the bot and waits for its worker to return
+func (r *Runner) stop(botID int32) {
+	r.mu.Lock()
+	run, ok := r.runs[botID]
+	delete(r.runs, botID)
+	r.mu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	run.cancel()
+	<-run.done
+}
+
+func (r *Runner) stopAll() {
+	r.mu.Lock()
+	ids := make([]int32, 0, len(r.runs))
+	for id := range r.runs {
+		ids = append(ids, id)
+	}
+	r.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, id := range ids {
+		wg.Add(1)
+		go func(id int32) {
+			defer wg.Done()
+			r.stop(id)
+		}(id)
+	}
+	wg.Wait()
+}
+
+// supervise runs the bot's worker until ctx is cancelled, restarting it with
+// backoff when it fails. Permanent errors and too many failures in a row put
+// the bot into ERROR.
+func (r *Runner) supervise(ctx context.Context, bot Bot, run *run) {
+	defer close(run.done)
+
+	failures := 0
+	for {
+		started := time.Now()
+		err := r.work(ctx, bot)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err == nil {
+			err = errors.New("worker stopped unexpectedly")
+		}
+
+		// A worker that ran for a while before failing starts counting again
+		if time.Since(started) > maxBackoff {
+			failures = 0
+		}
+		failures++
+
+		now := time.Now()
+		r.mu.Lock()
+		run.state.Failures = failures
+		run.state.LastError = err.Error()
+		run.state.FailedAt = &now
+		r.mu.Unlock()
+
+		var permanent *permanentError
+		if errors.As(err, &permanent) || failures >= maxFailures {
+			r.fail(ctx, bot, err)
+			return
+		}
+
+		wait := backoff(failures)
+		fmt.Printf("bot %d failed (%d in a row), restarting in %s: %v\n", bot.ID, failures, wait, err)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+// work builds and runs the worker once. A panic is turned into an error so
+// one bad bot can't take the runner down.
+func (r *Runner) work(ctx context.Context, bot Bot) (err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("panic: %v", p)
+		}
+	}()
+
+	worker, err := r.newWorker(bot)
+	if err != nil {
+		return Permanent(err)
+	}
+
+	return worker.Run(ctx)
+}
+
+// fail puts the bot into ERROR, unless its status was changed meanwhile
+func (r *Runner) fail(ctx context.Context, bot Bot, err error) {
+	fmt.Printf("bot %d stopped with error: %v\n", bot.ID, err)
+
+	setErr := r.db.Queries.SetBotError(context.WithoutCancel(ctx), db.SetBotErrorParams{
+		ID:           bot.ID,
+		StatusReason: pgtype.Text{String: err.Error(), Valid: true},
+	})
+	if setErr != nil {
+		fmt.Printf("failed to set bot %d to ERROR: %v\n", bot.ID, setErr)
+	}
+}
+
+// backoff doubles the wait per consecutive failure, from minBackoff up to maxBackoff
+func backoff(failures int) time.Duration {
+	wait := minBackoff
+	for i := 1; i < failures && wait < maxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, maxBackoff)
+}
+
+func config(bot Bot) string {
+	return fmt.Sprintf("%s|%s|%s|%s|%d|%s|%s|%s", bot.Strategy, bot.StrategyParams, bot.Mode, bot.Market, bot.BinanceAccountID.Int32, bot.ApiKey.String, bot.ApiSecret.String, binance.Environment(bot.Environment.String))
+}
+
+// Client creates the Binance client of the bot's account
+func Client(bot Bot) (*binance.Client, error) {
+	if !bot.ApiKey.Valid {
+		return nil, errors.New("bot has no active Binance account")
+	}
+	return binance.NewFromEncrypted(bot.ApiKey.String, bot.ApiSecret.String, binance.Environment(bot.Environment.String))
+}
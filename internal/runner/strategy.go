@@ -0,0 +1,326 @@
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"trade/internal/binance"
+	"trade/internal/models"
+	"trade/internal/orders"
+	"trade/internal/strategy"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	// Binance needs a moment after the close to serve the closed candle
+	candleCloseDelay = 2 * time.Second
+
+	// How long to wait before asking again when the closed candle wasn't
+	// served yet
+	candleRetryDelay = 5 * time.Second
+
+	tickInterval = 5 * time.Second
+)
+
+// strategyWorker feeds a strategy the candles and prices it asks for and
+// places the orders it wants
+type strategyWorker struct {
+	bot      Bot
+	client   *binance.Client
+	orders   *orders.Service
+	strategy strategy.Strategy
+	feed     strategy.Feed
+	interval time.Duration
+
+	last time.Time // open time of the last candle fed to the strategy
+}
+
+// StrategyWorkers is a WorkerFactory that runs the bot's strategy. Strategies
+// that only react to signals are driven by the webhook, so their bots get an
+// account worker.
+func StrategyWorkers(svc *orders.Service) WorkerFactory {
+	return func(bot Bot) (Worker, error) {
+		strat, err := strategy.New(bot.Strategy, bot.StrategyParams)
+		if err != nil {
+			return nil, err
+		}
+
+		feed := strat.Feed()
+		if feed.Interval == "" && !feed.Ticks {
+			return NewAccountWorker(bot)
+		}
+
+		client, err := Client(bot)
+		if err != nil {
+			return nil, err
+		}
+
+		w := &strategyWorker{
+			bot:      bot,
+			client:   client,
+			orders:   svc,
+			strategy: strat,
+			feed:     feed,
+		}
+		if feed.Interval != "" {
+			interval, ok := binance.IntervalDuration(feed.Interval)
+			if !ok {
+				return nil, fmt.Errorf("strategy %s wants unknown interval %q", bot.Strategy, feed.Interval)
+			}
+			w.interval = interval
+		}
+
+		return w, nil
+	}
+}
+
+func (w *strategyWorker) Run(ctx context.Context) error {
+	if err := w.warmup(); err != nil {
+		return err
+	}
+
+	if err := w.restorePosition(ctx); err != nil {
+		return err
+	}
+
+	var ticks <-chan time.Time
+	if w.feed.Ticks {
+		ticker := time.NewTicker(tickInterval)
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+
+	var candles <-chan time.Time
+	for {
+		var timer *time.Timer
+		if w.interval > 0 {
+			timer = time.NewTimer(max(time.Until(w.nextClose()), candleRetryDelay))
+			candles = timer.C
+		}
+
+		select {
+		case <-ctx.Done():
+			if timer != nil {
+				timer.Stop()
+			}
+			return nil
+		case <-ticks:
+			if timer != nil {
+				timer.Stop()
+			}
+			if err := w.tick(ctx); err != nil {
+				return err
+			}
+		case <-candles:
+			if err := w.poll(ctx); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// warmup replays the latest closed candles so indicators are ready. Intents
+// are discarded, the bot only acts on candles closing from now on.
+func (w *strategyWorker) warmup() error {
+	if w.interval == 0 || w.feed.Warmup <= 0 {
+		return nil
+	}
+
+	// One more, as the latest kline is usually still open
+	klines, err := w.client.GetKlines(w.feed.Symbol, w.feed.Interval, time.Time{}, time.Time{}, w.feed.Warmup+1)
+	if err != nil {
+		return w.marketDataError(err)
+	}
+
+	_, err = w.feedClosed(klines)
+	return err
+}
+
+// restorePosition tells the strategy what the bot holds of the symbol it
+// trades. The warmup candles moved the strategy as if it had traded on them.
+func (w *strategyWorker) restorePosition(ctx context.Context) error {
+	positioned, ok := w.strategy.(strategy.Positioned)
+	if !ok {
+		return nil
+	}
+
+	open, err := w.orders.BotPositions(ctx, w.bot.ID)
+	if err != nil {
+		return err
+	}
+
+	side, quantity := binance.SideBuy, decimal.Zero
+	for _, p := range open {
+		if p.Symbol == w.feed.Symbol {
+			side, quantity = binance.OrderSide(p.Side), p.Quantity
+		}
+	}
+
+	positioned.SetPosition(w.feed.Symbol, side, quantity)
+	return nil
+}
+
+// poll feeds the candles that closed since the last one to the strategy
+func (w *strategyWorker) poll(ctx context.Context) error {
+	start := time.Time{}
+	limit := 2
+	if !w.last.IsZero() {
+		start = w.last.Add(w.interval)
+		limit = 0
+	}
+
+	klines, err := w.client.GetKlines(w.feed.Symbol, w.feed.Interval, start, time.Time{}, limit)
+	if err != nil {
+		return w.marketDataError(err)
+	}
+
+	intents, err := w.feedClosed(klines)
+	if err != nil {
+		return err
+	}
+
+	return w.act(ctx, intents)
+}
+
+func (w *strategyWorker) tick(ctx context.Context) error {
+	price, err := w.client.GetPrice(w.feed.Symbol)
+	if err != nil {
+		return w.marketDataError(err)
+	}
+
+	p, err := decimal.NewFromString(price.Price)
+	if err != nil {
+		return fmt.Errorf("error parsing %s price: %w", w.feed.Symbol, err)
+	}
+
+	intents := w.strategy.OnTick(strategy.Tick{Symbol: w.feed.Symbol, Price: p, Time: time.Now()})
+	return w.act(ctx, intents)
+}
+
+// feedClosed feeds the closed klines that are newer than the last one fed
+func (w *strategyWorker) feedClosed(klines []binance.Kline) ([]strategy.Intent, error) {
+	now := time.Now()
+
+	var intents []strategy.Intent
+	for _, k := range klines {
+		if k.CloseTime.After(now) || !k.OpenTime.After(w.last) {
+			continue
+		}
+
+		candle, err := strategy.CandleFromKline(w.feed.Symbol, w.feed.Interval, k)
+		if err != nil {
+			return nil, err
+		}
+
+		intents = append(intents, w.strategy.OnCandle(candle)...)
+		w.last = k.OpenTime
+	}
+
+	return intents, nil
+}
+
+// nextClose is when the candle after the last one fed closes
+func (w *strategyWorker) nextClose() time.Time {
+	if w.last.IsZero() {
+		return time.Now().Truncate(w.interval).Add(w.interval + candleCloseDelay)
+	}
+	return w.last.Add(2*w.interval + candleCloseDelay)
+}
+
+// act places the orders the strategy asked for, on the paper exchange when
+// the bot is in paper mode. Rejected orders are logged and skipped, so one
+// bad order doesn't stop the bot.
+func (w *strategyWorker) act(ctx context.Context, intents []strategy.Intent) error {
+	for _, intent := range intents {
+		if intent.Percent.IsPositive() {
+			fmt.Printf("bot %d: %s %s%% %s rejected: percent sizing is only supported for webhook signals\n", w.bot.ID, intent.Side, intent.Percent, intent.Symbol)
+			continue
+		}
+		if !intent.Quantity.IsPositive() {
+			fmt.Printf("bot %d: %s %s rejected: no quantity\n", w.bot.ID, intent.Side, intent.Symbol)
+			continue
+		}
+
+		quantity, err := w.size(ctx, intent)
+		if err != nil {
+			return err
+		}
+		if !quantity.IsPositive() {
+			fmt.Printf("bot %d: %s %s skipped: what the bot holds is below the lot step\n", w.bot.ID, intent.Side, intent.Symbol)
+			continue
+		}
+
+		order := binance.OrderRequest{
+			Symbol:   intent.Symbol,
+			Side:     intent.Side,
+			Type:     intent.Type,
+			Quantity: quantity.String(),
+		}
+		if order.Type == binance.OrderTypeLimit {
+			order.TimeInForce = binance.TimeInForceGTC
+			order.Price = intent.Price.String()
+		}
+
+		var res binance.Order
+		if models.BotMode(w.bot.Mode) == models.BotModePaper {
+			res, err = w.orders.SubmitPaper(ctx, w.client, w.bot.ID, w.bot.BinanceAccountID.Int32, order)
+		} else {
+			res, err = w.orders.Submit(ctx, w.client, w.bot.ID, w.bot.BinanceAccountID.Int32, orders.Market(w.bot.Market), order)
+		}
+		if err != nil {
+			switch {
+			case errors.Is(err, binance.ErrUnauthorized) || errors.Is(err, binance.ErrForbidden):
+				return Permanent(fmt.Errorf("account rejected the API key: %w", err))
+			case errors.Is(err, binance.ErrInvalidOrder), errors.Is(err, binance.ErrInvalidSymbol),
+				errors.Is(err, binance.ErrInsufficientBalance), errors.Is(err, binance.ErrOrderRejected):
+				fmt.Printf("bot %d: %s %s %s rejected: %v\n", w.bot.ID, intent.Side, order.Quantity, intent.Symbol, err)
+				// The strategy took the order as done, tell it what the bot
+				// still holds
+				if err := w.restorePosition(ctx); err != nil {
+					return err
+				}
+				continue
+			}
+			return fmt.Errorf("error placing %s %s: %w", intent.Side, intent.Symbol, err)
+		}
+
+		fmt.Printf("bot %d: placed %s %s %s (order %d), %s\n", w.bot.ID, intent.Side, order.Quantity, intent.Symbol, res.OrderID, intent.Reason)
+	}
+
+	return nil
+}
+
+// size is the quantity to order for intent. An order against the bot's open
+// position is an exit and trades at most what the bot's fills hold, rounded
+// down to the lot step, since a buy fee paid in the base asset leaves less
+// than the strategy bought.
+func (w *strategyWorker) size(ctx context.Context, intent strategy.Intent) (decimal.Decimal, error) {
+	open, err := w.orders.BotPositions(ctx, w.bot.ID)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	for _, p := range open {
+		if p.Symbol != intent.Symbol || p.Side == string(intent.Side) {
+			continue
+		}
+
+		quantity := decimal.Min(intent.Quantity, p.Quantity)
+		if step := binance.LotStep(intent.Symbol); step.IsPositive() {
+			quantity = quantity.Div(step).Floor().Mul(step)
+		}
+		return quantity, nil
+	}
+
+	return intent.Quantity, nil
+}
+
+func (w *strategyWorker) marketDataError(err error) error {
+	if errors.Is(err, binance.ErrInvalidSymbol) {
+		return Permanent(fmt.Errorf("strategy symbol %s: %w", w.feed.Symbol, err))
+	}
+	return fmt.Errorf("error getting %s market data: %w", w.feed.Symbol, err)
+}
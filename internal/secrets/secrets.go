@@ -0,0 +1,251 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Secrets are stored as prefix:keyID:wrappedDataKey:ciphertext. Every value
+// gets its own data key, which is encrypted with the master key.
+const prefix = "enc:v1"
+
+var (
+	ErrNoMasterKey  = errors.New("SECRETS_MASTER_KEY is empty")
+	ErrNotEncrypted = errors.New("value is not encrypted, run cmd/rotate-secrets")
+	ErrUnknownKey   = errors.New("value is encrypted with an unknown master key")
+)
+
+type masterKey struct {
+	id  string
+	key []byte
+}
+
+// loadKeys reads the current master key from SECRETS_MASTER_KEY and the keys
+// it replaced from SECRETS_OLD_MASTER_KEYS (comma separated). Keys are base64
+// encoded 32 byte AES-256 keys.
+func loadKeys() (masterKey, []masterKey, error) {
+	current, err := parseKey(os.Getenv("SECRETS_MASTER_KEY"))
+	if err != nil {
+		return masterKey{}, nil, err
+	}
+
+	var old []masterKey
+	for _, encoded := range strings.Split(os.Getenv("SECRETS_OLD_MASTER_KEYS"), ",") {
+		if strings.TrimSpace(encoded) == "" {
+			continue
+		}
+		key, err := parseKey(encoded)
+		if err != nil {
+			return masterKey{}, nil, fmt.Errorf("SECRETS_OLD_MASTER_KEYS: %w", err)
+		}
+		old = append(old, key)
+	}
+
+	return current, old, nil
+}
+
+func parseKey(encoded string) (masterKey, error) {
+	encoded = strings.TrimSpace(encoded)
+	if encoded == "" {
+		return masterKey{}, ErrNoMasterKey
+	}
+
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return masterKey{}, fmt.Errorf("error decoding master key: %v", err)
+	}
+	if len(key) != 32 {
+		return masterKey{}, fmt.Errorf("master key must be 32 bytes, got %d", len(key))
+	}
+
+	sum := sha256.Sum256(key)
+	return masterKey{id: hex.EncodeToString(sum[:4]), key: key}, nil
+}
+
+// GenerateMasterKey returns a new base64 encoded master key
+func GenerateMasterKey() (string, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return "", fmt.Errorf("error generating master key: %v", err)
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, prefix+":")
+}
+
+// Encrypt seals plaintext with a fresh data key wrapped by the current master key
+func Encrypt(plaintext string) (string, error) {
+	current, _, err := loadKeys()
+	if err != nil {
+		return "", err
+	}
+	return encrypt(current, plaintext)
+}
+
+// Decrypt opens a value sealed by Encrypt with the current or an old master key
+func Decrypt(value string) (string, error) {
+	current, old, err := loadKeys()
+	if err != nil {
+		return "", err
+	}
+	return decrypt(append([]masterKey{current}, old...), value)
+}
+
+// Rotate re-encrypts value with the current master key. Plaintext values are
+// encrypted, values already under the current key are returned unchanged.
+func Rotate(value string) (rotated string, changed bool, err error) {
+	current, old, err := loadKeys()
+	if err != nil {
+		return "", false, err
+	}
+
+	plaintext := value
+	if IsEncrypted(value) {
+		if keyID(value) == current.id {
+			return value, false, nil
+		}
+		plaintext, err = decrypt(old, value)
+		if err != nil {
+			return "", false, err
+		}
+	}
+
+	rotated, err = encrypt(current, plaintext)
+	if err != nil {
+		return "", false, err
+	}
+	return rotated, true, nil
+}
+
+// Mask hides all but the first and last four characters of an API key
+func Mask(key string) string {
+	if len(key) <= 8 {
+		return "****"
+	}
+	return key[:4] + "****" + key[len(key)-4:]
+}
+
+func encrypt(master masterKey, plaintext string) (string, error) {
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return "", fmt.Errorf("error generating data key: %v", err)
+	}
+
+	wrappedKey, err := seal(master.key, dataKey)
+	if err != nil {
+		return "", err
+	}
+
+	ciphertext, err := seal(dataKey, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Join([]string{
+		prefix,
+		master.id,
+		base64.StdEncoding.EncodeToString(wrappedKey),
+		base64.StdEncoding.EncodeToString(ciphertext),
+	}, ":"), nil
+}
+
+func decrypt(keys []masterKey, value string) (string, error) {
+	if !IsEncrypted(value) {
+		return "", ErrNotEncrypted
+	}
+
+	parts := strings.Split(strings.TrimPrefix(value, prefix+":"), ":")
+	if len(parts) != 3 {
+		return "", errors.New("malformed encrypted value")
+	}
+
+	var master *masterKey
+	for i := range keys {
+		if keys[i].id == parts[0] {
+			master = &keys[i]
+			break
+		}
+	}
+	if master == nil {
+		return "", ErrUnknownKey
+	}
+
+	wrappedKey, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return "", fmt.Errorf("error decoding data key: %v", err)
+	}
+
+	ciphertext, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("error decoding ciphertext: %v", err)
+	}
+
+	dataKey, err := open(master.key, wrappedKey)
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := open(dataKey, ciphertext)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
+
+func keyID(value string) string {
+	parts := strings.Split(strings.TrimPrefix(value, prefix+":"), ":")
+	return parts[0]
+}
+
+// seal encrypts with AES-GCM and prepends the nonce
+func seal(key, plaintext []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("error generating nonce: %v", err)
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(key, sealed []byte) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(sealed) < gcm.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+
+	nonce, ciphertext := sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error decrypting: %v", err)
+	}
+
+	return plaintext, nil
+}
+
+func newGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("error creating cipher: %v", err)
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,176 @@
+package stats
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// AccountBalance is one balance snapshot of an account
+type AccountBalance struct {
+	AccountID int32
+	Time      time.Time
+	Balance   decimal.Decimal
+}
+
+type EquityPoint struct {
+	Time   time.Time       `json:"time"`
+	Equity decimal.Decimal `json:"equity"`
+}
+
+// EquityCurve sums account balances per timestamp. An account missing a
+// timestamp keeps its last known balance, so gaps don't show up as losses.
+func EquityCurve(balances []AccountBalance) []EquityPoint {
+	sorted := make([]AccountBalance, len(balances))
+	copy(sorted, balances)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+
+	latest := make(map[int32]decimal.Decimal)
+	var curve []EquityPoint
+
+	for i, b := range sorted {
+		latest[b.AccountID] = b.Balance
+
+		// Emit once all balances for this timestamp are applied
+		if i+1 < len(sorted) && sorted[i+1].Time.Equal(b.Time) {
+			continue
+		}
+
+		total := decimal.Zero
+		for _, balance := range latest {
+			total = total.Add(balance)
+		}
+		curve = append(curve, EquityPoint{Time: b.Time, Equity: total})
+	}
+
+	return curve
+}
+
+// MaxDrawdown returns the largest peak to trough decline of the curve in percent
+func MaxDrawdown(curve []EquityPoint) decimal.Decimal {
+	maxDrawdown := decimal.Zero
+	peak := decimal.Zero
+
+	for _, p := range curve {
+		if p.Equity.GreaterThan(peak) {
+			peak = p.Equity
+			continue
+		}
+
+		if peak.IsPositive() {
+			drawdown := peak.Sub(p.Equity).Div(peak).Mul(decimal.NewFromInt(100))
+			if drawdown.GreaterThan(maxDrawdown) {
+				maxDrawdown = drawdown
+			}
+		}
+	}
+
+	return maxDrawdown.Round(2)
+}
+
+// AnnualizedReturn compounds the return from start to end over a year, in percent.
+// Periods shorter than a day return the plain return.
+func AnnualizedReturn(start, end decimal.Decimal, elapsed time.Duration) decimal.Decimal {
+	if !start.IsPositive() || end.IsNegative() {
+		return decimal.Zero
+	}
+
+	growth := end.Div(start).InexactFloat64()
+	if elapsed < 24*time.Hour {
+		return decimal.NewFromFloat((growth - 1) * 100).Round(2)
+	}
+
+	years := elapsed.Hours() / (24 * 365)
+	annualized := math.Pow(growth, 1/years) - 1
+	if math.IsInf(annualized, 0) || math.IsNaN(annualized) {
+		return decimal.Zero
+	}
+
+	return decimal.NewFromFloat(annualized * 100).Round(2)
+}
+
+// FillForward samples the curve on a grid from from to to, every step. Each
+// grid point takes the last value at or before it, so gaps repeat the previous
+// equity. Grid points before the first value are left out.
+func FillForward(curve []EquityPoint, from, to time.Time, step time.Duration) []EquityPoint {
+	var filled []EquityPoint
+	next := 0
+	var last *EquityPoint
+
+	for t := from.Truncate(step); !t.After(to); t = t.Add(step) {
+		for next < len(curve) && !curve[next].Time.After(t) {
+			last = &curve[next]
+			next++
+		}
+
+		if last != nil {
+			filled = append(filled, EquityPoint{Time: t, Equity: last.Equity})
+		}
+	}
+
+	return filled
+}
+
+// CashFlow is money moved into (positive) or out of (negative) the equity
+// tracked by a curve
+type CashFlow struct {
+	Time   time.Time
+	Amount decimal.Decimal
+}
+
+// TimeWeightedReturn chains the returns between consecutive points of the
+// curve, in percent. Flows between two points are treated as arriving right
+// after the first one, so deposits and withdrawals don't count as profit or
+// loss. Intervals starting from nothing have no return and are skipped.
+func TimeWeightedReturn(curve []EquityPoint, flows []CashFlow) decimal.Decimal {
+	growth := GrowthCurve(curve, flows)
+	if len(growth) == 0 {
+		return decimal.Zero
+	}
+
+	return growth[len(growth)-1].Equity.Sub(decimal.NewFromInt(1)).Mul(decimal.NewFromInt(100)).Round(2)
+}
+
+// GrowthCurve is what one unit held at the start of the curve grows to at
+// each of its points, chaining the returns like TimeWeightedReturn. Flows
+// don't move it, so drawdowns and returns read from it aren't distorted by
+// deposits and withdrawals.
+func GrowthCurve(curve []EquityPoint, flows []CashFlow) []EquityPoint {
+	if len(curve) == 0 {
+		return nil
+	}
+
+	sortedFlows := make([]CashFlow, len(flows))
+	copy(sortedFlows, flows)
+	sort.SliceStable(sortedFlows, func(i, j int) bool {
+		return sortedFlows[i].Time.Before(sortedFlows[j].Time)
+	})
+
+	growth := decimal.NewFromInt(1)
+	points := []EquityPoint{{Time: curve[0].Time, Equity: growth}}
+	next := 0
+
+	// Flows before the curve starts are already part of its first value
+	for next < len(sortedFlows) && !sortedFlows[next].Time.After(curve[0].Time) {
+		next++
+	}
+
+	for i := 1; i < len(curve); i++ {
+		start := curve[i-1].Equity
+		for next < len(sortedFlows) && !sortedFlows[next].Time.After(curve[i].Time) {
+			start = start.Add(sortedFlows[next].Amount)
+			next++
+		}
+
+		if start.IsPositive() {
+			growth = growth.Mul(curve[i].Equity.Div(start))
+		}
+		points = append(points, EquityPoint{Time: curve[i].Time, Equity: growth})
+	}
+
+	return points
+}
@@ -0,0 +1,265 @@
+package stats
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// maxProfitFactor is used when a bot has winning trades but no losing ones
+var maxProfitFactor = decimal.NewFromFloat(999.99)
+
+type Fill struct {
+	Symbol          string
+	Side            string
+	Price           decimal.Decimal
+	Quantity        decimal.Decimal
+	Commission      decimal.Decimal
+	CommissionAsset string
+	Time            time.Time
+	// Step is the lot step of the symbol. A position left with less than
+	// a step can't be sold and counts as closed. Zero when unknown.
+	Step decimal.Decimal
+}
+
+// Fee returns the commission in the quote asset of the symbol.
+// Commissions paid in a third asset (e.g. BNB) cannot be valued from the
+// fill alone and count as zero.
+func (f Fill) Fee() decimal.Decimal {
+	switch {
+	case f.CommissionAsset == "" || f.Commission.IsZero():
+		return decimal.Zero
+	case strings.HasSuffix(f.Symbol, f.CommissionAsset):
+		return f.Commission
+	case strings.HasPrefix(f.Symbol, f.CommissionAsset):
+		return f.Commission.Mul(f.Price)
+	default:
+		return decimal.Zero
+	}
+}
+
+// Held is the base quantity the fill moves. A commission paid in the base
+// asset comes out of what a buy brings in and on top of what a sell gives up.
+func (f Fill) Held() decimal.Decimal {
+	if f.CommissionAsset == "" || strings.HasSuffix(f.Symbol, f.CommissionAsset) || !strings.HasPrefix(f.Symbol, f.CommissionAsset) {
+		return f.Quantity
+	}
+	if f.Side == "BUY" {
+		return f.Quantity.Sub(f.Commission)
+	}
+	return f.Quantity.Add(f.Commission)
+}
+
+// Trade is a closed round trip: a position opened from flat and closed back to flat
+type Trade struct {
+	Symbol   string
+	Side     string
+	OpenedAt time.Time
+	ClosedAt time.Time
+	Pnl      decimal.Decimal
+}
+
+type Stats struct {
+	Trades       int32
+	Wins         int32
+	WinRate      decimal.Decimal // in percent
+	ProfitFactor decimal.Decimal
+	RealizedPnl  decimal.Decimal
+	GrossProfit  decimal.Decimal
+	GrossLoss    decimal.Decimal
+}
+
+// lot is the open remainder of an entry fill
+type lot struct {
+	price    decimal.Decimal
+	quantity decimal.Decimal
+	feeLeft  decimal.Decimal
+}
+
+type position struct {
+	side     string
+	lots     []lot
+	openedAt time.Time
+	pnl      decimal.Decimal
+}
+
+func (p *position) quantity() decimal.Decimal {
+	total := decimal.Zero
+	for _, l := range p.lots {
+		total = total.Add(l.quantity)
+	}
+	return total
+}
+
+// Position is what is left open after replaying fills
+type Position struct {
+	Symbol     string
+	Side       string
+	Quantity   decimal.Decimal
+	EntryPrice decimal.Decimal // volume weighted over the open lots
+	OpenedAt   time.Time
+}
+
+// RoundTrips pairs entries with exits FIFO per symbol, including fees.
+// A sell with no open long opens a short, which later buys close. Quantities
+// are what the fills held, see Fill.Held.
+func RoundTrips(fills []Fill) []Trade {
+	trades, _ := replay(fills)
+	return trades
+}
+
+// OpenPositions returns the positions the fills leave open, one per symbol
+func OpenPositions(fills []Fill) []Position {
+	_, positions := replay(fills)
+
+	var open []Position
+	for symbol, pos := range positions {
+		if len(pos.lots) == 0 {
+			continue
+		}
+
+		quantity, cost := decimal.Zero, decimal.Zero
+		for _, l := range pos.lots {
+			quantity = quantity.Add(l.quantity)
+			cost = cost.Add(l.price.Mul(l.quantity))
+		}
+
+		open = append(open, Position{
+			Symbol:     symbol,
+			Side:       pos.side,
+			Quantity:   quantity,
+			EntryPrice: cost.Div(quantity),
+			OpenedAt:   pos.openedAt,
+		})
+	}
+
+	sort.Slice(open, func(i, j int) bool {
+		return open[i].Symbol < open[j].Symbol
+	})
+
+	return open
+}
+
+func replay(fills []Fill) ([]Trade, map[string]*position) {
+	sorted := make([]Fill, len(fills))
+	copy(sorted, fills)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.Before(sorted[j].Time)
+	})
+
+	positions := make(map[string]*position)
+	var trades []Trade
+
+	for _, f := range sorted {
+		quantity := f.Held()
+		if !quantity.IsPositive() {
+			continue
+		}
+
+		pos, ok := positions[f.Symbol]
+		if !ok || len(pos.lots) == 0 {
+			pos = &position{side: f.Side, openedAt: f.Time}
+			positions[f.Symbol] = pos
+		}
+
+		if f.Side == pos.side {
+			pos.lots = append(pos.lots, lot{price: f.Price, quantity: quantity, feeLeft: f.Fee()})
+			continue
+		}
+
+		// Exit fill, close lots FIFO
+		remaining := quantity
+		exitFee := f.Fee()
+		for remaining.IsPositive() && len(pos.lots) > 0 {
+			entry := &pos.lots[0]
+			qty := decimal.Min(remaining, entry.quantity)
+
+			gross := f.Price.Sub(entry.price).Mul(qty)
+			if pos.side == "SELL" {
+				gross = gross.Neg()
+			}
+
+			entryFee := entry.feeLeft.Mul(qty).Div(entry.quantity)
+			pos.pnl = pos.pnl.Add(gross).Sub(entryFee).Sub(exitFee.Mul(qty).Div(quantity))
+
+			entry.feeLeft = entry.feeLeft.Sub(entryFee)
+			entry.quantity = entry.quantity.Sub(qty)
+			remaining = remaining.Sub(qty)
+
+			if !entry.quantity.IsPositive() {
+				pos.lots = pos.lots[1:]
+			}
+		}
+
+		// Dust below the step can't be sold, what is left of the exit can't
+		// open a position either
+		if pos.quantity().LessThan(f.Step) {
+			pos.lots = nil
+		}
+		if remaining.LessThan(f.Step) {
+			remaining = decimal.Zero
+		}
+
+		if len(pos.lots) == 0 {
+			trades = append(trades, Trade{
+				Symbol:   f.Symbol,
+				Side:     pos.side,
+				OpenedAt: pos.openedAt,
+				ClosedAt: f.Time,
+				Pnl:      pos.pnl,
+			})
+
+			// Whatever is left of the exit flips the position
+			if remaining.IsPositive() {
+				share := remaining.Div(quantity)
+				positions[f.Symbol] = &position{
+					side:     f.Side,
+					openedAt: f.Time,
+					lots:     []lot{{price: f.Price, quantity: remaining, feeLeft: exitFee.Mul(share)}},
+				}
+			}
+		}
+	}
+
+	return trades, positions
+}
+
+func Compute(fills []Fill) Stats {
+	return Summarize(RoundTrips(fills))
+}
+
+func Summarize(trades []Trade) Stats {
+	s := Stats{
+		WinRate:      decimal.Zero,
+		ProfitFactor: decimal.Zero,
+		RealizedPnl:  decimal.Zero,
+		GrossProfit:  decimal.Zero,
+		GrossLoss:    decimal.Zero,
+	}
+
+	for _, t := range trades {
+		s.Trades++
+		s.RealizedPnl = s.RealizedPnl.Add(t.Pnl)
+		if t.Pnl.IsPositive() {
+			s.Wins++
+			s.GrossProfit = s.GrossProfit.Add(t.Pnl)
+		} else {
+			s.GrossLoss = s.GrossLoss.Add(t.Pnl.Abs())
+		}
+	}
+
+	if s.Trades > 0 {
+		s.WinRate = decimal.NewFromInt32(s.Wins).Mul(decimal.NewFromInt(100)).Div(decimal.NewFromInt32(s.Trades)).Round(2)
+	}
+
+	switch {
+	case s.GrossLoss.IsPositive():
+		s.ProfitFactor = decimal.Min(s.GrossProfit.Div(s.GrossLoss), maxProfitFactor).Round(2)
+	case s.GrossProfit.IsPositive():
+		s.ProfitFactor = maxProfitFactor
+	}
+
+	return s
+}
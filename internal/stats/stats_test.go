@@ -0,0 +1,212 @@
+package stats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+func fill(minute int, side, quantity, price, commission, asset string) Fill {
+	f := Fill{
+		Symbol:          "BTCUSDT",
+		Side:            side,
+		Price:           decimal.RequireFromString(price),
+		Quantity:        decimal.RequireFromString(quantity),
+		Commission:      decimal.Zero,
+		CommissionAsset: asset,
+		Time:            time.Date(2025, 1, 1, 0, minute, 0, 0, time.UTC),
+	}
+	if commission != "" {
+		f.Commission = decimal.RequireFromString(commission)
+	}
+	return f
+}
+
+func withStep(f Fill, step string) Fill {
+	f.Step = decimal.RequireFromString(step)
+	return f
+}
+
+func TestRoundTrips(t *testing.T) {
+	tests := []struct {
+		name  string
+		fills []Fill
+		pnls  []string // per trade, rounded to 8 places
+		open  string   // quantity left open, empty when flat
+	}{
+		{
+			name: "fees in quote",
+			fills: []Fill{
+				fill(0, "BUY", "1", "100", "0.1", "USDT"),
+				fill(1, "SELL", "1", "110", "0.11", "USDT"),
+			},
+			pnls: []string{"9.79"},
+		},
+		{
+			// The buy brings in 0.999, which is what the sell sells
+			name: "buy fee in base",
+			fills: []Fill{
+				fill(0, "BUY", "1", "100", "0.001", "BTC"),
+				fill(1, "SELL", "0.999", "110", "0.10989", "USDT"),
+			},
+			pnls: []string{"9.78011"},
+		},
+		{
+			// The fee comes on top of the 0.999 sold, closing the whole lot
+			name: "sell fee in base",
+			fills: []Fill{
+				fill(0, "BUY", "1", "100", "0", "USDT"),
+				fill(1, "SELL", "0.999", "110", "0.001", "BTC"),
+			},
+			pnls: []string{"9.89"},
+		},
+		{
+			// BNB can't be valued from the fills, the fees count as zero
+			name: "fees in a third asset",
+			fills: []Fill{
+				fill(0, "BUY", "1", "100", "0.0002", "BNB"),
+				fill(1, "SELL", "1", "90", "0.0002", "BNB"),
+			},
+			pnls: []string{"-10"},
+		},
+		{
+			name: "partial fills",
+			fills: []Fill{
+				fill(0, "BUY", "1", "100", "", ""),
+				fill(1, "BUY", "1", "110", "", ""),
+				fill(2, "SELL", "0.5", "120", "", ""),
+				fill(3, "SELL", "1", "90", "", ""),
+			},
+			// 0.5 of the first lot is still open
+			pnls: nil,
+			open: "0.5",
+		},
+		{
+			name: "partial fills closed",
+			fills: []Fill{
+				fill(0, "BUY", "1", "100", "", ""),
+				fill(1, "BUY", "1", "110", "", ""),
+				fill(2, "SELL", "0.5", "120", "", ""),
+				fill(3, "SELL", "1.5", "90", "", ""),
+			},
+			// 0.5*20 + 0.5*-10 + 1*-20
+			pnls: []string{"-15"},
+		},
+		{
+			name: "position flip",
+			fills: []Fill{
+				fill(0, "BUY", "1", "100", "0.1", "USDT"),
+				fill(1, "SELL", "2", "110", "0.22", "USDT"),
+				fill(2, "BUY", "1", "100", "0.1", "USDT"),
+			},
+			// The long pays half the sell's fee, the short the other half
+			pnls: []string{"9.79", "9.79"},
+		},
+		{
+			name: "dust below the step",
+			fills: []Fill{
+				fill(0, "BUY", "1", "100", "0.001", "BTC"),
+				withStep(fill(1, "SELL", "0.99", "110", "", ""), "0.01"),
+			},
+			// 0.99*10 less 0.99/0.999 of the 0.1 buy fee
+			pnls: []string{"9.8009009"},
+		},
+		{
+			name: "exit left below the step doesn't flip",
+			fills: []Fill{
+				fill(0, "BUY", "1", "100", "", ""),
+				withStep(fill(1, "SELL", "1.005", "110", "", ""), "0.01"),
+			},
+			pnls: []string{"10"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			trades := RoundTrips(tt.fills)
+			if len(trades) != len(tt.pnls) {
+				t.Fatalf("got %d trades, want %d: %+v", len(trades), len(tt.pnls), trades)
+			}
+			for i, trade := range trades {
+				want := decimal.RequireFromString(tt.pnls[i])
+				if got := trade.Pnl.Round(8); !got.Equal(want) {
+					t.Errorf("trade %d pnl = %s, want %s", i, got, want)
+				}
+			}
+
+			open := OpenPositions(tt.fills)
+			switch {
+			case tt.open == "" && len(open) > 0:
+				t.Errorf("left %s %s open, want flat", open[0].Side, open[0].Quantity)
+			case tt.open != "" && len(open) != 1:
+				t.Errorf("left %d positions open, want one of %s", len(open), tt.open)
+			case tt.open != "" && !open[0].Quantity.Equal(decimal.RequireFromString(tt.open)):
+				t.Errorf("left %s open, want %s", open[0].Quantity, tt.open)
+			}
+		})
+	}
+}
+
+func TestCompute(t *testing.T) {
+	fills := []Fill{
+		// A win of 9.78011 with the buy fee in base
+		fill(0, "BUY", "1", "100", "0.001", "BTC"),
+		fill(1, "SELL", "0.999", "110", "0.10989", "USDT"),
+		// A loss of 10.19 with fees in quote
+		fill(2, "BUY", "1", "100", "0.1", "USDT"),
+		fill(3, "SELL", "1", "90", "0.09", "USDT"),
+		// A win of 5 on a short
+		fill(4, "SELL", "1", "100", "", ""),
+		fill(5, "BUY", "1", "95", "", ""),
+	}
+
+	got := Compute(fills)
+
+	if got.Trades != 3 || got.Wins != 2 {
+		t.Errorf("trades = %d, wins = %d, want 3 and 2", got.Trades, got.Wins)
+	}
+	for _, c := range []struct {
+		name string
+		got  decimal.Decimal
+		want string
+	}{
+		{"win rate", got.WinRate, "66.67"},
+		{"profit factor", got.ProfitFactor, "1.45"},
+		{"realized pnl", got.RealizedPnl, "4.59011"},
+		{"gross profit", got.GrossProfit, "14.78011"},
+		{"gross loss", got.GrossLoss, "10.19"},
+	} {
+		if !c.got.Equal(decimal.RequireFromString(c.want)) {
+			t.Errorf("%s = %s, want %s", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestGrowthCurveTakesOutFlows(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2025, 1, 1, hour, 0, 0, 0, time.UTC) }
+	point := func(hour int, equity int64) EquityPoint {
+		return EquityPoint{Time: at(hour), Equity: decimal.NewFromInt(equity)}
+	}
+
+	curve := []EquityPoint{point(0, 100), point(1, 200), point(2, 220), point(3, 110), point(4, 99)}
+	flows := []CashFlow{
+		{Time: at(1), Amount: decimal.NewFromInt(100)},  // deposit, no gain
+		{Time: at(3), Amount: decimal.NewFromInt(-110)}, // withdrawal, no loss
+	}
+
+	growth := GrowthCurve(curve, flows)
+
+	want := []string{"1", "1", "1.1", "1.1", "0.99"}
+	for i, p := range growth {
+		if !p.Equity.Equal(decimal.RequireFromString(want[i])) {
+			t.Errorf("growth at %s = %s, want %s", p.Time, p.Equity, want[i])
+		}
+	}
+	if got := MaxDrawdown(growth); !got.Equal(decimal.NewFromInt(10)) {
+		t.Errorf("max drawdown = %s, want 10", got)
+	}
+	if got := TimeWeightedReturn(curve, flows); !got.Equal(decimal.NewFromInt(-1)) {
+		t.Errorf("time-weighted return = %s, want -1", got)
+	}
+}
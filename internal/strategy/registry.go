@@ -0,0 +1,114 @@
+package strategy
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var ErrUnknownStrategy = errors.New("unknown strategy")
+
+// Definition describes a strategy that bots can use by name
+type Definition struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Schema      Schema `json:"schema"`
+
+	// New creates the strategy from the bot's JSON parameters, which have
+	// been checked against Schema. It returns a *ValidationError for checks
+	// the schema can't express.
+	New func(params json.RawMessage) (Strategy, error) `json:"-"`
+}
+
+var (
+	mu       sync.RWMutex
+	registry = make(map[string]Definition)
+)
+
+// Register makes a strategy available under its name. It panics on a
+// duplicate name, as that is a programming error.
+func Register(def Definition) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := registry[def.Name]; ok {
+		panic(fmt.Sprintf("strategy %q registered twice", def.Name))
+	}
+	registry[def.Name] = def
+}
+
+// Lookup returns the definition of a registered strategy
+func Lookup(name string) (Definition, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	def, ok := registry[name]
+	return def, ok
+}
+
+// Definitions returns every registered strategy sorted by name
+func Definitions() []Definition {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	defs := make([]Definition, 0, len(registry))
+	for _, def := range registry {
+		defs = append(defs, def)
+	}
+
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].Name < defs[j].Name
+	})
+
+	return defs
+}
+
+// New creates the named strategy. Empty params are treated as {}. Invalid
+// params are reported as a *ValidationError.
+func New(name string, params json.RawMessage) (Strategy, error) {
+	def, ok := Lookup(name)
+	if !ok {
+		return nil, fmt.Errorf("%w %q", ErrUnknownStrategy, name)
+	}
+
+	if len(bytes.TrimSpace(params)) == 0 || bytes.Equal(bytes.TrimSpace(params), []byte("null")) {
+		params = json.RawMessage("{}")
+	}
+
+	if errs := def.Schema.Validate(params); len(errs) > 0 {
+		return nil, &ValidationError{Strategy: name, Fields: errs}
+	}
+
+	strat, err := def.New(params)
+	if err != nil {
+		var verr *ValidationError
+		if errors.As(err, &verr) {
+			verr.Strategy = name
+		}
+		return nil, err
+	}
+
+	return strat, nil
+}
+
+// Validate checks that name is a registered strategy and params are valid for
+// it. Unknown strategies fail with ErrUnknownStrategy, invalid params with a
+// *ValidationError.
+func Validate(name string, params json.RawMessage) error {
+	_, err := New(name, params)
+	return err
+}
+
+// decodeParams decodes params strictly, so typos in field names are reported
+// instead of silently falling back to defaults
+func decodeParams(params json.RawMessage, into any) error {
+	dec := json.NewDecoder(bytes.NewReader(params))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(into); err != nil {
+		return fmt.Errorf("invalid params: %w", err)
+	}
+	return nil
+}
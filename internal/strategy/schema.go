@@ -0,0 +1,184 @@
+package strategy
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/shopspring/decimal"
+)
+
+// Schema is the JSON schema of a strategy's params. Only the subset of JSON
+// schema needed for flat parameter objects is supported, which is enough for
+// the dashboard to render a form.
+type Schema struct {
+	Type                 string              `json:"type"` // always "object"
+	Properties           map[string]Property `json:"properties"`
+	Required             []string            `json:"required,omitempty"`
+	AdditionalProperties bool                `json:"additionalProperties"`
+}
+
+// Property describes one parameter
+type Property struct {
+	Type             string    `json:"type"` // string, integer, number, boolean or array
+	Title            string    `json:"title,omitempty"`
+	Description      string    `json:"description,omitempty"`
+	Enum             []string  `json:"enum,omitempty"`
+	Default          any       `json:"default,omitempty"`
+	Minimum          *float64  `json:"minimum,omitempty"`
+	ExclusiveMinimum *float64  `json:"exclusiveMinimum,omitempty"`
+	Maximum          *float64  `json:"maximum,omitempty"`
+	MinLength        *int      `json:"minLength,omitempty"`
+	MinItems         *int      `json:"minItems,omitempty"`
+	Items            *Property `json:"items,omitempty"`
+}
+
+// FieldError is a problem with one parameter. Field is empty when the params
+// as a whole are wrong.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError lists every invalid parameter of a strategy
+type ValidationError struct {
+	Strategy string       `json:"strategy"`
+	Fields   []FieldError `json:"fields"`
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		if f.Field == "" {
+			msgs[i] = f.Message
+		} else {
+			msgs[i] = f.Field + " " + f.Message
+		}
+	}
+	return fmt.Sprintf("invalid params for strategy %s: %s", e.Strategy, strings.Join(msgs, "; "))
+}
+
+// invalidField is the error strategies return for checks the schema can't
+// express, such as one parameter depending on another
+func invalidField(field, format string, args ...any) error {
+	return &ValidationError{Fields: []FieldError{{Field: field, Message: fmt.Sprintf(format, args...)}}}
+}
+
+// Validate checks params against the schema and returns every field that
+// doesn't match, sorted by field name
+func (s Schema) Validate(params json.RawMessage) []FieldError {
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(params, &values); err != nil || values == nil {
+		return []FieldError{{Message: "params must be a JSON object"}}
+	}
+
+	var errs []FieldError
+	for _, name := range s.Required {
+		if _, ok := values[name]; !ok {
+			errs = append(errs, FieldError{Field: name, Message: "is required"})
+		}
+	}
+
+	for name, raw := range values {
+		prop, ok := s.Properties[name]
+		if !ok {
+			if !s.AdditionalProperties {
+				errs = append(errs, FieldError{Field: name, Message: "is not a parameter of this strategy"})
+			}
+			continue
+		}
+		errs = append(errs, prop.validate(name, raw)...)
+	}
+
+	sort.SliceStable(errs, func(i, j int) bool {
+		return errs[i].Field < errs[j].Field
+	})
+
+	return errs
+}
+
+func (p Property) validate(field string, raw json.RawMessage) []FieldError {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	var value any
+	if err := dec.Decode(&value); err != nil {
+		return []FieldError{{Field: field, Message: "is not valid JSON"}}
+	}
+
+	fail := func(format string, args ...any) []FieldError {
+		return []FieldError{{Field: field, Message: fmt.Sprintf(format, args...)}}
+	}
+
+	switch p.Type {
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			return fail("must be a string")
+		}
+		if p.MinLength != nil && len(strings.TrimSpace(str)) < *p.MinLength {
+			if *p.MinLength == 1 {
+				return fail("must not be empty")
+			}
+			return fail("must be at least %d characters", *p.MinLength)
+		}
+		if len(p.Enum) > 0 && !slices.Contains(p.Enum, str) {
+			return fail("must be one of %s", strings.Join(p.Enum, ", "))
+		}
+
+	case "integer", "number":
+		num, ok := value.(json.Number)
+		if !ok {
+			return fail("must be a %s", p.Type)
+		}
+		d, err := decimal.NewFromString(num.String())
+		if err != nil {
+			return fail("must be a %s", p.Type)
+		}
+		if p.Type == "integer" && !d.IsInteger() {
+			return fail("must be a whole number")
+		}
+		if p.Minimum != nil && d.LessThan(decimal.NewFromFloat(*p.Minimum)) {
+			return fail("must be at least %v", *p.Minimum)
+		}
+		if p.ExclusiveMinimum != nil && d.LessThanOrEqual(decimal.NewFromFloat(*p.ExclusiveMinimum)) {
+			return fail("must be greater than %v", *p.ExclusiveMinimum)
+		}
+		if p.Maximum != nil && d.GreaterThan(decimal.NewFromFloat(*p.Maximum)) {
+			return fail("must be at most %v", *p.Maximum)
+		}
+
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fail("must be true or false")
+		}
+
+	case "array":
+		if _, ok := value.([]any); !ok {
+			return fail("must be a list")
+		}
+		var items []json.RawMessage
+		json.Unmarshal(raw, &items)
+		if p.MinItems != nil && len(items) < *p.MinItems {
+			return fail("must have at least %d items", *p.MinItems)
+		}
+		if p.Items == nil {
+			return nil
+		}
+		var errs []FieldError
+		for i, item := range items {
+			errs = append(errs, p.Items.validate(fmt.Sprintf("%s[%d]", field, i), item)...)
+		}
+		return errs
+	}
+
+	return nil
+}
+
+// ptr helps filling the optional schema constraints
+func ptr[T any](v T) *T {
+	return &v
+}
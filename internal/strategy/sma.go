@@ -0,0 +1,171 @@
+package strategy
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"trade/internal/binance"
+
+	"github.com/shopspring/decimal"
+)
+
+const SMACrossoverName = "sma_crossover"
+
+type smaParams struct {
+	Symbol   string          `json:"symbol"`
+	Interval string          `json:"interval"`
+	Fast     int             `json:"fast"`
+	Slow     int             `json:"slow"`
+	Quantity decimal.Decimal `json:"quantity"`
+}
+
+// validate checks what the schema can't
+func (p smaParams) validate() error {
+	if _, ok := binance.IntervalDuration(p.Interval); !ok {
+		return invalidField("interval", "must be one of %s", strings.Join(binance.Intervals(), ", "))
+	}
+	if p.Slow <= p.Fast {
+		return invalidField("slow", "must be greater than fast")
+	}
+	return nil
+}
+
+// smaCrossover goes long when the fast moving average of the closes crosses
+// above the slow one and closes the position when it crosses back below
+type smaCrossover struct {
+	Base
+	params smaParams
+	closes []decimal.Decimal
+	long   bool
+}
+
+func init() {
+	Register(Definition{
+		Name:        SMACrossoverName,
+		Description: "Buys when the fast simple moving average crosses above the slow one, sells when it crosses back below",
+		Schema: Schema{
+			Type: "object",
+			Properties: map[string]Property{
+				"symbol": {
+					Type:        "string",
+					Title:       "Symbol",
+					Description: "Spot symbol to trade, e.g. BTCUSDT",
+					MinLength:   ptr(1),
+				},
+				"interval": {
+					Type:        "string",
+					Title:       "Interval",
+					Description: "Candle interval the averages are computed on",
+					Enum:        binance.Intervals(),
+					Default:     "1h",
+				},
+				"fast": {
+					Type:        "integer",
+					Title:       "Fast period",
+					Description: "Number of candles in the fast average",
+					Minimum:     ptr(1.0),
+					Default:     9,
+				},
+				"slow": {
+					Type:        "integer",
+					Title:       "Slow period",
+					Description: "Number of candles in the slow average, more than the fast period",
+					Minimum:     ptr(2.0),
+					Default:     21,
+				},
+				"quantity": {
+					Type:             "number",
+					Title:            "Quantity",
+					Description:      "Amount of the base asset bought on a cross up and sold on a cross down",
+					ExclusiveMinimum: ptr(0.0),
+				},
+			},
+			Required: []string{"symbol", "interval", "fast", "slow", "quantity"},
+		},
+		New: newSMACrossover,
+	})
+}
+
+func newSMACrossover(raw json.RawMessage) (Strategy, error) {
+	var params smaParams
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+
+	params.Symbol = strings.ToUpper(strings.TrimSpace(params.Symbol))
+	if err := params.validate(); err != nil {
+		return nil, err
+	}
+
+	return &smaCrossover{params: params}, nil
+}
+
+func (s *smaCrossover) Feed() Feed {
+	return Feed{
+		Symbol:   s.params.Symbol,
+		Interval: s.params.Interval,
+		Warmup:   s.params.Slow * 2,
+	}
+}
+
+func (s *smaCrossover) OnCandle(c Candle) []Intent {
+	if c.Symbol != s.params.Symbol {
+		return nil
+	}
+
+	// One close more than the slow average needs, to compare with the previous candle
+	s.closes = append(s.closes, c.Close)
+	if len(s.closes) > s.params.Slow+1 {
+		s.closes = s.closes[len(s.closes)-s.params.Slow-1:]
+	}
+	if len(s.closes) <= s.params.Slow {
+		return nil
+	}
+
+	previous := s.closes[:len(s.closes)-1]
+	current := s.closes[1:]
+
+	prevFast, prevSlow := average(previous, s.params.Fast), average(previous, s.params.Slow)
+	fast, slow := average(current, s.params.Fast), average(current, s.params.Slow)
+
+	switch {
+	case !s.long && prevFast.LessThanOrEqual(prevSlow) && fast.GreaterThan(slow):
+		s.long = true
+		return []Intent{s.intent(binance.SideBuy, fast, slow)}
+	case s.long && prevFast.GreaterThanOrEqual(prevSlow) && fast.LessThan(slow):
+		s.long = false
+		return []Intent{s.intent(binance.SideSell, fast, slow)}
+	}
+
+	return nil
+}
+
+// SetPosition makes the strategy long when the bot holds the symbol, so it
+// waits for the next cross down to sell it
+func (s *smaCrossover) SetPosition(symbol string, side binance.OrderSide, quantity decimal.Decimal) {
+	if symbol == s.params.Symbol {
+		s.long = side == binance.SideBuy && quantity.IsPositive()
+	}
+}
+
+// intent orders the configured quantity. Sells ask for what was bought, the
+// runner trims them to what the bot still holds after fees.
+func (s *smaCrossover) intent(side binance.OrderSide, fast, slow decimal.Decimal) Intent {
+	return Intent{
+		Symbol:   s.params.Symbol,
+		Side:     side,
+		Type:     binance.OrderTypeMarket,
+		Quantity: s.params.Quantity,
+		Reason:   fmt.Sprintf("SMA%d %s crossed SMA%d %s", s.params.Fast, fast.Round(8), s.params.Slow, slow.Round(8)),
+	}
+}
+
+// average is the mean of the last n values
+func average(values []decimal.Decimal, n int) decimal.Decimal {
+	sum := decimal.Zero
+	for _, v := range values[len(values)-n:] {
+		sum = sum.Add(v)
+	}
+	return sum.Div(decimal.NewFromInt(int64(n)))
+}
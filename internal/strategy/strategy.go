@@ -0,0 +1,112 @@
+package strategy
+
+import (
+	"fmt"
+	"time"
+
+	"trade/internal/binance"
+
+	"github.com/shopspring/decimal"
+)
+
+type Candle struct {
+	Symbol    string
+	Interval  string
+	OpenTime  time.Time
+	CloseTime time.Time
+	Open      decimal.Decimal
+	High      decimal.Decimal
+	Low       decimal.Decimal
+	Close     decimal.Decimal
+	Volume    decimal.Decimal
+}
+
+// CandleFromKline converts a kline of symbol to a candle
+func CandleFromKline(symbol, interval string, k binance.Kline) (Candle, error) {
+	candle := Candle{
+		Symbol:    symbol,
+		Interval:  interval,
+		OpenTime:  k.OpenTime,
+		CloseTime: k.CloseTime,
+	}
+
+	for _, f := range []struct {
+		value string
+		into  *decimal.Decimal
+	}{
+		{k.Open, &candle.Open}, {k.High, &candle.High}, {k.Low, &candle.Low},
+		{k.Close, &candle.Close}, {k.Volume, &candle.Volume},
+	} {
+		d, err := decimal.NewFromString(f.value)
+		if err != nil {
+			return Candle{}, fmt.Errorf("error parsing kline of %s at %s: %w", symbol, k.OpenTime.Format(time.RFC3339), err)
+		}
+		*f.into = d
+	}
+
+	return candle, nil
+}
+
+type Tick struct {
+	Symbol string
+	Price  decimal.Decimal
+	Time   time.Time
+}
+
+// Signal is an external trade instruction, such as a TradingView alert
+// posted to the webhook. Either Quantity or Percent is set.
+type Signal struct {
+	Symbol   string
+	Side     binance.OrderSide
+	Type     binance.OrderType
+	Quantity decimal.Decimal
+	Percent  decimal.Decimal
+	Price    decimal.Decimal
+}
+
+// Intent is an order a strategy wants placed. Either Quantity (in the base
+// asset) or Percent (of the free balance) is set. Price is only used by
+// LIMIT orders.
+type Intent struct {
+	Symbol   string
+	Side     binance.OrderSide
+	Type     binance.OrderType
+	Quantity decimal.Decimal
+	Percent  decimal.Decimal
+	Price    decimal.Decimal
+	Reason   string
+}
+
+// Feed is the market data a strategy wants to be fed. A zero Feed means the
+// strategy only reacts to signals.
+type Feed struct {
+	Symbol   string
+	Interval string // candle interval, e.g. "15m", empty for no candles
+	Ticks    bool
+	Warmup   int // closed candles replayed on start, before any intent is acted on
+}
+
+// Strategy turns market data and signals into order intents. A strategy is
+// created per bot and only called from one goroutine at a time.
+type Strategy interface {
+	Feed() Feed
+	OnCandle(c Candle) []Intent
+	OnTick(t Tick) []Intent
+	OnSignal(s Signal) []Intent
+}
+
+// Positioned is a strategy that remembers whether it holds a position.
+// Intents from the warmup are never acted on, so after it the runner sets
+// the position to what the bot's recorded fills hold: side BUY for a long,
+// SELL for a short, zero quantity when flat.
+type Positioned interface {
+	SetPosition(symbol string, side binance.OrderSide, quantity decimal.Decimal)
+}
+
+// Base ignores every input. Strategies embed it and override what they use.
+type Base struct{}
+
+func (Base) Feed() Feed                 { return Feed{} }
+func (Base) OnCandle(c Candle) []Intent { return nil }
+func (Base) OnTick(t Tick) []Intent     { return nil }
+func (Base) OnSignal(s Signal) []Intent { return nil }
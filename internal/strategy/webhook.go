@@ -0,0 +1,67 @@
+package strategy
+
+import (
+	"encoding/json"
+	"slices"
+	"strings"
+)
+
+const WebhookName = "webhook"
+
+type webhookParams struct {
+	Symbols []string `json:"symbols"` // symbols signals may trade, any when empty
+}
+
+// webhookPassthrough places every signal it receives as it is
+type webhookPassthrough struct {
+	Base
+	params webhookParams
+}
+
+func init() {
+	Register(Definition{
+		Name:        WebhookName,
+		Description: "Places the orders sent to the webhook, e.g. by TradingView alerts",
+		Schema: Schema{
+			Type: "object",
+			Properties: map[string]Property{
+				"symbols": {
+					Type:        "array",
+					Title:       "Symbols",
+					Description: "Symbols signals may trade, any symbol when left empty",
+					Items:       &Property{Type: "string", MinLength: ptr(1)},
+				},
+			},
+		},
+		New: newWebhookPassthrough,
+	})
+}
+
+func newWebhookPassthrough(raw json.RawMessage) (Strategy, error) {
+	var params webhookParams
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
+	}
+
+	for i, symbol := range params.Symbols {
+		params.Symbols[i] = strings.ToUpper(strings.TrimSpace(symbol))
+	}
+
+	return &webhookPassthrough{params: params}, nil
+}
+
+func (s *webhookPassthrough) OnSignal(signal Signal) []Intent {
+	if len(s.params.Symbols) > 0 && !slices.Contains(s.params.Symbols, signal.Symbol) {
+		return nil
+	}
+
+	return []Intent{{
+		Symbol:   signal.Symbol,
+		Side:     signal.Side,
+		Type:     signal.Type,
+		Quantity: signal.Quantity,
+		Percent:  signal.Percent,
+		Price:    signal.Price,
+		Reason:   "webhook signal",
+	}}
+}
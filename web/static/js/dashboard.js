@@ -5,9 +5,11 @@ class Dashboard {
     this.accountBalances = new Map();
     this.totalBalance = 0;
     this.returnsUpdateTimeout = null;
+    this.strategies = new Map();
   }
 
   init() {
+    this.loadStrategies();
     this.loadDashboardData();
     this.setupEventListeners();
     this.startAutoRefresh();
@@ -52,20 +54,23 @@ class Dashboard {
 
   async updateReturn(period, elementId) {
     try {
-      const response = await this.apiCall(`/api/dashboard/${period}-return`);
-      const returnValue = await response.json();
+      const response = await this.apiCall(`/api/dashboard/returns/${period}`);
+      const periodReturn = await response.json();
       const element = document.getElementById(elementId);
 
-      if (returnValue !== null && element) {
-        const diff = this.totalBalance - returnValue;
+      if (periodReturn !== null && element) {
+        // Deposits and withdrawals are already left out of change and twr
+        const change = parseFloat(periodReturn.change);
+        const twr = parseFloat(periodReturn.twr);
 
-        element.textContent = `$${diff.toLocaleString('en-US', {
+        element.textContent = `$${change.toLocaleString('en-US', {
           minimumFractionDigits: 2,
           maximumFractionDigits: 2
-        })}`;
+        })} (${twr >= 0 ? '+' : ''}${twr.toFixed(2)}%)`;
+        element.title = `${periodReturn.timezone}: $${parseFloat(periodReturn.start_balance).toFixed(2)} → $${parseFloat(periodReturn.end_balance).toFixed(2)}`;
 
         // Apply color based on positive/negative
-        if (diff >= 0) {
+        if (change >= 0) {
           element.style.color = '#38a169'; // green
         } else {
           element.style.color = '#e53e3e'; // red
@@ -78,9 +83,9 @@ class Dashboard {
 
   // Then call them like this:
   async updateAllReturns() {
-    await this.updateReturn('monthly', 'monthlyReturn');
-    await this.updateReturn('yearly', 'yearlyReturn');
-    await this.updateReturn('daily', 'dailyReturn');
+    await this.updateReturn('month', 'monthlyReturn');
+    await this.updateReturn('year', 'yearlyReturn');
+    await this.updateReturn('day', 'dailyReturn');
   }
 
   async loadBotStats() {
@@ -131,9 +136,35 @@ class Dashboard {
       modal.style.display = 'flex';
       // Clear form
       document.getElementById('createAccountForm').reset();
+      this.fillEnvironments('environment', 'binance');
     }
   }
 
+  // fillEnvironments lists the environments of the exchange in a select
+  fillEnvironments(selectId, exchange, selected = 'mainnet') {
+    const environments = {
+      binance: [
+        ['mainnet', 'Mainnet'],
+        ['spot-testnet', 'Spot testnet (test funds)'],
+        ['futures-testnet', 'Futures testnet (test funds)']
+      ],
+      bybit: [
+        ['mainnet', 'Mainnet'],
+        ['testnet', 'Testnet (test funds)']
+      ]
+    };
+
+    const select = document.getElementById(selectId);
+    select.innerHTML = '';
+    (environments[exchange] || environments.binance).forEach(([value, label]) => {
+      const option = document.createElement('option');
+      option.value = value;
+      option.textContent = label;
+      select.appendChild(option);
+    });
+    select.value = selected;
+  }
+
   hideCreateAccountModal() {
     const modal = document.getElementById('createAccountModal');
     if (modal) {
@@ -151,7 +182,8 @@ class Dashboard {
       name: formData.get('accountName').trim(),
       api_key: formData.get('apiKey').trim(),
       api_secret: formData.get('apiSecret').trim(),
-      base_url: formData.get('baseUrl').trim() || 'https://api.binance.com'
+      exchange: formData.get('exchange') || 'binance',
+      environment: formData.get('environment') || 'mainnet'
     };
 
     // Validate required fields
@@ -270,11 +302,10 @@ class Dashboard {
     }
   }
 
-  async loadAccountBalance(accountId, key, secret) {
+  async loadAccountBalance(accountId) {
     try {
       const accountData = {
-        key: key,
-        secret: secret
+        account_id: accountId
       };
 
       const response = await this.apiCall('/api/get-margin-account-info', {
@@ -358,7 +389,7 @@ class Dashboard {
 
       // Pre-fill the form with current values
       document.getElementById('editAccountName').value = account.name;
-      document.getElementById('editBaseUrl').value = account.base_url;
+      this.fillEnvironments('editEnvironment', account.exchange || 'binance', account.environment || 'mainnet');
 
       // Show the modal
       modal.style.display = 'flex';
@@ -386,7 +417,7 @@ class Dashboard {
     const formData = new FormData(form);
     const updatedData = {
       name: formData.get('editAccountName').trim(),
-      base_url: formData.get('editBaseUrl').trim()
+      environment: formData.get('editEnvironment')
     };
 
     // Validate required fields
@@ -431,9 +462,20 @@ class Dashboard {
     const existingBalanceElement = document.getElementById(`balance-${account.id}`);
     const existingBalance = existingBalanceElement ? existingBalanceElement.textContent : 'Loading...'
 
+    // What the key could do when it was last validated
+    const capabilities = [
+      account.spot_enabled && 'spot',
+      account.margin_enabled && 'margin',
+      account.futures_enabled && 'futures'
+    ].filter(Boolean);
+    const exchangeName = account.exchange === 'bybit' ? 'Bybit' : 'Binance';
+    const keyInfo = account.validated_at
+      ? `${capabilities.join(', ') || 'read only'}${account.ip_restricted ? ', IP restricted' : ''}, validated ${new Date(account.validated_at).toLocaleString()}`
+      : 'not validated';
+
     // Create the row HTML
     row.innerHTML = `
-        <td>${account.name}</td>
+        <td title="${exchangeName} API key ${account.api_key_masked} (${keyInfo})">${account.name} <span class="mode-badge">${exchangeName}</span>${account.environment && account.environment !== 'mainnet' ? ` <span class="mode-badge testnet">${account.environment}</span>` : ''}</td>
         <td id="balance-${account.id}">${existingBalance}</td>
         <td><span class="status-badge ${account.account_active ? 'running' : 'stopped'}">
             ${account.account_active ? 'ACTIVE' : 'INACTIVE'}
@@ -465,7 +507,7 @@ class Dashboard {
     });
 
     // Load account balance asynchronously (don't await it)
-    this.loadAccountBalance(account.id, account.api_key, account.api_secret);
+    this.loadAccountBalance(account.id);
 
     return row; // Return the DOM node immediately
   }
@@ -473,8 +515,8 @@ class Dashboard {
   createBotStatsRow(bot) {
     const row = document.createElement('tr');
     row.innerHTML = `
-            <td>${bot.name}</td>
-            <td><span class="status-badge ${bot.status.toLowerCase()}">${bot.status}</span></td>
+            <td>${bot.name}${bot.mode && bot.mode !== 'live' ? ` <span class="mode-badge ${bot.mode}">${bot.mode}</span>` : ''}</td>
+            <td><span class="status-badge ${bot.status.toLowerCase()}" title="${bot.status_reason || ''}">${bot.status}</span></td>
             <td>${bot.account_name || "No Account"}</td>
             <td>${bot.win_rate}%</td>
             <td>${bot.profit_factor}</td>
@@ -499,7 +541,10 @@ class Dashboard {
     const formData = new FormData(form);
     const botData = {
       name: formData.get('botName').trim(),
-      strategy: formData.get('botStrategy').trim(),
+      strategy: (formData.get('botStrategy') || '').trim(),
+      strategy_params: this.collectStrategyParams('botStrategyParams'),
+      mode: formData.get('botMode') || 'live',
+      market: formData.get('botMarket') || 'SPOT',
       initial_holding: parseFloat(formData.get('initialHolding')) || 0
     };
 
@@ -532,8 +577,7 @@ class Dashboard {
         this.loadBotStats(); // Refresh the bot table
         // alert('Bot created successfully!');
       } else {
-        const error = await response.text();
-        alert('Error creating bot: ' + error);
+        await this.showBotError(response, 'botStrategyParams', 'Error creating bot: ');
       }
     } catch (error) {
       alert('Network error: ' + error.message);
@@ -546,16 +590,20 @@ class Dashboard {
 
   createPositionRow(position) {
     const row = document.createElement('tr');
+    const pnl = position.pnl === null ? null : parseFloat(position.pnl);
+    const leverage = position.leverage
+      ? ` <span class="mode-badge" title="${position.liquidation_price ? 'Liquidation at $' + parseFloat(position.liquidation_price).toLocaleString() : ''}">${position.leverage}x</span>`
+      : '';
     row.innerHTML = `
-            <td>${position.trade_id}</td>
-            <td>${position.bot}</td>
+            <td>${position.symbol}${leverage}</td>
+            <td>${position.bot || '-'}</td>
             <td><span class="position-badge ${position.position.toLowerCase()}">${position.position}</span></td>
-            <td>$${parseFloat(position.entry).toLocaleString()}</td>
+            <td>${position.entry === null ? '-' : '$' + parseFloat(position.entry).toLocaleString()}</td>
             <td>$${parseFloat(position.current).toLocaleString()}</td>
-            <td class="${position.pnl >= 0 ? 'positive' : 'negative'}">
-                ${position.pnl >= 0 ? '$' : '-$'}${Math.abs(position.pnl).toLocaleString()}
+            <td class="${pnl === null ? '' : pnl >= 0 ? 'positive' : 'negative'}">
+                ${pnl === null ? '-' : (pnl >= 0 ? '$' : '-$') + Math.abs(pnl).toLocaleString()}
             </td>
-            <td>${position.time}</td>
+            <td>${position.time || '-'}</td>
         `;
     return row;
   }
@@ -566,6 +614,7 @@ class Dashboard {
       modal.style.display = 'flex';
       // Clear form
       document.getElementById('createBotForm').reset();
+      this.renderStrategyParams('botStrategyParams', document.getElementById('botStrategy').value, {});
 
       // Load available accounts for the dropdown
       this.loadAvailableAccounts();
@@ -583,11 +632,12 @@ class Dashboard {
         dropdown.innerHTML = '<option value="">No Account</option>';
 
         if (accounts && Array.isArray(accounts)) {
-          accounts.forEach(account => {
+          // Bots only trade on Binance accounts
+          accounts.filter(account => (account.exchange || 'binance') === 'binance').forEach(account => {
             console.log('Adding account:', account); // Debug each account
             const option = document.createElement('option');
             option.value = account.id;
-            option.textContent = `${account.name} (${account.base_url})`;
+            option.textContent = `${account.name} (${account.environment})`;
             dropdown.appendChild(option);
           });
         }
@@ -597,6 +647,178 @@ class Dashboard {
     }
   }
 
+  async loadStrategies() {
+    try {
+      const response = await this.apiCall('/api/strategies');
+      if (!response.ok) return;
+
+      const strategies = await response.json();
+      this.strategies = new Map(strategies.map(strategy => [strategy.name, strategy]));
+
+      [['botStrategy', 'botStrategyParams'], ['editBotStrategy', 'editBotStrategyParams']].forEach(([selectId, paramsId]) => {
+        const dropdown = document.getElementById(selectId);
+        if (!dropdown) return;
+
+        dropdown.innerHTML = '';
+        strategies.forEach(strategy => {
+          const option = document.createElement('option');
+          option.value = strategy.name;
+          option.textContent = strategy.name;
+          option.title = strategy.description;
+          dropdown.appendChild(option);
+        });
+
+        dropdown.addEventListener('change', () => {
+          this.renderStrategyParams(paramsId, dropdown.value, {});
+        });
+        this.renderStrategyParams(paramsId, dropdown.value, {});
+      });
+    } catch (error) {
+      console.error('Error loading strategies:', error);
+    }
+  }
+
+  // renderStrategyParams builds the form fields of a strategy from its schema
+  renderStrategyParams(containerId, strategyName, values) {
+    const container = document.getElementById(containerId);
+    const strategy = this.strategies.get(strategyName);
+    if (!container) return;
+
+    container.innerHTML = '';
+    if (!strategy) return;
+
+    const schema = strategy.schema || {};
+    const required = schema.required || [];
+    const properties = schema.properties || {};
+    const names = [...required, ...Object.keys(properties).filter(name => !required.includes(name))];
+
+    names.forEach(name => {
+      const property = properties[name];
+      if (!property) return;
+
+      const id = `${containerId}-${name}`;
+      const group = document.createElement('div');
+      group.className = 'form-group';
+
+      const label = document.createElement('label');
+      label.htmlFor = id;
+      label.textContent = (property.title || name) + (required.includes(name) ? ' *' : '');
+      group.appendChild(label);
+
+      let input;
+      if (property.enum) {
+        input = document.createElement('select');
+        property.enum.forEach(value => {
+          const option = document.createElement('option');
+          option.value = value;
+          option.textContent = value;
+          input.appendChild(option);
+        });
+      } else if (property.type === 'boolean') {
+        input = document.createElement('input');
+        input.type = 'checkbox';
+      } else {
+        input = document.createElement('input');
+        if (property.type === 'integer' || property.type === 'number') {
+          input.type = 'number';
+          input.step = property.type === 'integer' ? '1' : 'any';
+          if (property.minimum !== undefined) input.min = property.minimum;
+        } else {
+          input.type = 'text';
+        }
+        if (property.type === 'array') {
+          input.placeholder = 'Comma separated';
+        }
+      }
+
+      input.id = id;
+      input.dataset.param = name;
+      input.dataset.type = property.type;
+      input.title = property.description || '';
+
+      const value = values[name] !== undefined ? values[name] : property.default;
+      if (property.type === 'boolean') {
+        input.checked = Boolean(value);
+      } else if (Array.isArray(value)) {
+        input.value = value.join(', ');
+      } else if (value !== undefined && value !== null) {
+        input.value = value;
+      }
+
+      group.appendChild(input);
+
+      const error = document.createElement('small');
+      error.className = 'field-error';
+      error.dataset.errorFor = name;
+      group.appendChild(error);
+
+      container.appendChild(group);
+    });
+  }
+
+  // collectStrategyParams reads the rendered fields back into a params object.
+  // Empty fields are left out so the server reports missing required ones.
+  collectStrategyParams(containerId) {
+    const params = {};
+    const container = document.getElementById(containerId);
+    if (!container) return params;
+
+    container.querySelectorAll('[data-param]').forEach(input => {
+      const name = input.dataset.param;
+      switch (input.dataset.type) {
+        case 'boolean':
+          params[name] = input.checked;
+          break;
+        case 'integer':
+        case 'number':
+          if (input.value !== '') params[name] = Number(input.value);
+          break;
+        case 'array':
+          params[name] = input.value.split(',').map(item => item.trim()).filter(item => item);
+          break;
+        default:
+          if (input.value.trim() !== '') params[name] = input.value.trim();
+      }
+    });
+
+    return params;
+  }
+
+  // showBotError shows the field errors of a rejected bot next to the fields,
+  // and anything else in an alert
+  async showBotError(response, containerId, prefix) {
+    const container = document.getElementById(containerId);
+    container?.querySelectorAll('.field-error').forEach(el => { el.textContent = ''; });
+
+    const text = await response.text();
+    let body = null;
+    try {
+      body = JSON.parse(text);
+    } catch (error) {
+      // Plain text error
+    }
+
+    if (!body || !Array.isArray(body.fields)) {
+      alert(prefix + text);
+      return;
+    }
+
+    const unplaced = [];
+    body.fields.forEach(field => {
+      const name = field.field.split('[')[0];
+      const el = container?.querySelector(`[data-error-for="${name}"]`);
+      if (el) {
+        el.textContent = `${field.field} ${field.message}`;
+      } else {
+        unplaced.push(field.field ? `${field.field} ${field.message}` : field.message);
+      }
+    });
+
+    if (unplaced.length > 0) {
+      alert(prefix + unplaced.join('\n'));
+    }
+  }
+
   hideCreateBotModal() {
     const modal = document.getElementById('createBotModal');
     if (modal) {
@@ -718,6 +940,10 @@ class Dashboard {
       this.handleCreateAccount();
     });
 
+    document.getElementById('exchange')?.addEventListener('change', (e) => {
+      this.fillEnvironments('environment', e.target.value);
+    });
+
     // Add these to setupEventListeners()
     document.getElementById('cancelAccountBtn')?.addEventListener('click', () => {
       this.hideCreateAccountModal();
@@ -766,7 +992,10 @@ class Dashboard {
     const formData = new FormData(form);
     const updatedData = {
       name: formData.get('editBotName').trim(),
-      strategy: formData.get('editBotStrategy').trim(),
+      strategy: (formData.get('editBotStrategy') || '').trim(),
+      strategy_params: this.collectStrategyParams('editBotStrategyParams'),
+      mode: formData.get('editBotMode') || 'live',
+      market: formData.get('editBotMarket') || 'SPOT',
       initial_holding: parseFloat(formData.get('editInitialHolding')) || 0
     };
 
@@ -823,9 +1052,7 @@ class Dashboard {
           saveBtn.textContent = 'Save Changes';
         }, 1000);
       } else {
-        const error = await response.text();
-        console.error('Server error:', error); // Debug log
-        alert('Error updating bot: ' + error);
+        await this.showBotError(response, 'editBotStrategyParams', 'Error updating bot: ');
       }
     } catch (error) {
       console.error('Network error:', error); // Debug log
@@ -1163,6 +1390,9 @@ class Dashboard {
     // Populate the form with current bot data
     document.getElementById('editBotName').value = bot.name || '';
     document.getElementById('editBotStrategy').value = bot.strategy || '';
+    this.renderStrategyParams('editBotStrategyParams', bot.strategy, bot.strategy_params || {});
+    document.getElementById('editBotMode').value = bot.mode || 'live';
+    document.getElementById('editBotMarket').value = bot.market || 'SPOT';
     document.getElementById('editInitialHolding').value = bot.initial_holding || 0;
 
     // Update status display
@@ -1207,11 +1437,12 @@ class Dashboard {
         if (accounts && Array.isArray(accounts)) {
           console.log('Adding', accounts.length, 'accounts to edit dropdown'); // Debug log
 
-          accounts.forEach((account, index) => {
+          // Bots only trade on Binance accounts
+          accounts.filter(account => (account.exchange || 'binance') === 'binance').forEach((account, index) => {
             console.log('Adding account', index, ':', account); // Debug log
             const option = document.createElement('option');
             option.value = account.id;
-            option.textContent = `${account.name} (${account.base_url})`;
+            option.textContent = `${account.name} (${account.environment})`;
             dropdown.appendChild(option);
           })
 